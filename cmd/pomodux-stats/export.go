@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+	"github.com/pomodux/pomodux/internal/export"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/privacy"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/spf13/cobra"
+)
+
+// exportFormats are the formats of the export command
+var exportFormats = []string{
+	export.FormatJSON, export.FormatCSV, export.FormatTSV, export.FormatICS,
+	export.FormatTimewarrior, export.FormatOrg, export.FormatToggl,
+}
+
+func newExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export sessions as JSON, CSV, TSV, iCalendar, Timewarrior, Org-mode or Toggl CSV",
+		Long: "Export the sessions matching the filters, oldest first. The ics format emits one " +
+			"calendar event per session with a stable UID, so re-importing an export updates " +
+			"existing events instead of duplicating them.",
+		Args: cobra.NoArgs,
+		RunE: exportHistory,
+	}
+
+	cmd.Flags().String("format", export.FormatJSON, "Output format: "+strings.Join(exportFormats, ", "))
+	cmd.Flags().StringP("output", "o", "", "Write the export to a file instead of stdout")
+	cmd.Flags().String("from", "", "Sessions started at or after this date or time (YYYY-MM-DD, YYYY-MM-DD HH:MM or RFC 3339)")
+	cmd.Flags().String("to", "", "Sessions started before this time, or on or before this date")
+	cmd.Flags().String("label", "", "Label contains this text (case-insensitive)")
+	cmd.Flags().String("label-regex", "", "Label matches this regular expression")
+	cmd.Flags().StringArray("preset", nil, "Preset is one of these (repeatable)")
+	cmd.Flags().StringArray("status", nil, "End status is one of these (repeatable)")
+	cmd.Flags().StringArray("project", nil, "Project is one of these (repeatable)")
+	cmd.Flags().StringArray("tag", nil, "Session has all of these tags (repeatable)")
+	cmd.Flags().Duration("min-duration", 0, "Minimum focus time, e.g. 10m")
+	cmd.Flags().String("redact", "", "Show labels and notes as plain, hash or redact (default privacy.export_labels)")
+	return cmd
+}
+
+func exportHistory(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	if !containsString(exportFormats, format) {
+		return fmt.Errorf("invalid --format %q (expected %s)", format, strings.Join(exportFormats, ", "))
+	}
+
+	q, err := exportQuery(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	mode, err := redactMode(cmd, cfg.Privacy.ExportLabels)
+	if err != nil {
+		return err
+	}
+	store = history.NewRedactedStore(store, mode)
+
+	if output == "" {
+		return writeExport(cmd.OutOrStdout(), store, format, q)
+	}
+	var buf bytes.Buffer
+	if err := writeExport(&buf, store, format, q); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported sessions to %s\n", output)
+	return nil
+}
+
+// redactMode returns the --redact mode, or configured when the flag is not set
+func redactMode(cmd *cobra.Command, configured string) (string, error) {
+	if !cmd.Flags().Changed("redact") {
+		return configured, nil
+	}
+	value, _ := cmd.Flags().GetString("redact")
+	mode, err := privacy.ParseMode(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid --redact: %w", err)
+	}
+	return mode, nil
+}
+
+// writeExport streams the sessions matching q, followed by their summary
+func writeExport(out io.Writer, store history.Store, format string, q history.Query) error {
+	w, err := export.NewSessionWriter(out, format)
+	if err != nil {
+		return err
+	}
+	var summary stats.Summary
+	err = history.Walk(store, q, func(s history.Session) error {
+		summary.Add(s)
+		return w.Write(s)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export history: %w", err)
+	}
+	return w.Finish(summary)
+}
+
+// exportQuery builds the history query selected by the filter flags
+func exportQuery(cmd *cobra.Command) (history.Query, error) {
+	var q history.Query
+	flags := cmd.Flags()
+
+	now := time.Now()
+	if from, _ := flags.GetString("from"); from != "" {
+		t, _, err := parseBound(from, now)
+		if err != nil {
+			return q, fmt.Errorf("invalid --from: %w", err)
+		}
+		q.From = t
+	}
+	if to, _ := flags.GetString("to"); to != "" {
+		t, dateOnly, err := parseBound(to, now)
+		if err != nil {
+			return q, fmt.Errorf("invalid --to: %w", err)
+		}
+		if dateOnly {
+			// A date includes the whole day
+			t = t.AddDate(0, 0, 1)
+		}
+		q.To = t
+	}
+
+	q.Label, _ = flags.GetString("label")
+	if pattern, _ := flags.GetString("label-regex"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return q, fmt.Errorf("invalid --label-regex: %w", err)
+		}
+		q.LabelRegex = re
+	}
+	q.Presets, _ = flags.GetStringArray("preset")
+	q.EndStatuses, _ = flags.GetStringArray("status")
+	for _, status := range q.EndStatuses {
+		if !containsString(history.EndStatuses, status) {
+			return q, fmt.Errorf("invalid --status %q (expected %s)", status, strings.Join(history.EndStatuses, ", "))
+		}
+	}
+	q.Projects, _ = flags.GetStringArray("project")
+	q.Tags, _ = flags.GetStringArray("tag")
+	q.MinDuration, _ = flags.GetDuration("min-duration")
+	return q, nil
+}
+
+// parseBound parses a local date or a time, reporting whether it was a
+// bare date
+func parseBound(value string, now time.Time) (time.Time, bool, error) {
+	if day, err := history.ParseDay(value, time.Local); err == nil {
+		return day, true, nil
+	}
+	t, err := history.ParseTime(value, now)
+	return t, false, err
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -2,23 +2,71 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/encryption"
+	"github.com/pomodux/pomodux/internal/export"
+	"github.com/pomodux/pomodux/internal/history"
 	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/privacy"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/statsui"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/pomodux/pomodux/internal/timer"
 	"github.com/spf13/cobra"
 )
 
+// groupByFields maps --group-by values to history grouping fields
+var groupByFields = map[string]string{
+	"label":   history.GroupByLabel,
+	"preset":  history.GroupByPreset,
+	"project": history.GroupByProject,
+	"tag":     history.GroupByTag,
+	"status":  history.GroupByEndStatus,
+}
+
 var (
 	version   = "0.1.0"
 	buildTime = "unknown"
 	gitCommit = "unknown"
 )
 
+// statsOptions holds the parsed command-line flags
+type statsOptions struct {
+	limit        int
+	today        bool
+	all          bool
+	groupBy      string
+	by           string
+	heatmap      bool
+	heatmapWeeks int
+	distribution bool
+	compare      string
+	compareRange string
+	against      string
+	interactive  bool
+	format       string
+}
+
 func main() {
-	var limit int
-	var today bool
-	var all bool
+	if err := newRootCmd().Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newRootCmd builds the pomodux-stats command with its subcommands
+func newRootCmd() *cobra.Command {
+	var opts statsOptions
 
 	rootCmd := &cobra.Command{
 		Use:     "pomodux-stats",
@@ -26,42 +74,438 @@ func main() {
 		Long:    "View statistics and history for pomodoro timer sessions",
 		Version: fmt.Sprintf("%s (built %s, commit %s)", version, buildTime, gitCommit),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return showStats(limit, today, all)
+			// Choosing the number of weeks asks for the heatmap too
+			if cmd.Flags().Changed("heatmap-weeks") {
+				opts.heatmap = true
+			}
+			return showStats(cmd.OutOrStdout(), opts)
 		},
 	}
 
-	rootCmd.Flags().IntVarP(&limit, "limit", "l", 20, "Show last N sessions")
-	rootCmd.Flags().BoolVarP(&today, "today", "t", false, "Show today's statistics")
-	rootCmd.Flags().BoolVar(&all, "all", false, "Show all sessions")
+	rootCmd.Flags().IntVarP(&opts.limit, "limit", "l", 20, "Show last N sessions")
+	rootCmd.Flags().BoolVarP(&opts.today, "today", "t", false, "Show today's statistics")
+	rootCmd.Flags().BoolVar(&opts.all, "all", false, "Show all sessions")
+	rootCmd.Flags().StringVar(&opts.groupBy, "group-by", "", "Show focus totals grouped by label, preset, project, tag or status")
+	rootCmd.Flags().StringVar(&opts.by, "by", "", "Show focus totals per day, week or month (combine with --group-by)")
+	rootCmd.Flags().BoolVar(&opts.heatmap, "heatmap", false, "Show a calendar heatmap of focus time")
+	rootCmd.Flags().IntVar(&opts.heatmapWeeks, "heatmap-weeks", 26, "Number of weeks the heatmap covers (implies --heatmap)")
+	rootCmd.Flags().BoolVar(&opts.distribution, "distribution", false, "Show focus by hour of day and weekday, with completion rate and pauses per hour")
+	rootCmd.Flags().StringVar(&opts.compare, "compare", "", "Compare this day, week or month with the previous one, or a custom --range")
+	rootCmd.Flags().StringVar(&opts.compareRange, "range", "", "Dates compared by --compare custom, as YYYY-MM-DD..YYYY-MM-DD")
+	rootCmd.Flags().StringVar(&opts.against, "against", "", "Dates --range is compared with (default: the same number of days before it)")
+	rootCmd.Flags().BoolVarP(&opts.interactive, "interactive", "i", false, "Browse sessions, days, labels and charts interactively")
+	rootCmd.Flags().StringVar(&opts.format, "format", export.FormatTable, "Output format: table, json, csv or tsv")
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
-	}
+	rootCmd.AddCommand(newReportCmd(), newExportCmd())
+	return rootCmd
 }
 
-func showStats(limit int, today bool, all bool) error {
+// openStore loads the configuration, initializes the logger and opens the
+// history store
+func openStore() (*config.Config, history.Store, error) {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Initialize logger
 	if err := logger.Init(logger.Config{
-		Level: cfg.Logging.Level,
-		File:  cfg.Logging.File,
+		Level:  cfg.Logging.Level,
+		File:   cfg.Logging.File,
+		Labels: cfg.Privacy.LogLabels,
 	}); err != nil {
-		return fmt.Errorf("failed to initialize logger: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
 	logger.WithField("component", "pomodux-stats").Info("Starting pomodux-stats")
 
-	// TODO: Load and display history
-	fmt.Println("Statistics view will be implemented in the next phase")
-	fmt.Printf("Options: limit=%d, today=%v, all=%v\n", limit, today, all)
+	if _, err := encryption.UnlockDir(config.StatePath(), nil); err != nil {
+		return nil, nil, err
+	}
+
+	store, err := history.Open(cfg.History.Backend, config.StatePath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	// Old ranges are read from the archives "pomodux history compact" wrote
+	archived := history.NewArchivedStore(store, filepath.Join(config.StatePath(), history.ArchiveDirName))
+	// Private sessions never show their label outside the history
+	return cfg, history.NewRedactedStore(archived, privacy.ModePlain), nil
+}
+
+func showStats(out io.Writer, opts statsOptions) error {
+	if !export.ValidFormat(opts.format) {
+		return fmt.Errorf("invalid --format %q (expected %s)", opts.format, strings.Join(export.Formats, ", "))
+	}
+
+	cfg, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if opts.format != export.FormatTable {
+		store = history.NewRedactedStore(store, cfg.Privacy.ExportLabels)
+	}
+
+	if opts.heatmap {
+		if opts.format != export.FormatTable {
+			return fmt.Errorf("--heatmap only supports the table format")
+		}
+		if opts.heatmapWeeks <= 0 {
+			return fmt.Errorf("--heatmap-weeks must be positive, got %d", opts.heatmapWeeks)
+		}
+		return showHeatmap(out, store, theme.GetTheme(cfg.Theme), opts.heatmapWeeks, cfg.Stats.WeekStartDay())
+	}
+
+	if opts.compare != "" {
+		if opts.format != export.FormatTable {
+			return fmt.Errorf("--compare only supports the table format")
+		}
+		return showComparison(out, store, theme.GetTheme(cfg.Theme), cfg.Stats.WeekStartDay(), opts)
+	}
+
+	// Restrict to the current local day when --today is set
+	var from, to time.Time
+	if opts.today {
+		from, to = history.DayRange(time.Now())
+	}
+
+	if opts.distribution {
+		if opts.format != export.FormatTable {
+			return fmt.Errorf("--distribution only supports the table format")
+		}
+		return showDistribution(out, store, theme.GetTheme(cfg.Theme), cfg, from, to)
+	}
 
+	if opts.interactive {
+		if opts.format != export.FormatTable {
+			return fmt.Errorf("--interactive only supports the table format")
+		}
+		return browse(store, theme.GetTheme(cfg.Theme), cfg.Stats.WeekStartDay(), from, to)
+	}
+
+	var field string
+	if opts.groupBy != "" {
+		var ok bool
+		field, ok = groupByFields[opts.groupBy]
+		if !ok {
+			return fmt.Errorf("invalid --group-by %q (expected label, preset, project, tag or status)", opts.groupBy)
+		}
+	}
+
+	if opts.by != "" {
+		buckets, err := bucketize(store, opts.by, cfg.Stats.WeekStartDay(), field, from, to)
+		if err != nil {
+			return err
+		}
+		if opts.format == export.FormatTable {
+			return printBuckets(out, opts.by, opts.groupBy, buckets)
+		}
+		return export.WriteBuckets(out, opts.format, opts.by, opts.groupBy, buckets)
+	}
+
+	if opts.groupBy != "" {
+		totals, err := history.Totals(store, from, to, field)
+		if err != nil {
+			return fmt.Errorf("failed to compute totals: %w", err)
+		}
+		if opts.format == export.FormatTable {
+			return printTotals(out, opts.groupBy, totals)
+		}
+		return export.WriteTotals(out, opts.format, opts.groupBy, totals)
+	}
+
+	// --all lifts the --limit on the session list
+	limit := opts.limit
+	if opts.all {
+		limit = 0
+	}
+	recent := history.Query{From: from, To: to, Order: history.NewestFirst, Limit: limit}
+
+	if opts.format != export.FormatTable {
+		return exportSessions(out, store, opts.format, recent)
+	}
+
+	page, err := store.Query(recent)
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	summary, err := summarize(store, from, to)
+	if err != nil {
+		return err
+	}
+
+	var goal *stats.GoalReport
+	if goals := stats.GoalsFromConfig(cfg.Goals); goals.Enabled() {
+		report, err := stats.TrackGoals(store, goals, time.Now(), "")
+		if err != nil {
+			return err
+		}
+		goal = &report
+	}
+
+	th := theme.GetTheme(cfg.Theme)
+	return printReport(out, th, opts.today, page.Sessions, summary, goal)
+}
+
+// exportSessions streams the sessions selected by q followed by the summary
+// of the whole range
+func exportSessions(out io.Writer, store history.Store, format string, q history.Query) error {
+	w, err := export.NewSessionWriter(out, format)
+	if err != nil {
+		return err
+	}
+	if err := history.Walk(store, q, w.Write); err != nil {
+		return fmt.Errorf("failed to export history: %w", err)
+	}
+
+	summary, err := summarize(store, q.From, q.To)
+	if err != nil {
+		return err
+	}
+	return w.Finish(summary)
+}
+
+// bucketize streams sessions started in [from, to) into local calendar
+// periods. Periods past to, reached by sessions running over the end of the
+// range, are dropped.
+func bucketize(store history.Store, period string, weekStart time.Weekday, field string, from, to time.Time) ([]stats.Bucket, error) {
+	bucketer, err := stats.NewBucketer(period, weekStart, field, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --by: %w", err)
+	}
+	err = history.Walk(store, history.Query{From: from, To: to}, func(s history.Session) error {
+		bucketer.Add(s)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate history: %w", err)
+	}
+
+	buckets := bucketer.Buckets()
+	if !to.IsZero() {
+		for len(buckets) > 0 && !buckets[len(buckets)-1].Start.Before(to) {
+			buckets = buckets[:len(buckets)-1]
+		}
+	}
+	return buckets, nil
+}
+
+// showHeatmap renders focus time from completed and manually logged
+// sessions over the last weeks weeks
+func showHeatmap(out io.Writer, store history.Store, th *theme.Theme, weeks int, weekStart time.Weekday) error {
+	now := time.Now()
+	from := stats.PeriodStart(now, stats.PeriodWeek, weekStart).AddDate(0, 0, -7*(weeks-1))
+
+	bucketer, err := stats.NewBucketer(stats.PeriodDay, weekStart, "", time.Local)
+	if err != nil {
+		return err
+	}
+	q := history.Query{
+		From:        from,
+		EndStatuses: []string{history.StatusCompleted, history.StatusManual},
+	}
+	err = history.Walk(store, q, func(s history.Session) error {
+		bucketer.Add(s)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate history: %w", err)
+	}
+
+	ascii := lipgloss.ColorProfile() == termenv.Ascii
+	_, err = fmt.Fprintln(out, statsui.Heatmap(th, bucketer.Buckets(), now, weeks, weekStart, ascii))
+	return err
+}
+
+// showDistribution renders when focus sessions started in [from, to)
+// happen, skipping the break presets excluded from goals
+func showDistribution(out io.Writer, store history.Store, th *theme.Theme, cfg *config.Config, from, to time.Time) error {
+	d := stats.NewDistribution(cfg.Goals.BreakPresets, time.Local)
+	err := history.Walk(store, history.Query{From: from, To: to}, func(s history.Session) error {
+		d.Add(s)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to aggregate history: %w", err)
+	}
+
+	_, err = fmt.Fprintln(out, statsui.Distribution(th, d, cfg.Stats.WeekStartDay()))
+	return err
+}
+
+// showComparison renders the comparison selected by --compare
+func showComparison(out io.Writer, store history.Store, th *theme.Theme, weekStart time.Weekday, opts statsOptions) error {
+	var current, previous stats.Range
+	if opts.compare == "custom" {
+		if opts.compareRange == "" {
+			return fmt.Errorf("--compare custom requires --range")
+		}
+		var err error
+		if current, err = parseDateRange(opts.compareRange); err != nil {
+			return fmt.Errorf("invalid --range: %w", err)
+		}
+		previous = stats.Range{From: current.From.AddDate(0, 0, -days(current)), To: current.From}
+		if opts.against != "" {
+			if previous, err = parseDateRange(opts.against); err != nil {
+				return fmt.Errorf("invalid --against: %w", err)
+			}
+		}
+	} else {
+		if opts.compareRange != "" || opts.against != "" {
+			return fmt.Errorf("--range and --against require --compare custom")
+		}
+		var err error
+		current, previous, err = stats.PeriodRanges(time.Now(), opts.compare, weekStart)
+		if err != nil {
+			return fmt.Errorf("invalid --compare %q (expected day, week, month or custom)", opts.compare)
+		}
+	}
+
+	comparison, err := stats.Compare(store, current, previous)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, statsui.Comparison(th, comparison))
+	return err
+}
+
+// parseDateRange parses an inclusive local date range "YYYY-MM-DD..YYYY-MM-DD";
+// a single date selects one day
+func parseDateRange(value string) (stats.Range, error) {
+	first, last, found := strings.Cut(value, "..")
+	if !found {
+		last = first
+	}
+	from, err := history.ParseDay(first, time.Local)
+	if err != nil {
+		return stats.Range{}, err
+	}
+	to, err := history.ParseDay(last, time.Local)
+	if err != nil {
+		return stats.Range{}, err
+	}
+	if to.Before(from) {
+		return stats.Range{}, fmt.Errorf("range %q ends before it starts", value)
+	}
+	return stats.Range{From: from, To: to.AddDate(0, 0, 1)}, nil
+}
+
+// days counts the calendar days in r
+func days(r stats.Range) int {
+	n := 0
+	for day := r.From; day.Before(r.To); day = day.AddDate(0, 0, 1) {
+		n++
+	}
+	return n
+}
+
+// browse runs the interactive browser over sessions started in [from, to)
+func browse(store history.Store, th *theme.Theme, weekStart time.Weekday, from, to time.Time) error {
+	sessions, err := store.Between(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+
+	// Keep log output from corrupting the full-screen view
+	if err := logger.RedirectToFile(config.LogFilePath()); err != nil {
+		logger.WithError(err).Warn("Failed to redirect logger to file")
+	}
+
+	browser := statsui.NewBrowser(th, sessions, weekStart, time.Now())
+	if _, err := tea.NewProgram(browser, tea.WithAltScreen()).Run(); err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
 	return nil
 }
 
+// summarize streams every session started in [from, to) into a summary
+func summarize(store history.Store, from, to time.Time) (stats.Summary, error) {
+	var summary stats.Summary
+	err := history.Walk(store, history.Query{From: from, To: to}, func(s history.Session) error {
+		summary.Add(s)
+		return nil
+	})
+	if err != nil {
+		return stats.Summary{}, fmt.Errorf("failed to summarize history: %w", err)
+	}
+	return summary, nil
+}
+
+// printReport writes the session table followed by the summary block and,
+// when goals are configured, the goal block
+func printReport(out io.Writer, th *theme.Theme, today bool, recent []history.Session, summary stats.Summary, goal *stats.GoalReport) error {
+	title := "Summary (all time)"
+	if today {
+		title = "Summary (today)"
+	}
 
+	blocks := []string{"No sessions recorded"}
+	if len(recent) > 0 {
+		blocks = []string{statsui.SessionTable(th, recent), statsui.SummaryBlock(th, title, summary)}
+	}
+	if goal != nil {
+		blocks = append(blocks, statsui.GoalBlock(th, *goal))
+	}
+
+	_, err := fmt.Fprintln(out, strings.Join(blocks, "\n\n"))
+	return err
+}
+
+// printTotals writes grouped focus totals as an aligned table
+func printTotals(out io.Writer, groupBy string, totals []history.Total) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tSESSIONS\tFOCUS\n", strings.ToUpper(groupBy))
+	for _, t := range totals {
+		key := t.Key
+		if key == "" {
+			key = "(none)"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\n", key, t.Sessions, timer.FormatDuration(t.Focus))
+	}
+	return w.Flush()
+}
+
+// periodLayouts formats the start of each --by period in tables
+var periodLayouts = map[string]string{
+	stats.PeriodDay:   "2006-01-02 Mon",
+	stats.PeriodWeek:  "2006-01-02",
+	stats.PeriodMonth: "2006-01",
+}
+
+// printBuckets writes per-period focus totals as an aligned table
+func printBuckets(out io.Writer, by string, groupBy string, buckets []stats.Bucket) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	header := strings.ToUpper(by)
+	if by == stats.PeriodWeek {
+		header = "WEEK OF"
+	}
+	if groupBy != "" {
+		header += "\t" + strings.ToUpper(groupBy)
+	}
+	fmt.Fprintf(w, "%s\tSESSIONS\tFOCUS\n", header)
+
+	for _, b := range buckets {
+		period := b.Start.Format(periodLayouts[by])
+		if len(b.Totals) == 0 {
+			if groupBy != "" {
+				period += "\t-"
+			}
+			fmt.Fprintf(w, "%s\t0\t0s\n", period)
+			continue
+		}
+		for _, t := range b.Totals {
+			row := period
+			if groupBy != "" {
+				key := t.Key
+				if key == "" {
+					key = "(none)"
+				}
+				row += "\t" + key
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\n", row, t.Sessions, statsui.FormatFocus(t.Focus))
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/privacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logged returns a session of minutes minutes started at hour on a day of
+// January 2025
+func logged(id string, day, hour, minutes int, label, status string) history.Session {
+	start := time.Date(2025, time.January, day, hour, 0, 0, 0, time.Local)
+	return history.Session{
+		ID:             id,
+		StartedAt:      start,
+		EndedAt:        start.Add(time.Duration(minutes) * time.Minute),
+		Duration:       (time.Duration(minutes) * time.Minute).String(),
+		Preset:         "work",
+		Label:          label,
+		EndStatus:      status,
+		PausedDuration: "0s",
+	}
+}
+
+// setupHistory points the XDG directories at a temp dir, saves cfg there
+// and records a history holding a private session and a 2024 session
+// compacted into the archives
+func setupHistory(t *testing.T, cfg *config.Config) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir+"/config")
+	t.Setenv("XDG_STATE_HOME", dir+"/state")
+	t.Setenv("XDG_CACHE_HOME", dir+"/cache")
+	require.NoError(t, config.SaveToPath(cfg, config.ConfigPath()))
+
+	store, err := history.Open(cfg.History.Backend, config.StatePath())
+	require.NoError(t, err)
+	defer store.Close()
+
+	archived := logged("old", 1, 9, 25, "Year review", history.StatusCompleted)
+	archived.StartedAt = archived.StartedAt.AddDate(-1, 0, 0)
+	archived.EndedAt = archived.EndedAt.AddDate(-1, 0, 0)
+	fix := logged("fix", 13, 9, 25, "Fix login", history.StatusCompleted)
+	fix.Project, fix.Tags = "auth", []string{"bugfix"}
+	doctor := logged("doctor", 13, 11, 25, "Doctor", history.StatusCompleted)
+	doctor.Private, doctor.Project = true, "health"
+	for _, s := range []history.Session{
+		archived,
+		fix,
+		doctor,
+		logged("retry", 14, 9, 10, "Fix login", history.StatusInterrupted),
+		logged("plan", 15, 14, 45, "Planning", history.StatusManual),
+	} {
+		require.NoError(t, store.Append(s))
+	}
+
+	cutoff := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)
+	result, err := history.Compact(store, filepath.Join(config.StatePath(), history.ArchiveDirName), cutoff, false, false)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Sessions)
+}
+
+// run executes pomodux-stats with args and returns what it wrote to stdout
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := newRootCmd()
+	cmd.SetArgs(args)
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestShowStats(t *testing.T) {
+	setupHistory(t, config.DefaultConfig())
+
+	out, err := run(t, "--all")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Fix login")
+	assert.Contains(t, out, "Year review", "archived sessions are read back")
+	assert.Contains(t, out, privacy.PrivateLabel)
+	assert.NotContains(t, out, "Doctor", "private labels never leave the history")
+
+	out, err = run(t, "--all", "--format", "csv")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 6, "header and five sessions")
+	assert.True(t, strings.HasPrefix(lines[0], "id,started_at"), lines[0])
+	assert.Contains(t, out, "plan,")
+	assert.NotContains(t, out, "Doctor")
+	assert.NotContains(t, out, "health")
+
+	_, err = run(t, "--format", "xml")
+	assert.ErrorContains(t, err, `invalid --format "xml"`)
+}
+
+func TestShowStats_Totals(t *testing.T) {
+	setupHistory(t, config.DefaultConfig())
+
+	out, err := run(t, "--group-by", "project")
+	require.NoError(t, err)
+	assert.Contains(t, out, "PROJECT")
+	assert.Contains(t, out, "auth")
+	assert.NotContains(t, out, "health", "private sessions have no project")
+
+	out, err = run(t, "--group-by", "label", "--format", "json")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"Fix login"`)
+	assert.Contains(t, out, `"`+privacy.PrivateLabel+`"`)
+
+	_, err = run(t, "--group-by", "colour")
+	assert.ErrorContains(t, err, `invalid --group-by "colour"`)
+
+	out, err = run(t, "--by", "day")
+	require.NoError(t, err)
+	assert.Contains(t, out, "DAY")
+	assert.Contains(t, out, "2025-01-13 Mon")
+	assert.Contains(t, out, "2025-01-15 Wed")
+
+	out, err = run(t, "--by", "month", "--group-by", "label", "--format", "csv")
+	require.NoError(t, err)
+	assert.Contains(t, out, "2024-01")
+	assert.Contains(t, out, "Year review")
+
+	_, err = run(t, "--by", "fortnight")
+	assert.ErrorContains(t, err, "invalid --by")
+}
+
+func TestShowStats_Views(t *testing.T) {
+	setupHistory(t, config.DefaultConfig())
+
+	out, err := run(t, "--heatmap-weeks", "4")
+	require.NoError(t, err)
+	assert.NotEmpty(t, out, "--heatmap-weeks implies --heatmap")
+	_, err = run(t, "--heatmap-weeks", "0")
+	assert.ErrorContains(t, err, "--heatmap-weeks must be positive")
+	_, err = run(t, "--heatmap", "--format", "json")
+	assert.ErrorContains(t, err, "--heatmap only supports the table format")
+
+	out, err = run(t, "--distribution")
+	require.NoError(t, err)
+	assert.NotEmpty(t, out)
+	_, err = run(t, "--distribution", "--format", "csv")
+	assert.ErrorContains(t, err, "--distribution only supports the table format")
+
+	out, err = run(t, "--compare", "custom", "--range", "2025-01-13..2025-01-15", "--against", "2024-01-01")
+	require.NoError(t, err)
+	assert.NotEmpty(t, out)
+	_, err = run(t, "--compare", "custom")
+	assert.ErrorContains(t, err, "--compare custom requires --range")
+	_, err = run(t, "--compare", "week", "--range", "2025-01-13")
+	assert.ErrorContains(t, err, "--range and --against require --compare custom")
+	_, err = run(t, "--compare", "fortnight")
+	assert.ErrorContains(t, err, `invalid --compare "fortnight"`)
+}
+
+func TestExport(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Privacy.ExportLabels = privacy.ModeRedact
+	setupHistory(t, cfg)
+
+	// privacy.export_labels applies unless --redact overrides it
+	out, err := run(t, "export", "--format", "csv")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Fix login")
+	assert.Contains(t, out, privacy.Redacted)
+	assert.Contains(t, out, privacy.PrivateLabel)
+
+	out, err = run(t, "--all", "--format", "json")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "Fix login", "stats output in a file format is redacted too")
+
+	out, err = run(t, "export", "--format", "csv", "--redact", "plain")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Fix login")
+	assert.Contains(t, out, "Year review", "archived sessions are exported")
+	assert.NotContains(t, out, "Doctor")
+
+	// Filters never match private sessions, whatever they hold
+	out, err = run(t, "export", "--format", "csv", "--redact", "plain", "--project", "health")
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(strings.TrimSpace(out), "\n")+1, "only the header")
+
+	path := filepath.Join(t.TempDir(), "sessions.org")
+	_, err = run(t, "export", "--format", "org", "--redact", "plain", "--from", "2025-01-14", "-o", path)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "* Fix login")
+	assert.Contains(t, string(data), "* Planning")
+	assert.NotContains(t, string(data), "2025-01-13")
+
+	_, err = run(t, "export", "--format", "xlsx")
+	assert.ErrorContains(t, err, `invalid --format "xlsx"`)
+	_, err = run(t, "export", "--redact", "blur")
+	assert.ErrorContains(t, err, "invalid --redact")
+	_, err = run(t, "export", "--status", "finished")
+	assert.ErrorContains(t, err, `invalid --status "finished"`)
+}
+
+func TestReport(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Privacy.ExportLabels = privacy.ModeHash
+	setupHistory(t, cfg)
+
+	out, err := run(t, "report", "--week", "2025-W03", "--redact", "plain")
+	require.NoError(t, err)
+	assert.Contains(t, out, "2025-W03")
+	assert.Contains(t, out, "Fix login")
+	assert.Contains(t, out, privacy.PrivateLabel)
+	assert.NotContains(t, out, "Doctor")
+
+	out, err = run(t, "report", "--week", "2025-W03", "--format", "html")
+	require.NoError(t, err)
+	assert.Contains(t, out, "<html")
+	assert.NotContains(t, out, "Fix login", "privacy.export_labels hashes labels")
+
+	_, err = run(t, "report", "--format", "pdf")
+	assert.ErrorContains(t, err, `invalid --format "pdf"`)
+	_, err = run(t, "report", "--week", "last")
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/report"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate a weekly focus report as Markdown or HTML",
+		Long: "Generate a self-contained weekly report with a summary, per-day table, " +
+			"per-label breakdown, session notes and a focus chart",
+		Args: cobra.NoArgs,
+		RunE: writeReport,
+	}
+
+	cmd.Flags().String("week", "", "ISO week to report on, e.g. 2026-W41 (default: the current week)")
+	cmd.Flags().String("format", report.FormatMarkdown, "Output format: markdown or html")
+	cmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+	cmd.Flags().String("redact", "", "Show labels and notes as plain, hash or redact (default privacy.export_labels)")
+	return cmd
+}
+
+func writeReport(cmd *cobra.Command, args []string) error {
+	week, _ := cmd.Flags().GetString("week")
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+
+	if format != report.FormatMarkdown && format != report.FormatHTML {
+		return fmt.Errorf("invalid --format %q (expected markdown or html)", format)
+	}
+	if week == "" {
+		week = report.WeekOf(time.Now())
+	}
+	monday, err := report.ParseWeek(week, time.Local)
+	if err != nil {
+		return err
+	}
+
+	cfg, store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	mode, err := redactMode(cmd, cfg.Privacy.ExportLabels)
+	if err != nil {
+		return err
+	}
+	store = history.NewRedactedStore(store, mode)
+
+	r, err := report.Build(store, monday)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		return report.Write(cmd.OutOrStdout(), format, r)
+	}
+	var buf bytes.Buffer
+	if err := report.Write(&buf, format, r); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %s report for %s to %s\n", format, r.Week, output)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// newCompactCmd builds the "pomodux history compact" command
+func newCompactCmd() *cobra.Command {
+	compactCmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Move old sessions into yearly archives",
+		Long: "Move sessions older than history.retention.keep_months whole months into yearly archives " +
+			"(archive/history-YYYY.json in the state directory), each with a rollup of every day. " +
+			"With history.retention.rollup_only only the daily rollups are kept. " +
+			"pomodux-stats reads the archives for ranges reaching back into them. " +
+			"Compaction is not recorded in the audit log and cannot be undone.",
+		Example: "  pomodux history compact --dry-run\n" +
+			"  pomodux history compact --keep-months 12",
+		Args: cobra.NoArgs,
+		RunE: compactHistory,
+	}
+	compactCmd.Flags().Int("keep-months", 0, "Months of sessions to keep (default history.retention.keep_months)")
+	compactCmd.Flags().Bool("rollup-only", false, "Keep only daily rollups of archived sessions (default history.retention.rollup_only)")
+	compactCmd.Flags().Bool("dry-run", false, "Show what would be archived without changing the history")
+	return compactCmd
+}
+
+func compactHistory(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+
+	keepMonths := cfg.History.Retention.KeepMonths
+	if cmd.Flags().Changed("keep-months") {
+		keepMonths, _ = cmd.Flags().GetInt("keep-months")
+	}
+	rollupOnly := cfg.History.Retention.RollupOnly
+	if cmd.Flags().Changed("rollup-only") {
+		rollupOnly, _ = cmd.Flags().GetBool("rollup-only")
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if keepMonths <= 0 {
+		return fmt.Errorf("no retention configured; set history.retention.keep_months or pass --keep-months")
+	}
+
+	_, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	cutoff := history.RetentionCutoff(time.Now(), keepMonths)
+	dir := filepath.Join(config.StatePath(), history.ArchiveDirName)
+	result, err := history.Compact(store, dir, cutoff, rollupOnly, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to compact history: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if result.Sessions == 0 {
+		fmt.Fprintf(out, "No sessions before %s to archive\n", cutoff.Format(time.DateOnly))
+		return nil
+	}
+
+	if !dryRun {
+		logger.WithFields(map[string]interface{}{
+			"component": "history",
+			"event":     "history_compacted",
+			"sessions":  result.Sessions,
+			"cutoff":    cutoff.Format(time.DateOnly),
+		}).Info("History compacted")
+	}
+
+	years := make([]string, len(result.Years))
+	for i, year := range result.Years {
+		years[i] = filepath.Base(history.ArchivePath(dir, year))
+	}
+	kind := "sessions and daily rollups"
+	if rollupOnly {
+		kind = "daily rollups"
+	}
+	verb := "Archived"
+	if dryRun {
+		verb = "Would archive"
+	}
+	fmt.Fprintf(out, "%s %d session(s) from %d day(s) before %s as %s in %s\n",
+		verb, result.Sessions, result.Days, cutoff.Format(time.DateOnly), kind, strings.Join(years, ", "))
+	return nil
+}
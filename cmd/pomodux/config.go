@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// newConfigCmd builds the "pomodux config" command and its subcommands
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show and change the configuration",
+		Long: "Show and change the configuration file. Keys are dotted paths such as timer.bell_on_complete " +
+			"or timers.deep. Changes keep the file's comments and key order and are validated before saving.",
+	}
+
+	getCmd := &cobra.Command{
+		Use:     "get <key>",
+		Short:   "Print the value in effect for a key",
+		Example: "  pomodux config get timers.work\n  pomodux config get goals",
+		Args:    cobra.ExactArgs(1),
+		RunE:    getConfig,
+	}
+
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Set a key in the configuration file",
+		Long: "Set a key in the configuration file. Lists take [a, b] or comma separated values. " +
+			"The change is refused if it makes the configuration invalid.",
+		Example: "  pomodux config set timer.bell_on_complete true\n" +
+			"  pomodux config set timers.deep 90m\n" +
+			"  pomodux config set goals.break_presets \"break, long_break\"",
+		Args: cobra.ExactArgs(2),
+		RunE: setConfig,
+	}
+
+	unsetCmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a key from the configuration file so its default applies",
+		Args:  cobra.ExactArgs(1),
+		RunE:  unsetConfig,
+	}
+
+	validateCmd := &cobra.Command{
+		Use:   "validate [file]",
+		Short: "Check the configuration file for unknown keys and invalid values",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  validateConfig,
+	}
+
+	editCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit the configuration file in $VISUAL or $EDITOR",
+		Long: "Open a copy of the configuration file in $VISUAL, $EDITOR or vi. " +
+			"The file is only replaced once the edited copy is valid.",
+		Args: cobra.NoArgs,
+		RunE: editConfig,
+	}
+
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the configuration file path",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintln(cmd.OutOrStdout(), config.ConfigPath())
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the configuration file",
+		Args:  cobra.NoArgs,
+		RunE:  showConfig,
+	}
+	showCmd.Flags().Bool("effective", false, "Print the configuration in effect, with defaults applied")
+
+	configCmd.AddCommand(getCmd, setCmd, unsetCmd, validateCmd, editCmd, pathCmd, showCmd)
+	return configCmd
+}
+
+func getConfig(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	value, err := config.Lookup(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), value)
+	return nil
+}
+
+func setConfig(cmd *cobra.Command, args []string) error {
+	path := config.ConfigPath()
+	doc, err := config.LoadDocument(path)
+	if err != nil {
+		return err
+	}
+	if err := doc.Set(args[0], args[1]); err != nil {
+		return err
+	}
+	if err := doc.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Set %s to %s in %s\n", args[0], args[1], path)
+	return nil
+}
+
+func unsetConfig(cmd *cobra.Command, args []string) error {
+	path := config.ConfigPath()
+	doc, err := config.LoadDocument(path)
+	if err != nil {
+		return err
+	}
+	if !doc.Unset(args[0]) {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is not set in %s\n", args[0], path)
+		return nil
+	}
+	if err := doc.Save(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from %s\n", args[0], path)
+	return nil
+}
+
+func validateConfig(cmd *cobra.Command, args []string) error {
+	path := config.ConfigPath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	_, problems, err := config.Validate(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	out := cmd.OutOrStdout()
+	for _, problem := range problems {
+		fmt.Fprintf(out, "%s: %s\n", path, problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s has %d problem(s)", path, len(problems))
+	}
+	fmt.Fprintf(out, "%s is valid\n", path)
+	return nil
+}
+
+func showConfig(cmd *cobra.Command, args []string) error {
+	effective, _ := cmd.Flags().GetBool("effective")
+	if !effective {
+		data, err := os.ReadFile(config.ConfigPath())
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no config file at %s; use --effective to show the defaults", config.ConfigPath())
+		} else if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+func editConfig(cmd *cobra.Command, args []string) error {
+	path := config.ConfigPath()
+	original, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		// Start from the defaults LoadDocument fills in for a missing file
+		doc, err := config.LoadDocument(path)
+		if err != nil {
+			return err
+		}
+		if original, err = doc.Bytes(); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	_, before, _ := config.Validate(original)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	in := bufio.NewReader(cmd.InOrStdin())
+	for {
+		if err := runEditor(tmpPath); err != nil {
+			return fmt.Errorf("%w (changes kept in %s)", err, tmpPath)
+		}
+		data, err := os.ReadFile(tmpPath)
+		if err != nil {
+			return fmt.Errorf("failed to read edited config: %w", err)
+		}
+
+		_, after, err := config.Validate(data)
+		if err == nil {
+			if added := config.NewProblems(before, after); len(added) > 0 {
+				err = fmt.Errorf("invalid config: %s", strings.Join(added, "; "))
+			}
+		}
+		if err == nil {
+			if err := atomicfile.WriteFile(path, data, 0600); err != nil {
+				return fmt.Errorf("failed to write config file: %w", err)
+			}
+			os.Remove(tmpPath)
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %s\n", path)
+			return nil
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "%v\nEdit again? [Y/n] ", err)
+		answer, _ := in.ReadString('\n')
+		if answer == "" || strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			return fmt.Errorf("config not saved; changes kept in %s", tmpPath)
+		}
+	}
+}
+
+// runEditor opens path in $VISUAL, $EDITOR or vi
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	fields := strings.Fields(editor)
+	c := exec.Command(fields[0], append(fields[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %s failed: %w", fields[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/encryption"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// newEncryptionCmd builds the "pomodux encryption" command and its subcommands
+func newEncryptionCmd() *cobra.Command {
+	encryptionCmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Encrypt the history and timer state at rest",
+		Long: "Encrypt history.json, its archives, the audit log and the timer state with AES-256-GCM " +
+			"under a key protected by a passphrase or a keyfile. Encryption requires the json history backend. " +
+			"Commands reading the history ask for the passphrase, or read it from $" + encryption.PassphraseEnv + ". " +
+			"Run these commands while no timer is running.",
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the history is encrypted",
+		Args:  cobra.NoArgs,
+		RunE:  encryptionStatus,
+	}
+
+	enableCmd := &cobra.Command{
+		Use:   "enable",
+		Short: "Encrypt the history with a passphrase or keyfile",
+		Long: "Encrypt the history and timer state. Without --keyfile a new passphrase is asked for twice, " +
+			"or read from $" + encryption.NewPassphraseEnv + ". Keep the passphrase or keyfile safe: " +
+			"the history cannot be recovered without it.",
+		Example: "  pomodux encryption enable\n" +
+			"  pomodux encryption enable --keyfile ~/.config/pomodux/history.key --generate-keyfile",
+		Args: cobra.NoArgs,
+		RunE: enableEncryption,
+	}
+	enableCmd.Flags().String("keyfile", "", "Protect the key with this keyfile instead of a passphrase")
+	enableCmd.Flags().Bool("generate-keyfile", false, "Create a new random keyfile at --keyfile")
+
+	rotateCmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Re-encrypt the history under a new key",
+		Long: "Re-encrypt the history and timer state under a new data key. The new key is protected by " +
+			"--keyfile, by the current keyfile, or by a new passphrase asked for twice or read from $" +
+			encryption.NewPassphraseEnv + ". An interrupted rotation is completed by rotating again.",
+		Example: "  pomodux encryption rotate\n" +
+			"  pomodux encryption rotate --passphrase",
+		Args: cobra.NoArgs,
+		RunE: rotateEncryption,
+	}
+	rotateCmd.Flags().String("keyfile", "", "Protect the new key with this keyfile")
+	rotateCmd.Flags().Bool("generate-keyfile", false, "Create a new random keyfile at --keyfile")
+	rotateCmd.Flags().Bool("passphrase", false, "Switch from a keyfile to a passphrase")
+	rotateCmd.MarkFlagsMutuallyExclusive("keyfile", "passphrase")
+
+	disableCmd := &cobra.Command{
+		Use:   "disable",
+		Short: "Decrypt the history and turn encryption off",
+		Args:  cobra.NoArgs,
+		RunE:  disableEncryption,
+	}
+
+	exportCmd := &cobra.Command{
+		Use:     "export",
+		Short:   "Write a decrypted copy of the history",
+		Long:    "Write the history as plain history.json to stdout or a file, leaving the encrypted history unchanged.",
+		Example: "  pomodux encryption export -o history-backup.json",
+		Args:    cobra.NoArgs,
+		RunE:    exportDecrypted,
+	}
+	exportCmd.Flags().StringP("output", "o", "", "Write to this file instead of stdout")
+
+	encryptionCmd.AddCommand(statusCmd, enableCmd, rotateCmd, disableCmd, exportCmd)
+	return encryptionCmd
+}
+
+// encryptedFiles lists the files of the state directory covered by encryption
+func encryptedFiles() encryption.Files {
+	dir := config.StatePath()
+	historyPath := filepath.Join(dir, history.FileName(history.BackendJSON))
+	archiveDir := filepath.Join(dir, history.ArchiveDirName)
+	archives, _ := filepath.Glob(filepath.Join(archiveDir, "history-*.json"))
+	return encryption.Files{
+		Documents: append([]string{historyPath, config.TimerStatePath()}, archives...),
+		Lines:     []string{filepath.Join(dir, history.AuditFileName)},
+		Lock: func(path string, fn func() error) error {
+			switch {
+			case path == config.TimerStatePath():
+				// Only a running timer writes it, and checkNoTimer rules that out
+				return fn()
+			case filepath.Dir(path) == archiveDir:
+				// Compaction writes archives under the history file's lock
+				return history.WithLock(historyPath, fn)
+			default:
+				return history.WithLock(path, fn)
+			}
+		},
+	}
+}
+
+// checkNoTimer refuses to change encryption while a timer session is in
+// progress: its process keeps the keys it started with and would write the
+// history with them when the session ends
+func checkNoTimer() error {
+	if stateExists(config.TimerStatePath()) {
+		return fmt.Errorf("a timer session is in progress; finish it (run 'pomodux' to resume an interrupted one) before changing encryption")
+	}
+	return nil
+}
+
+// newKeyDescriptor builds the descriptor protecting a new data key, from
+// keyfile when set, else from a new passphrase
+func newKeyDescriptor(keyfile string, generate bool) (*encryption.Descriptor, []byte, error) {
+	if generate {
+		if keyfile == "" {
+			return nil, nil, fmt.Errorf("--generate-keyfile requires --keyfile")
+		}
+		if err := encryption.GenerateKeyfile(keyfile); err != nil {
+			return nil, nil, err
+		}
+	}
+	if keyfile != "" {
+		return encryption.NewDescriptor("", keyfile)
+	}
+
+	passphrase := os.Getenv(encryption.NewPassphraseEnv)
+	if passphrase == "" {
+		var err error
+		if passphrase, err = encryption.PromptPassphrase("New passphrase: "); err != nil {
+			return nil, nil, err
+		}
+		confirm, err := encryption.PromptPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return nil, nil, err
+		}
+		if confirm != passphrase {
+			return nil, nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return encryption.NewDescriptor(passphrase, "")
+}
+
+func encryptionStatus(cmd *cobra.Command, args []string) error {
+	d, err := encryption.LoadDescriptor(config.StatePath())
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		fmt.Fprintln(cmd.OutOrStdout(), "Encryption is off")
+		return nil
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Encryption is on, protected by %s\n", d.Describe())
+	return nil
+}
+
+func enableEncryption(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	if encryption.Active() != nil {
+		return fmt.Errorf("encryption is already enabled; use \"pomodux encryption rotate\" to change the key")
+	}
+	if cfg.History.Backend != history.BackendJSON {
+		return fmt.Errorf("encryption requires the %s history backend, not %s", history.BackendJSON, cfg.History.Backend)
+	}
+	if err := checkNoTimer(); err != nil {
+		return err
+	}
+
+	keyfile, _ := cmd.Flags().GetString("keyfile")
+	generate, _ := cmd.Flags().GetBool("generate-keyfile")
+	d, kek, err := newKeyDescriptor(keyfile, generate)
+	if err != nil {
+		return err
+	}
+	if _, err := encryption.Enable(config.StatePath(), d, kek, encryptedFiles()); err != nil {
+		return fmt.Errorf("failed to enable encryption: %w", err)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component": "encryption",
+		"event":     "encryption_enabled",
+		"kdf":       d.KDF,
+	}).Info("History encryption enabled")
+	fmt.Fprintf(cmd.OutOrStdout(), "Encrypted the history, protected by %s\n", d.Describe())
+	return nil
+}
+
+func rotateEncryption(cmd *cobra.Command, args []string) error {
+	if _, err := initCommand(); err != nil {
+		return err
+	}
+	current, err := encryption.LoadDescriptor(config.StatePath())
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("encryption is not enabled")
+	}
+	if err := checkNoTimer(); err != nil {
+		return err
+	}
+
+	keyfile, _ := cmd.Flags().GetString("keyfile")
+	generate, _ := cmd.Flags().GetBool("generate-keyfile")
+	usePassphrase, _ := cmd.Flags().GetBool("passphrase")
+	if keyfile == "" && !usePassphrase && current.KDF == encryption.KDFKeyfile {
+		keyfile = current.Keyfile
+	}
+	d, kek, err := newKeyDescriptor(keyfile, generate)
+	if err != nil {
+		return err
+	}
+	if _, err := encryption.Rotate(config.StatePath(), encryption.Active(), d, kek, encryptedFiles()); err != nil {
+		return fmt.Errorf("failed to rotate key: %w", err)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component": "encryption",
+		"event":     "encryption_rotated",
+		"kdf":       d.KDF,
+	}).Info("History encryption key rotated")
+	fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted the history under a new key, protected by %s\n", d.Describe())
+	return nil
+}
+
+func disableEncryption(cmd *cobra.Command, args []string) error {
+	if _, err := initCommand(); err != nil {
+		return err
+	}
+	ring := encryption.Active()
+	if ring == nil {
+		return fmt.Errorf("encryption is not enabled")
+	}
+	if err := checkNoTimer(); err != nil {
+		return err
+	}
+	if err := encryption.Disable(config.StatePath(), ring, encryptedFiles()); err != nil {
+		return fmt.Errorf("failed to disable encryption: %w", err)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component": "encryption",
+		"event":     "encryption_disabled",
+	}).Info("History encryption disabled")
+	fmt.Fprintln(cmd.OutOrStdout(), "Decrypted the history; encryption is off")
+	return nil
+}
+
+func exportDecrypted(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	store, err := history.Open(cfg.History.Backend, config.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer store.Close()
+	h, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+	data = append(data, '\n')
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "" {
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d session(s) to %s\n", len(h.Sessions), output)
+	return nil
+}
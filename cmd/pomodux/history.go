@@ -0,0 +1,452 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/encryption"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/timer"
+	"github.com/spf13/cobra"
+)
+
+// newHistoryCmd builds the "pomodux history" command family
+func newHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "List and correct recorded sessions",
+		Long:  "List, inspect, edit and delete recorded sessions. Every change is written to an audit log and can be undone.",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recent sessions, newest first",
+		Args:  cobra.NoArgs,
+		RunE:  listSessions,
+	}
+	listCmd.Flags().IntP("limit", "l", 20, "Show last N sessions (0 for all)")
+
+	showCmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show a session by ID or ID prefix",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showSession,
+	}
+
+	editCmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Edit a session's label, project, tags, times, end status, notes or privacy",
+		Long: "Edit a session by ID or ID prefix. Times accept HH:MM (on the session's start date), " +
+			"YYYY-MM-DD HH:MM or RFC 3339. The edited session must not overlap another session.",
+		Args: cobra.ExactArgs(1),
+		RunE: editSession,
+	}
+	editCmd.Flags().String("label", "", "New label")
+	editCmd.Flags().String("project", "", "New project (empty string clears it)")
+	editCmd.Flags().StringArray("tag", nil, "Replace all tags (repeatable)")
+	editCmd.Flags().StringArray("add-tag", nil, "Add a tag (repeatable)")
+	editCmd.Flags().StringArray("remove-tag", nil, "Remove a tag (repeatable)")
+	editCmd.Flags().String("start", "", "New start time")
+	editCmd.Flags().String("end", "", "New end time")
+	editCmd.Flags().String("duration", "", "New planned duration (e.g., 25m)")
+	editCmd.Flags().String("status", "", "New end status ("+strings.Join(history.EndStatuses, ", ")+")")
+	editCmd.Flags().String("notes", "", "New notes (empty string clears them)")
+	editCmd.Flags().Bool("private", false, "Keep the label, project, tags and notes out of logs, stats and exports (--private=false to undo)")
+	editCmd.Flags().Int("record", 0, "Record to edit when a resumed session has several (see 'history show')")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete a session by ID or ID prefix",
+		Long:  "Delete a session by ID or ID prefix, with every record of it if it was resumed. One undo restores them all.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  deleteSession,
+	}
+
+	undoCmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Undo the last history change",
+		Args:  cobra.NoArgs,
+		RunE:  undoChange,
+	}
+
+	historyCmd.AddCommand(listCmd, showCmd, editCmd, deleteCmd, undoCmd, newImportCmd(), newMergeCmd(), newCompactCmd())
+	return historyCmd
+}
+
+// initCommand loads configuration and sets up logging for non-TUI commands.
+// Logs go to the log file so they do not mix with command output.
+func initCommand() (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	logFile := cfg.Logging.File
+	if logFile == "" {
+		logFile = config.LogFilePath()
+		if err := os.MkdirAll(filepath.Dir(logFile), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+	if err := logger.Init(logger.Config{
+		Level:  cfg.Logging.Level,
+		File:   logFile,
+		Labels: cfg.Privacy.LogLabels,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	if _, err := encryption.UnlockDir(config.StatePath(), nil); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// openEditor opens the configured history store with its audit log
+func openEditor(cfg *config.Config) (*history.Editor, history.Store, error) {
+	store, err := history.Open(cfg.History.Backend, config.StatePath())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	audit := history.NewAuditLog(filepath.Join(config.StatePath(), history.AuditFileName))
+	editor := history.NewEditor(store, audit)
+	editor.SetArchiveDir(filepath.Join(config.StatePath(), history.ArchiveDirName))
+	return editor, store, nil
+}
+
+func listSessions(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	_, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	page, err := store.Query(history.Query{Order: history.NewestFirst, Limit: limit})
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+
+	if len(page.Sessions) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No sessions recorded yet")
+		return nil
+	}
+	return printSessionList(cmd.OutOrStdout(), page.Sessions)
+}
+
+func showSession(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	records, err := editor.Records(args[0])
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	for i, session := range records {
+		if len(records) > 1 {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "Record %d of %d\n", i+1, len(records))
+		}
+		printSession(out, session)
+	}
+	return nil
+}
+
+func editSession(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	record, _ := flags.GetInt("record")
+	if flags.NFlag() == 0 || (flags.NFlag() == 1 && flags.Changed("record")) {
+		return fmt.Errorf("nothing to change; see 'pomodux history edit --help'")
+	}
+
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	before, after, err := editor.EditRecord(args[0], record, func(s *history.Session) error {
+		return applyEditFlags(cmd, s)
+	})
+	if errors.Is(err, history.ErrSeveralRecords) {
+		return fmt.Errorf("%w with --record; 'pomodux history show %s' lists them", err, args[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component":  "history",
+		"event":      "session_edited",
+		"session_id": after.ID,
+	}).Info("Session edited")
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Updated session %s\n", history.ShortID(after.ID))
+	printChanges(out, before, after)
+	return nil
+}
+
+// applyEditFlags applies the flags set on the edit command to s
+func applyEditFlags(cmd *cobra.Command, s *history.Session) error {
+	flags := cmd.Flags()
+	day := s.StartedAt.Local()
+
+	if flags.Changed("label") {
+		label, _ := flags.GetString("label")
+		if len(label) > 200 {
+			return fmt.Errorf("label too long (max 200 chars), got %d", len(label))
+		}
+		s.Label = label
+	}
+	if flags.Changed("notes") {
+		s.Notes, _ = flags.GetString("notes")
+	}
+	if flags.Changed("private") {
+		s.Private, _ = flags.GetBool("private")
+	}
+	if flags.Changed("project") {
+		project, _ := flags.GetString("project")
+		if project != "" {
+			if err := timer.ValidateName("project", project); err != nil {
+				return err
+			}
+		}
+		s.Project = project
+	}
+	if flags.Changed("tag") {
+		tags, _ := flags.GetStringArray("tag")
+		s.Tags = nil
+		for _, tag := range tags {
+			if tag == "" {
+				continue
+			}
+			if err := timer.ValidateName("tag", tag); err != nil {
+				return err
+			}
+			s.Tags = timer.MergeTags(s.Tags, []string{tag})
+		}
+	}
+	if flags.Changed("add-tag") {
+		tags, _ := flags.GetStringArray("add-tag")
+		for _, tag := range tags {
+			if err := timer.ValidateName("tag", tag); err != nil {
+				return err
+			}
+		}
+		s.Tags = timer.MergeTags(s.Tags, tags)
+	}
+	if flags.Changed("remove-tag") {
+		remove, _ := flags.GetStringArray("remove-tag")
+		kept := s.Tags[:0]
+		for _, tag := range s.Tags {
+			if !containsString(remove, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		s.Tags = kept
+		if len(s.Tags) == 0 {
+			s.Tags = nil
+		}
+	}
+	if flags.Changed("start") {
+		value, _ := flags.GetString("start")
+		start, err := history.ParseTime(value, day)
+		if err != nil {
+			return err
+		}
+		s.StartedAt = start
+	}
+	if flags.Changed("end") {
+		value, _ := flags.GetString("end")
+		end, err := history.ParseTime(value, day)
+		if err != nil {
+			return err
+		}
+		s.EndedAt = end
+	}
+	if flags.Changed("duration") {
+		value, _ := flags.GetString("duration")
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		s.Duration = timer.FormatDuration(d)
+	}
+	if flags.Changed("status") {
+		s.EndStatus, _ = flags.GetString("status")
+	}
+	return nil
+}
+
+func deleteSession(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	deleted, err := editor.Delete(args[0])
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component":  "history",
+		"event":      "session_deleted",
+		"session_id": deleted.ID,
+	}).Info("Session deleted")
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Deleted session %s (%s). Run 'pomodux history undo' to restore it.\n",
+		history.ShortID(deleted.ID), deleted.Label)
+	return nil
+}
+
+func undoChange(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	entry, err := editor.Undo()
+	if errors.Is(err, history.ErrNothingToUndo) {
+		fmt.Fprintln(cmd.OutOrStdout(), "Nothing to undo")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component":  "history",
+		"event":      "change_undone",
+		"session_id": entry.SessionID,
+		"action":     entry.Action,
+	}).Info("History change undone")
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Undid %s of session %s (made %s)\n",
+		entry.Action, history.ShortID(entry.SessionID), entry.Time.Local().Format("2006-01-02 15:04"))
+	return nil
+}
+
+// printSessionList writes sessions as an aligned table
+func printSessionList(out io.Writer, sessions []history.Session) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tFOCUS\tSTATUS\tLABEL")
+	for _, s := range sessions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			history.ShortID(s.ID),
+			s.StartedAt.Local().Format("2006-01-02 15:04"),
+			timer.FormatDuration(s.FocusDuration().Truncate(time.Second)),
+			s.EndStatus,
+			labelWithMeta(s),
+		)
+	}
+	return w.Flush()
+}
+
+// printSession writes every field of a session
+func printSession(out io.Writer, s history.Session) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%s\n", s.ID)
+	fmt.Fprintf(w, "Label:\t%s\n", s.Label)
+	if s.Project != "" {
+		fmt.Fprintf(w, "Project:\t%s\n", s.Project)
+	}
+	if len(s.Tags) > 0 {
+		fmt.Fprintf(w, "Tags:\t%s\n", strings.Join(s.Tags, ", "))
+	}
+	if s.Preset != "" {
+		fmt.Fprintf(w, "Preset:\t%s\n", s.Preset)
+	}
+	fmt.Fprintf(w, "Started:\t%s\n", s.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Ended:\t%s\n", s.EndedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(w, "Duration:\t%s\n", s.Duration)
+	fmt.Fprintf(w, "Focus:\t%s\n", timer.FormatDuration(s.FocusDuration().Truncate(time.Second)))
+	fmt.Fprintf(w, "Status:\t%s\n", s.EndStatus)
+	fmt.Fprintf(w, "Pauses:\t%d (%s)\n", s.PausedCount, s.PausedDuration)
+	if s.Notes != "" {
+		fmt.Fprintf(w, "Notes:\t%s\n", s.Notes)
+	}
+	if s.Private {
+		fmt.Fprintf(w, "Private:\tyes\n")
+	}
+	w.Flush()
+}
+
+// printChanges writes the fields that differ between two versions of a session
+func printChanges(out io.Writer, before, after history.Session) {
+	change := func(field, old, new string) {
+		if old != new {
+			fmt.Fprintf(out, "  %s: %q -> %q\n", field, old, new)
+		}
+	}
+	timeFormat := "2006-01-02 15:04:05"
+	change("label", before.Label, after.Label)
+	change("project", before.Project, after.Project)
+	change("tags", strings.Join(before.Tags, ","), strings.Join(after.Tags, ","))
+	change("started", before.StartedAt.Local().Format(timeFormat), after.StartedAt.Local().Format(timeFormat))
+	change("ended", before.EndedAt.Local().Format(timeFormat), after.EndedAt.Local().Format(timeFormat))
+	change("duration", before.Duration, after.Duration)
+	change("status", before.EndStatus, after.EndStatus)
+	change("notes", before.Notes, after.Notes)
+	change("private", strconv.FormatBool(before.Private), strconv.FormatBool(after.Private))
+}
+
+// labelWithMeta renders a label followed by its project and tags in label shorthand
+func labelWithMeta(s history.Session) string {
+	parts := []string{s.Label}
+	if s.Project != "" {
+		parts = append(parts, "+"+s.Project)
+	}
+	for _, tag := range s.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
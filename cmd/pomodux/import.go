@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/export"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/timer"
+	"github.com/spf13/cobra"
+)
+
+// Import formats besides those shared with the exporter
+const (
+	importFormatAuto    = "auto"
+	importFormatPomodux = "pomodux"
+)
+
+var importFormats = []string{
+	importFormatAuto, importFormatPomodux, export.FormatCSV,
+	export.FormatTimewarrior, export.FormatOrg, export.FormatToggl,
+}
+
+// importPrecision is the precision of the times each format keeps, so a
+// re-imported export still matches the sessions it was written from
+var importPrecision = map[string]time.Duration{
+	export.FormatCSV:         time.Second,
+	export.FormatTimewarrior: time.Second,
+	export.FormatOrg:         time.Minute,
+	export.FormatToggl:       time.Second,
+}
+
+// newImportCmd builds the "pomodux history import" command
+func newImportCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import sessions from CSV, Timewarrior, Org-mode, Toggl or another pomodux history",
+		Long: "Merge sessions from a file into the history in one atomic update. " +
+			"The format is detected from the file extension unless --format is given: " +
+			".json is a pomodux history.json, .csv a session CSV, .data or .timew Timewarrior data " +
+			"and .org an Org-mode document. Toggl CSV must be named with --format toggl.\n\n" +
+			"Sessions already in the history are skipped, matched by session ID or by start time and label " +
+			"(--dedup), as are sessions overlapping existing ones. A session matching the one it overlaps to the precision " +
+			"of its format (the minute for Org, the second otherwise) counts as already recorded, so re-importing an " +
+			"export changes nothing. Use --dry-run to see what would change.",
+		Example: "  pomodux history import old-laptop/history.json --dry-run\n" +
+			"  pomodux history import ~/.timewarrior/data/2025-01.data\n" +
+			"  pomodux history import tracker.csv --map started_at=Start --map label=Task --dedup start-label",
+		Args: cobra.ExactArgs(1),
+		RunE: importSessions,
+	}
+	importCmd.Flags().String("format", importFormatAuto, "Input format ("+strings.Join(importFormats, ", ")+")")
+	importCmd.Flags().StringArray("map", nil, "Read a CSV field from another column, as field=column (repeatable; fields: "+
+		strings.Join(export.ImportFields, ", ")+")")
+	importCmd.Flags().String("dedup", history.DedupID, "How to recognise sessions already recorded ("+
+		strings.Join(history.DedupStrategies, ", ")+")")
+	importCmd.Flags().Bool("dry-run", false, "Show what would be imported without changing the history")
+	return importCmd
+}
+
+func importSessions(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	mapFlags, _ := cmd.Flags().GetStringArray("map")
+	dedup, _ := cmd.Flags().GetString("dedup")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if format == importFormatAuto {
+		if format, err = detectImportFormat(args[0]); err != nil {
+			return err
+		}
+	} else if !containsString(importFormats, format) {
+		return fmt.Errorf("invalid format %q (expected %s)", format, strings.Join(importFormats, ", "))
+	}
+	mapping, err := parseFieldMapping(mapFlags)
+	if err != nil {
+		return err
+	}
+	if len(mapping) > 0 && format != export.FormatCSV {
+		return fmt.Errorf("--map only applies to CSV input")
+	}
+
+	sessions, err := readImport(args[0], format, mapping)
+	if err != nil {
+		return err
+	}
+
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	plan, err := editor.Import(sessions, dedup, importPrecision[format], dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", args[0], err)
+	}
+
+	if !dryRun {
+		logger.WithFields(map[string]interface{}{
+			"component":  "history",
+			"event":      "sessions_imported",
+			"file":       args[0],
+			"format":     format,
+			"added":      len(plan.Added),
+			"duplicates": len(plan.Duplicates),
+			"conflicts":  len(plan.Conflicts),
+		}).Info("Sessions imported")
+	}
+
+	printImportPlan(cmd.OutOrStdout(), plan, dryRun)
+	return nil
+}
+
+// detectImportFormat picks the import format from a file's extension
+func detectImportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return importFormatPomodux, nil
+	case ".csv":
+		return export.FormatCSV, nil
+	case ".data", ".timew":
+		return export.FormatTimewarrior, nil
+	case ".org":
+		return export.FormatOrg, nil
+	}
+	return "", fmt.Errorf("cannot detect the format of %s; use --format (%s)", path, strings.Join(importFormats[1:], ", "))
+}
+
+// parseFieldMapping parses --map field=column flags
+func parseFieldMapping(flags []string) (map[string]string, error) {
+	mapping := map[string]string{}
+	for _, flag := range flags {
+		field, column, ok := strings.Cut(flag, "=")
+		field = strings.TrimSpace(field)
+		if !ok || field == "" || column == "" {
+			return nil, fmt.Errorf("invalid --map %q (expected field=column)", flag)
+		}
+		mapping[field] = column
+	}
+	return mapping, nil
+}
+
+// readImport reads the sessions of an import file in the given format
+func readImport(path, format string, mapping map[string]string) ([]history.Session, error) {
+	if format == importFormatPomodux {
+		h, err := history.LoadCopy(path)
+		if err != nil {
+			return nil, err
+		}
+		return h.Sessions, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var sessions []history.Session
+	switch format {
+	case export.FormatCSV:
+		sessions, err = export.ReadCSV(f, mapping, time.Local)
+	case export.FormatTimewarrior:
+		sessions, err = export.ReadTimewarrior(f)
+	case export.FormatOrg:
+		sessions, err = export.ReadOrg(f, time.Local)
+	case export.FormatToggl:
+		sessions, err = export.ReadToggl(f, time.Local)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return sessions, nil
+}
+
+// printImportPlan writes the import as a diff: + added, = duplicate,
+// ! conflicting, x invalid, followed by a summary
+func printImportPlan(out io.Writer, plan *history.ImportPlan, dryRun bool) {
+	line := func(mark string, s history.Session, reason string) {
+		fmt.Fprintf(out, "%s %s  %s  %s  %s", mark, history.ShortID(s.ID),
+			s.StartedAt.Local().Format("2006-01-02 15:04"),
+			timer.FormatDuration(s.FocusDuration().Truncate(time.Second)),
+			labelWithMeta(s))
+		if reason != "" {
+			fmt.Fprintf(out, "  (%s)", reason)
+		}
+		fmt.Fprintln(out)
+	}
+	for _, s := range plan.Added {
+		line("+", s, "")
+	}
+	for _, skip := range plan.Duplicates {
+		line("=", skip.Session, skip.Reason)
+	}
+	for _, skip := range plan.Conflicts {
+		line("!", skip.Session, skip.Reason)
+	}
+	for _, skip := range plan.Invalid {
+		line("x", skip.Session, skip.Reason)
+	}
+
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(out, "%s %d session(s); skipped %d duplicate(s), %d conflict(s) and %d invalid\n",
+		verb, len(plan.Added), len(plan.Duplicates), len(plan.Conflicts), len(plan.Invalid))
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/timer"
+	"github.com/spf13/cobra"
+)
+
+// newLogCmd builds the "pomodux log" command
+func newLogCmd() *cobra.Command {
+	logCmd := &cobra.Command{
+		Use:   "log <duration|preset> [label]",
+		Short: "Record a session that was worked without running a timer",
+		Long: "Record a session after the fact. By default the session ends now; use --at to set its start " +
+			"or --from/--to to set both ends (the duration argument may then be omitted). " +
+			"Times accept HH:MM (today), YYYY-MM-DD HH:MM or RFC 3339. " +
+			"Logged sessions are stored with end status \"manual\" and must not overlap other sessions.",
+		Example: "  pomodux log 25m \"Code review\" --at 10:00\n" +
+			"  pomodux log \"Planning +web @meeting\" --from 14:00 --to 14:45",
+		Args: cobra.RangeArgs(0, 2),
+		RunE: logSession,
+	}
+	logCmd.Flags().String("at", "", "Start time of the session")
+	logCmd.Flags().String("from", "", "Start time of the session (requires --to)")
+	logCmd.Flags().String("to", "", "End time of the session (requires --from)")
+	logCmd.Flags().String("project", "", "Project the session is booked to (shorthand: +project in the label)")
+	logCmd.Flags().StringArray("tag", nil, "Tag the session (repeatable, shorthand: @tag in the label)")
+	logCmd.Flags().String("notes", "", "Notes on the session")
+	logCmd.Flags().Bool("private", false, "Keep the label, project, tags and notes out of logs, stats and exports")
+	logCmd.MarkFlagsRequiredTogether("from", "to")
+	logCmd.MarkFlagsMutuallyExclusive("at", "from")
+	return logCmd
+}
+
+func logSession(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+
+	session, err := newLoggedSession(cmd, cfg, args, time.Now())
+	if err != nil {
+		return err
+	}
+
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := editor.Add(session); err != nil {
+		return fmt.Errorf("failed to log session: %w", err)
+	}
+
+	logger.WithFields(map[string]interface{}{
+		"component":  "history",
+		"event":      "session_logged",
+		"session_id": session.ID,
+		"duration":   session.Duration,
+		"label":      session.Label,
+		"private":    session.Private,
+	}).Info("Session logged manually")
+
+	start, end := session.StartedAt, session.EndedAt
+	fmt.Fprintf(cmd.OutOrStdout(), "Logged %s session %s: %s (%s - %s)\n",
+		timer.FormatDuration(end.Sub(start)), history.ShortID(session.ID), labelWithMeta(session),
+		start.Local().Format("2006-01-02 15:04"), end.Local().Format("15:04"))
+	return nil
+}
+
+// newLoggedSession builds the session described by the log command's
+// arguments and flags, resolving times relative to now
+func newLoggedSession(cmd *cobra.Command, cfg *config.Config, args []string, now time.Time) (history.Session, error) {
+	at, _ := cmd.Flags().GetString("at")
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+
+	var start, end time.Time
+	var duration time.Duration
+	var preset, label string
+	var err error
+
+	if from != "" {
+		if start, err = history.ParseTime(from, now); err != nil {
+			return history.Session{}, err
+		}
+		if end, err = history.ParseTime(to, now); err != nil {
+			return history.Session{}, err
+		}
+		duration = end.Sub(start)
+
+		// The span gives the planned duration unless one is passed explicitly,
+		// so a lone argument may be the label
+		switch len(args) {
+		case 2:
+			if duration, preset, err = resolveDuration(cfg, args[0]); err != nil {
+				return history.Session{}, err
+			}
+			label = args[1]
+		case 1:
+			if d, p, err := resolveDuration(cfg, args[0]); err == nil {
+				duration, preset = d, p
+			} else {
+				label = args[0]
+			}
+		}
+	} else {
+		if len(args) == 0 {
+			return history.Session{}, fmt.Errorf("a duration or preset is required unless --from and --to are given")
+		}
+		if duration, preset, err = resolveDuration(cfg, args[0]); err != nil {
+			return history.Session{}, err
+		}
+		if len(args) > 1 {
+			label = args[1]
+		}
+
+		end = now
+		start = now.Add(-duration)
+		if at != "" {
+			if start, err = history.ParseTime(at, now); err != nil {
+				return history.Session{}, err
+			}
+			end = start.Add(duration)
+		}
+	}
+
+	if end.After(now) {
+		return history.Session{}, fmt.Errorf("session would end in the future (%s); use 'pomodux start' for upcoming work", end.Format("2006-01-02 15:04"))
+	}
+	if duration <= 0 || !end.After(start) {
+		return history.Session{}, fmt.Errorf("session must end after it starts")
+	}
+	if duration > 24*time.Hour {
+		return history.Session{}, fmt.Errorf("duration exceeds maximum (24h), got %v", duration)
+	}
+
+	label, project, tags, err := resolveLabel(cmd, label, preset)
+	if err != nil {
+		return history.Session{}, err
+	}
+
+	session := history.Session{
+		ID:             uuid.New().String(),
+		StartedAt:      start,
+		EndedAt:        end,
+		Duration:       timer.FormatDuration(duration),
+		Preset:         preset,
+		Label:          label,
+		Project:        project,
+		Tags:           tags,
+		EndStatus:      history.StatusManual,
+		PausedCount:    0,
+		PausedDuration: timer.FormatDuration(0),
+	}
+	session.Notes, _ = cmd.Flags().GetString("notes")
+	session.Private, _ = cmd.Flags().GetBool("private")
+	return session, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLoggedSession(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.Local)
+	at := func(hour, min int) time.Time {
+		return time.Date(2025, 1, 15, hour, min, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		flags        []string
+		wantStart    time.Time
+		wantEnd      time.Time
+		wantDuration string
+		wantPreset   string
+		wantLabel    string
+		wantErr      string
+	}{
+		{name: "ends now", args: []string{"25m", "Review"},
+			wantStart: at(11, 35), wantEnd: now, wantDuration: "25m", wantLabel: "Review"},
+		{name: "at", args: []string{"work"}, flags: []string{"--at", "10:00"},
+			wantStart: at(10, 0), wantEnd: at(10, 25), wantDuration: "25m", wantPreset: "work", wantLabel: "Work"},
+		{name: "from to with label only", args: []string{"Planning"}, flags: []string{"--from", "10:00", "--to", "10:45"},
+			wantStart: at(10, 0), wantEnd: at(10, 45), wantDuration: "45m", wantLabel: "Planning"},
+		{name: "from to with preset", args: []string{"work", "Planning"}, flags: []string{"--from", "10:00", "--to", "10:45"},
+			wantStart: at(10, 0), wantEnd: at(10, 45), wantDuration: "25m", wantPreset: "work", wantLabel: "Planning"},
+		{name: "from to without arguments", flags: []string{"--from", "2025-01-14 22:30", "--to", "10:00"},
+			wantStart: time.Date(2025, 1, 14, 22, 30, 0, 0, time.Local), wantEnd: at(10, 0), wantDuration: "11h30m", wantLabel: "Generic timer session"},
+		{name: "missing duration", wantErr: "a duration or preset is required"},
+		{name: "unknown preset", args: []string{"lunch"}, wantErr: "unknown preset"},
+		{name: "ends in the future", args: []string{"25m"}, flags: []string{"--at", "11:50"}, wantErr: "in the future"},
+		{name: "ends before it starts", flags: []string{"--from", "11:00", "--to", "10:00"}, wantErr: "must end after it starts"},
+		{name: "too long", args: []string{"25h"}, wantErr: "exceeds maximum"},
+		{name: "invalid time", args: []string{"25m"}, flags: []string{"--at", "noon"}, wantErr: "invalid time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newLogCmd()
+			require.NoError(t, cmd.ParseFlags(tt.flags))
+
+			session, err := newLoggedSession(cmd, config.DefaultConfig(), tt.args, now)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.wantStart.Equal(session.StartedAt), "start %v", session.StartedAt)
+			assert.True(t, tt.wantEnd.Equal(session.EndedAt), "end %v", session.EndedAt)
+			assert.Equal(t, tt.wantDuration, session.Duration)
+			assert.Equal(t, tt.wantPreset, session.Preset)
+			assert.Equal(t, tt.wantLabel, session.Label)
+			assert.Equal(t, history.StatusManual, session.EndStatus)
+			assert.NoError(t, session.Validate())
+		})
+	}
+}
+
+func TestLogSession(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir+"/config")
+	t.Setenv("XDG_STATE_HOME", dir+"/state")
+	t.Setenv("XDG_CACHE_HOME", dir+"/cache")
+
+	run := func(args ...string) error {
+		cmd := newLogCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		return cmd.Execute()
+	}
+
+	require.NoError(t, run("25m", "Doctor +health", "--at", "2025-01-15 10:00", "--notes", "Checkup", "--private"))
+	assert.ErrorContains(t, run("25m", "Overlapping", "--at", "2025-01-15 10:15"), "overlaps")
+	require.NoError(t, run("25m", "Adjacent", "--at", "2025-01-15 10:25"))
+
+	store, err := history.Open(history.BackendJSON, config.StatePath())
+	require.NoError(t, err)
+	defer store.Close()
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+
+	private := h.Sessions[0]
+	assert.Equal(t, "Doctor", private.Label)
+	assert.Equal(t, "health", private.Project)
+	assert.Equal(t, "Checkup", private.Notes)
+	assert.True(t, private.Private)
+	assert.False(t, h.Sessions[1].Private)
+}
@@ -10,8 +10,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/google/uuid"
 	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/encryption"
 	"github.com/pomodux/pomodux/internal/history"
 	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/stats"
 	"github.com/pomodux/pomodux/internal/theme"
 	"github.com/pomodux/pomodux/internal/timer"
 	"github.com/pomodux/pomodux/internal/tui"
@@ -35,12 +37,16 @@ func main() {
 	startCmd := &cobra.Command{
 		Use:   "start <duration|preset> [label]",
 		Short: "Start a timer session",
-		Long:  "Start a timer session with a duration (e.g., 25m, 1h30m) or preset name, with an optional label",
-		Args:  cobra.RangeArgs(1, 2),
-		RunE:  startTimer,
+		Long: "Start a timer session with a duration (e.g., 25m, 1h30m) or preset name, with an optional label.\n" +
+			"Words starting with + or @ in the label set the project and tags, e.g. \"Fix login +auth @bugfix\".",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: startTimer,
 	}
+	startCmd.Flags().String("project", "", "Project the session is booked to (shorthand: +project in the label)")
+	startCmd.Flags().StringArray("tag", nil, "Tag the session (repeatable, shorthand: @tag in the label)")
+	startCmd.Flags().Bool("private", false, "Keep the label, project and tags out of logs, stats and exports")
 
-	rootCmd.AddCommand(startCmd)
+	rootCmd.AddCommand(startCmd, newLogCmd(), newHistoryCmd(), newEncryptionCmd(), newConfigCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -57,12 +63,18 @@ func startTimer(cmd *cobra.Command, args []string) error {
 
 	// Initialize logger
 	if err := logger.Init(logger.Config{
-		Level: cfg.Logging.Level,
-		File:  cfg.Logging.File,
+		Level:  cfg.Logging.Level,
+		File:   cfg.Logging.File,
+		Labels: cfg.Privacy.LogLabels,
 	}); err != nil {
 		return fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
+	// Unlock an encrypted history before the TUI takes over the terminal
+	if _, err := encryption.UnlockDir(config.StatePath(), nil); err != nil {
+		return err
+	}
+
 	// Resolve theme from config (fallback to default for unknown names)
 	selectedTheme := theme.GetTheme(cfg.Theme)
 	if cfg.Theme != "default" && cfg.Theme != "nord" && cfg.Theme != "catppuccin-mocha" {
@@ -109,32 +121,14 @@ func startTimer(cmd *cobra.Command, args []string) error {
 			label = args[1]
 		}
 
-		var duration time.Duration
-		var preset string
-
-		// Try to parse as duration first
-		duration, err = time.ParseDuration(durationOrPreset)
+		duration, preset, err := resolveDuration(cfg, durationOrPreset)
 		if err != nil {
-			// Not a duration, try as preset
-			presetDuration, ok := cfg.Timers[durationOrPreset]
-			if !ok {
-				return fmt.Errorf("unknown preset %q\nAvailable presets: %v", durationOrPreset, getPresetNames(cfg.Timers))
-			}
-
-			duration, err = time.ParseDuration(presetDuration)
-			if err != nil {
-				return fmt.Errorf("invalid duration in preset %q: %w", durationOrPreset, err)
-			}
-			preset = durationOrPreset
+			return err
 		}
 
-		// Default label if not provided
-		if label == "" {
-			if preset != "" {
-				label = prettifyPresetName(preset)
-			} else {
-				label = "Generic timer session"
-			}
+		label, project, tags, err := resolveLabel(cmd, label, preset)
+		if err != nil {
+			return err
 		}
 
 		// Generate session ID
@@ -145,6 +139,10 @@ func startTimer(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to create timer: %w", err)
 		}
+		t.SetProject(project)
+		t.SetTags(tags)
+		private, _ := cmd.Flags().GetBool("private")
+		t.SetPrivate(private)
 
 		if err := t.Start(); err != nil {
 			return fmt.Errorf("failed to start timer: %w", err)
@@ -155,6 +153,9 @@ func startTimer(cmd *cobra.Command, args []string) error {
 			"duration":   duration,
 			"label":      label,
 			"preset":     preset,
+			"project":    project,
+			"tags":       tags,
+			"private":    private,
 		}).Info("Timer started")
 	}
 
@@ -177,9 +178,23 @@ func startTimer(cmd *cobra.Command, args []string) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
+	// Open history store for the configured backend
+	store, err := history.Open(cfg.History.Backend, config.StatePath())
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer store.Close()
+
 	// Start TUI with resolved theme
-	historyPath := config.HistoryPath()
-	model := tui.NewModel(t, sessionID, statePath, historyPath, selectedTheme)
+	model := tui.NewModel(t, sessionID, statePath, store, selectedTheme)
+	if goals := stats.GoalsFromConfig(cfg.Goals); goals.Enabled() {
+		report, err := stats.TrackGoals(store, goals, time.Now(), sessionID)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to load goal progress from history")
+		} else {
+			model = model.WithGoals(goals, report)
+		}
+	}
 	program := tea.NewProgram(model, tea.WithAltScreen())
 
 	// Handle signals in a goroutine (this is the exception - signal handling)
@@ -193,26 +208,9 @@ func startTimer(cmd *cobra.Command, args []string) error {
 		}
 
 		// Append session with end_status "interrupted" to history
-		endedAt := time.Now()
-		interruptedSession := history.Session{
-			ID:             sessionID,
-			StartedAt:      t.StartTime(),
-			EndedAt:        endedAt,
-			Duration:       timer.FormatDuration(t.Duration()),
-			Preset:         t.Preset(),
-			Label:          t.Label(),
-			EndStatus:      "interrupted",
-			PausedCount:    t.PausedCount(),
-			PausedDuration: timer.FormatDuration(t.TotalPausedDuration()),
-		}
-		h, err := history.Load(historyPath)
-		if err != nil {
-			logger.WithError(err).Error("Failed to load history on interrupt")
-		} else {
-			h.AddSession(interruptedSession)
-			if err := history.Save(h, historyPath); err != nil {
-				logger.WithError(err).Error("Failed to save interrupted session to history")
-			}
+		interruptedSession := tui.HistorySession(t, sessionID, "interrupted", time.Now())
+		if err := store.Append(interruptedSession); err != nil {
+			logger.WithError(err).Error("Failed to save interrupted session to history")
 		}
 
 		program.Quit()
@@ -231,6 +229,69 @@ func startTimer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resolveDuration interprets a duration (e.g., 25m) or preset name.
+// The returned preset is empty for plain durations.
+func resolveDuration(cfg *config.Config, durationOrPreset string) (time.Duration, string, error) {
+	// Try to parse as duration first
+	duration, err := time.ParseDuration(durationOrPreset)
+	if err == nil {
+		return duration, "", nil
+	}
+
+	// Not a duration, try as preset
+	presetDuration, ok := cfg.Timers[durationOrPreset]
+	if !ok {
+		return 0, "", fmt.Errorf("unknown preset %q\nAvailable presets: %v", durationOrPreset, getPresetNames(cfg.Timers))
+	}
+
+	duration, err = time.ParseDuration(presetDuration)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid duration in preset %q: %w", durationOrPreset, err)
+	}
+	return duration, durationOrPreset, nil
+}
+
+// resolveLabel extracts +project and @tag shorthand from the label, merges the
+// --project and --tag flags and applies the default label for the preset
+func resolveLabel(cmd *cobra.Command, input string, preset string) (string, string, []string, error) {
+	label, project, tags, err := timer.ParseLabel(input)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	flagProject, _ := cmd.Flags().GetString("project")
+	if flagProject != "" {
+		if project != "" && project != flagProject {
+			return "", "", nil, fmt.Errorf("conflicting projects: --project %s and +%s in label", flagProject, project)
+		}
+		project = flagProject
+	}
+	flagTags, _ := cmd.Flags().GetStringArray("tag")
+	tags = timer.MergeTags(tags, flagTags)
+
+	if project != "" {
+		if err := timer.ValidateName("project", project); err != nil {
+			return "", "", nil, err
+		}
+	}
+	for _, tag := range tags {
+		if err := timer.ValidateName("tag", tag); err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	// Default label if not provided
+	if label == "" {
+		if preset != "" {
+			label = prettifyPresetName(preset)
+		} else {
+			label = "Generic timer session"
+		}
+	}
+
+	return label, project, tags, nil
+}
+
 // stateExists checks if the state file exists
 func stateExists(path string) bool {
 	_, err := os.Stat(path)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDuration(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Timers["broken"] = "soon"
+
+	duration, preset, err := resolveDuration(cfg, "50m")
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Minute, duration)
+	assert.Empty(t, preset)
+
+	duration, preset, err = resolveDuration(cfg, "work")
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Minute, duration)
+	assert.Equal(t, "work", preset)
+
+	_, _, err = resolveDuration(cfg, "lunch")
+	assert.ErrorContains(t, err, `unknown preset "lunch"`)
+
+	_, _, err = resolveDuration(cfg, "broken")
+	assert.ErrorContains(t, err, `invalid duration in preset "broken"`)
+}
+
+func TestResolveLabel(t *testing.T) {
+	tests := []struct {
+		name        string
+		flags       []string
+		input       string
+		preset      string
+		wantLabel   string
+		wantProject string
+		wantTags    []string
+		wantErr     string
+	}{
+		{name: "shorthand", input: "Fix login +web @bug", wantLabel: "Fix login", wantProject: "web", wantTags: []string{"bug"}},
+		{name: "flags merge with shorthand", flags: []string{"--project", "web", "--tag", "review"}, input: "Fix login +web @bug",
+			wantLabel: "Fix login", wantProject: "web", wantTags: []string{"bug", "review"}},
+		{name: "conflicting projects", flags: []string{"--project", "api"}, input: "Fix login +web", wantErr: "conflicting projects"},
+		{name: "invalid tag", flags: []string{"--tag", "two words"}, input: "Fix login", wantErr: "tag"},
+		{name: "preset default", preset: "work", wantLabel: "Work"},
+		{name: "generic default", wantLabel: "Generic timer session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newLogCmd()
+			require.NoError(t, cmd.ParseFlags(tt.flags))
+
+			label, project, tags, err := resolveLabel(cmd, tt.input, tt.preset)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLabel, label)
+			assert.Equal(t, tt.wantProject, project)
+			assert.Equal(t, tt.wantTags, tags)
+		})
+	}
+}
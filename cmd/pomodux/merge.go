@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/timer"
+	"github.com/spf13/cobra"
+)
+
+// newMergeCmd builds the "pomodux history merge" command
+func newMergeCmd() *cobra.Command {
+	mergeCmd := &cobra.Command{
+		Use:   "merge <file>...",
+		Short: "Merge other copies of the history, such as sync conflict copies",
+		Long: "Merge copies of the history from other machines into this one by session ID. " +
+			"Copies may be history.json, history.jsonl or history.db files. " +
+			"When copies disagree about a session the most recently edited version wins, then the most edited one, " +
+			"so every machine merging the same copies ends up with the same history. " +
+			"Sessions deleted on one machine but present in a copy are kept; delete them again after merging.",
+		Example: "  pomodux history merge \"history (conflicted copy).json\" --dry-run\n" +
+			"  pomodux history merge ~/.local/state/pomodux/history.sync-conflict-*.json --remove",
+		Args: cobra.MinimumNArgs(1),
+		RunE: mergeHistories,
+	}
+	mergeCmd.Flags().Bool("dry-run", false, "Show what would change without changing the history")
+	mergeCmd.Flags().Bool("remove", false, "Delete the merged copies afterwards")
+	mergeCmd.MarkFlagsMutuallyExclusive("dry-run", "remove")
+	return mergeCmd
+}
+
+func mergeHistories(cmd *cobra.Command, args []string) error {
+	cfg, err := initCommand()
+	if err != nil {
+		return err
+	}
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	remove, _ := cmd.Flags().GetBool("remove")
+
+	var copies []*history.History
+	for _, path := range args {
+		h, err := history.LoadCopy(path)
+		if err != nil {
+			return err
+		}
+		copies = append(copies, h)
+	}
+
+	editor, store, err := openEditor(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	result, err := editor.Merge(copies, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to merge histories: %w", err)
+	}
+
+	if !dryRun {
+		logger.WithFields(map[string]interface{}{
+			"component": "history",
+			"event":     "histories_merged",
+			"copies":    len(copies),
+			"added":     len(result.Added),
+			"updated":   len(result.Updated),
+		}).Info("Histories merged")
+	}
+
+	printMergeResult(cmd.OutOrStdout(), result, dryRun)
+
+	if remove {
+		for _, path := range args {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove merged copy: %w", err)
+			}
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %d merged copy file(s)\n", len(args))
+	}
+	return nil
+}
+
+// printMergeResult writes added sessions (+), updated sessions (~) with
+// their changes, sessions already archived (=) and overlap warnings (!),
+// followed by a summary
+func printMergeResult(out io.Writer, result *history.MergeResult, dryRun bool) {
+	line := func(mark string, s history.Session) {
+		fmt.Fprintf(out, "%s %s  %s  %s  %s\n", mark, history.ShortID(s.ID),
+			s.StartedAt.Local().Format("2006-01-02 15:04"),
+			timer.FormatDuration(s.FocusDuration().Truncate(time.Second)),
+			labelWithMeta(s))
+	}
+	for _, s := range result.Added {
+		line("+", s)
+	}
+	for _, u := range result.Updated {
+		last := u.After[len(u.After)-1]
+		line("~", last)
+		if len(u.Before) == 1 && len(u.After) == 1 {
+			printChanges(out, u.Before[0], last)
+		} else {
+			fmt.Fprintf(out, "  records: %d -> %d\n", len(u.Before), len(u.After))
+		}
+	}
+	for _, s := range result.Archived {
+		line("=", s)
+	}
+	for _, overlap := range result.Overlaps {
+		fmt.Fprintf(out, "! %s\n", overlap)
+	}
+
+	verb := "Merged"
+	if dryRun {
+		verb = "Would merge"
+	}
+	fmt.Fprintf(out, "%s: %d session record(s) added, %d session(s) updated, %d record(s) already archived\n",
+		verb, len(result.Added), len(result.Updated), len(result.Archived))
+}
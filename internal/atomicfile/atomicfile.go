@@ -0,0 +1,95 @@
+// Package atomicfile provides crash-safe file replacement.
+//
+// Files are written to a uniquely named temporary file in the same directory,
+// flushed to stable storage, renamed over the destination and the parent
+// directory is synced so the rename itself survives a power loss.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Hooks for the individual steps of WriteFile. Tests replace them to inject
+// failures (or simulated crashes) between steps.
+var (
+	writeFile = func(f *os.File, data []byte) error {
+		_, err := f.Write(data)
+		return err
+	}
+	syncFile = func(f *os.File) error { return f.Sync() }
+	rename   = os.Rename
+	syncDir  = syncDirectory
+)
+
+// WriteFile atomically replaces the file at path with data.
+// If the file already exists its permissions are preserved, otherwise perm is used.
+// The parent directory must already exist.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	// Unique temp name so concurrent writers never clobber each other's temp file
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temp file on any failure below
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", tmpPath, err)
+	}
+
+	if err := writeFile(tmp, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+
+	if err := syncFile(tmp); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", tmpPath, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+
+	if err := rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", tmpPath, path, err)
+	}
+	committed = true
+
+	if err := syncDir(dir); err != nil {
+		return fmt.Errorf("failed to sync directory %s: %w", dir, err)
+	}
+
+	return nil
+}
+
+// syncDirectory fsyncs a directory so that a preceding rename is durable
+func syncDirectory(dir string) error {
+	// Directories cannot be opened for syncing on Windows
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,211 @@
+package atomicfile
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crashEnv selects the step at which the helper process exits without cleanup
+const crashEnv = "ATOMICFILE_CRASH_AT"
+
+func TestWriteFile_NewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	err := WriteFile(path, []byte("hello"), 0600)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestWriteFile_ReplacesAndPreservesPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not meaningful on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+	require.NoError(t, os.Chmod(path, 0644))
+
+	err := WriteFile(path, []byte("new"), 0600)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestWriteFile_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "data.json")
+
+	err := WriteFile(path, []byte("x"), 0600)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create temp file")
+}
+
+func TestWriteFile_InjectedFailures(t *testing.T) {
+	injected := errors.New("injected failure")
+
+	tests := []struct {
+		name    string
+		install func()
+		wantErr string
+	}{
+		{
+			name: "write fails halfway",
+			install: func() {
+				writeFile = func(f *os.File, data []byte) error {
+					f.Write(data[:len(data)/2])
+					return injected
+				}
+			},
+			wantErr: "failed to write",
+		},
+		{
+			name:    "file sync fails",
+			install: func() { syncFile = func(*os.File) error { return injected } },
+			wantErr: "failed to sync",
+		},
+		{
+			name:    "rename fails",
+			install: func() { rename = func(string, string) error { return injected } },
+			wantErr: "failed to rename",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restoreHooks(t)
+			dir := t.TempDir()
+			path := filepath.Join(dir, "history.json")
+			require.NoError(t, os.WriteFile(path, []byte("original"), 0600))
+
+			tt.install()
+			err := WriteFile(path, []byte("replacement content"), 0600)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, injected)
+			assert.Contains(t, err.Error(), tt.wantErr)
+
+			// Original must be untouched and the temp file cleaned up
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "original", string(data))
+			assertNoTempFiles(t, dir)
+		})
+	}
+}
+
+func TestWriteFile_DirectorySyncFailureAfterRename(t *testing.T) {
+	restoreHooks(t)
+	injected := errors.New("injected failure")
+	syncDir = func(string) error { return injected }
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+
+	err := WriteFile(path, []byte("new"), 0600)
+	require.ErrorIs(t, err, injected)
+
+	// Rename already happened, so the new content is visible
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFile_CrashInjection(t *testing.T) {
+	if os.Getenv(crashEnv) != "" {
+		runCrashHelper()
+		return
+	}
+
+	for _, step := range []string{"write", "sync", "rename"} {
+		t.Run(step, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "history.json")
+			require.NoError(t, os.WriteFile(path, []byte("original"), 0600))
+
+			cmd := exec.Command(os.Args[0], "-test.run=^TestWriteFile_CrashInjection$")
+			cmd.Env = append(os.Environ(), crashEnv+"="+step, "ATOMICFILE_CRASH_PATH="+path)
+			err := cmd.Run()
+			var exitErr *exec.ExitError
+			require.ErrorAs(t, err, &exitErr, "helper process should have crashed")
+			assert.Equal(t, 3, exitErr.ExitCode())
+
+			// The destination holds the complete old content, never a partial write
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "original", string(data))
+
+			// A write after the crash succeeds despite leftover temp files
+			require.NoError(t, WriteFile(path, []byte("recovered"), 0600))
+			data, err = os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "recovered", string(data))
+		})
+	}
+}
+
+// runCrashHelper performs a write that exits the process abruptly at the
+// configured step, skipping all deferred cleanup like a real crash would
+func runCrashHelper() {
+	crash := func() { os.Exit(3) }
+
+	switch os.Getenv(crashEnv) {
+	case "write":
+		writeFile = func(f *os.File, data []byte) error {
+			f.Write(data[:len(data)/2])
+			crash()
+			return nil
+		}
+	case "sync":
+		syncFile = func(*os.File) error {
+			crash()
+			return nil
+		}
+	case "rename":
+		rename = func(string, string) error {
+			crash()
+			return nil
+		}
+	}
+
+	WriteFile(os.Getenv("ATOMICFILE_CRASH_PATH"), []byte("replacement content"), 0600)
+	os.Exit(0)
+}
+
+// restoreHooks resets the step hooks when the test finishes
+func restoreHooks(t *testing.T) {
+	origWrite, origSyncFile, origRename, origSyncDir := writeFile, syncFile, rename, syncDir
+	t.Cleanup(func() {
+		writeFile, syncFile, rename, syncDir = origWrite, origSyncFile, origRename, origSyncDir
+	})
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.False(t, strings.Contains(e.Name(), ".tmp-"), "leftover temp file %s", e.Name())
+	}
+}
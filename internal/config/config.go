@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/pomodux/pomodux/internal/atomicfile"
 	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/privacy"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,6 +19,10 @@ type Config struct {
 	Timers  map[string]string `yaml:"timers"`
 	Theme   string            `yaml:"theme"`
 	Timer   TimerConfig       `yaml:"timer"`
+	History HistoryConfig     `yaml:"history"`
+	Stats   StatsConfig       `yaml:"stats"`
+	Goals   GoalsConfig       `yaml:"goals"`
+	Privacy PrivacyConfig     `yaml:"privacy"`
 	Logging LoggingConfig     `yaml:"logging"`
 	Plugins PluginsConfig     `yaml:"plugins"`
 }
@@ -24,6 +32,76 @@ type TimerConfig struct {
 	BellOnComplete bool `yaml:"bell_on_complete"`
 }
 
+// HistoryConfig represents session history storage configuration
+type HistoryConfig struct {
+	Backend   string          `yaml:"backend"` // json, jsonl or sqlite
+	Retention RetentionConfig `yaml:"retention"`
+}
+
+// RetentionConfig controls how "pomodux history compact" archives old sessions
+type RetentionConfig struct {
+	KeepMonths int  `yaml:"keep_months"` // Months of sessions kept in the history, 0 keeps all
+	RollupOnly bool `yaml:"rollup_only"` // Archive daily rollups without the sessions themselves
+}
+
+// StatsConfig represents statistics configuration
+type StatsConfig struct {
+	WeekStart string `yaml:"week_start"` // Weekday weeks start on, e.g. monday
+}
+
+// weekdayNames lists the weekdays values in week order, for messages
+const weekdayNames = "sunday|monday|tuesday|wednesday|thursday|friday|saturday"
+
+// weekdays maps week_start values to weekdays
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// WeekStartDay returns the configured first day of the week, Monday if unset
+func (c StatsConfig) WeekStartDay() time.Weekday {
+	if day, ok := weekdays[c.WeekStart]; ok {
+		return day
+	}
+	return time.Monday
+}
+
+// GoalsConfig represents daily focus goals
+type GoalsConfig struct {
+	Daily        GoalConfig            `yaml:"daily"`
+	Weekdays     map[string]GoalConfig `yaml:"weekdays"`      // Per-weekday overrides, e.g. saturday
+	BreakPresets []string              `yaml:"break_presets"` // Presets that never count toward goals
+}
+
+// GoalConfig is one day's goal. Zero values set no target; a day with
+// neither target has no goal and does not affect streaks.
+type GoalConfig struct {
+	Minutes  int `yaml:"minutes"`  // Focus minutes
+	Sessions int `yaml:"sessions"` // Completed work sessions
+}
+
+// ForWeekday returns the goal for day, honoring weekday overrides
+func (c GoalsConfig) ForWeekday(day time.Weekday) GoalConfig {
+	for name, goal := range c.Weekdays {
+		if weekdays[name] == day {
+			return goal
+		}
+	}
+	return c.Daily
+}
+
+// PrivacyConfig controls how session labels appear outside the history.
+// Labels of sessions marked private never appear there.
+type PrivacyConfig struct {
+	LogLabels    string `yaml:"log_labels"`    // plain, hash or redact in log fields
+	ExportLabels string `yaml:"export_labels"` // plain, hash or redact in pomodux-stats export
+}
+
 // LoggingConfig represents logging configuration
 type LoggingConfig struct {
 	Level string `yaml:"level"`
@@ -100,18 +178,13 @@ func SaveToPath(config *Config, path string) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Marshal to YAML with proper indentation
-	data, err := yaml.Marshal(config)
+	data, err := marshalConfig(config)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 
-	// Add a helpful comment at the top
-	header := "# Pomodux Configuration File\n# Edit this file to customize your timer settings\n\n"
-	data = append([]byte(header), data...)
-
-	// Write file with proper permissions
-	if err := os.WriteFile(path, data, 0600); err != nil {
+	// Write file atomically with proper permissions
+	if err := atomicfile.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -119,6 +192,19 @@ func SaveToPath(config *Config, path string) error {
 	return nil
 }
 
+// marshalConfig renders config as a config file
+func marshalConfig(config *Config) ([]byte, error) {
+	// Marshal to YAML with proper indentation
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Add a helpful comment at the top
+	header := "# Pomodux Configuration File\n# Edit this file to customize your timer settings\n\n"
+	return append([]byte(header), data...), nil
+}
+
 // ConfigPath returns the XDG-compliant config file path
 func ConfigPath() string {
 	configHome := os.Getenv("XDG_CONFIG_HOME")
@@ -166,6 +252,31 @@ func LogFilePath() string {
 
 // validateAndApplyDefaults validates the config and applies defaults for missing fields
 func validateAndApplyDefaults(config *Config) error {
+	for _, problem := range repair(config) {
+		logger.Warn(problem.warning())
+	}
+	return nil
+}
+
+// problem describes an invalid field and what loading does about it
+type problem struct {
+	message string // What is wrong, such as `invalid log level "x" (want ...)`
+	action  string // How repair resets it, such as "defaulting to info"
+}
+
+// warning is the message loading logs for the problem
+func (p problem) warning() string {
+	return strings.ToUpper(p.message[:1]) + p.message[1:] + ", " + p.action
+}
+
+// repair applies defaults for missing fields and resets invalid ones,
+// returning a problem for each invalid field
+func repair(config *Config) []problem {
+	var problems []problem
+	warnf := func(action, format string, args ...interface{}) {
+		problems = append(problems, problem{message: fmt.Sprintf(format, args...), action: action})
+	}
+
 	// Apply defaults for missing fields
 	applyDefaults(config)
 
@@ -174,6 +285,51 @@ func validateAndApplyDefaults(config *Config) error {
 		config.Theme = "default"
 	}
 
+	// Validate history backend
+	validBackends := map[string]bool{
+		"json":   true,
+		"jsonl":  true,
+		"sqlite": true,
+	}
+	if !validBackends[config.History.Backend] {
+		warnf("defaulting to json", "invalid history backend %q (want json|jsonl|sqlite)", config.History.Backend)
+		config.History.Backend = "json"
+	}
+
+	// Validate retention
+	if config.History.Retention.KeepMonths < 0 {
+		warnf("keeping all sessions", "invalid history retention keep_months %d (want 0 or more)", config.History.Retention.KeepMonths)
+		config.History.Retention.KeepMonths = 0
+	}
+
+	// Validate week start
+	if _, ok := weekdays[config.Stats.WeekStart]; !ok {
+		warnf("defaulting to monday", "invalid stats week_start %q (want %s)", config.Stats.WeekStart, weekdayNames)
+		config.Stats.WeekStart = "monday"
+	}
+
+	// Validate goals
+	validateGoal("daily", &config.Goals.Daily, warnf)
+	for name, goal := range config.Goals.Weekdays {
+		if _, ok := weekdays[name]; !ok {
+			warnf("ignoring it", "invalid goals weekday %q (want %s)", name, weekdayNames)
+			delete(config.Goals.Weekdays, name)
+			continue
+		}
+		validateGoal(name, &goal, warnf)
+		config.Goals.Weekdays[name] = goal
+	}
+
+	// Validate privacy modes
+	if _, err := privacy.ParseMode(config.Privacy.LogLabels); err != nil {
+		warnf("redacting labels", "invalid privacy log_labels %q (want %s)", config.Privacy.LogLabels, strings.Join(privacy.Modes, "|"))
+		config.Privacy.LogLabels = privacy.ModeRedact
+	}
+	if _, err := privacy.ParseMode(config.Privacy.ExportLabels); err != nil {
+		warnf("redacting labels", "invalid privacy export_labels %q (want %s)", config.Privacy.ExportLabels, strings.Join(privacy.Modes, "|"))
+		config.Privacy.ExportLabels = privacy.ModeRedact
+	}
+
 	// Validate logging level
 	validLevels := map[string]bool{
 		"debug": true,
@@ -182,11 +338,23 @@ func validateAndApplyDefaults(config *Config) error {
 		"error": true,
 	}
 	if !validLevels[config.Logging.Level] {
-		logger.Warnf("Invalid log level %q, defaulting to info", config.Logging.Level)
+		warnf("defaulting to info", "invalid log level %q (want debug|info|warn|error)", config.Logging.Level)
 		config.Logging.Level = "info"
 	}
 
-	return nil
+	return problems
+}
+
+// validateGoal resets negative targets to 0 (no target)
+func validateGoal(name string, goal *GoalConfig, warnf func(string, string, ...interface{})) {
+	if goal.Minutes < 0 {
+		warnf("ignoring it", "invalid %s goal minutes %d (want 0 or more)", name, goal.Minutes)
+		goal.Minutes = 0
+	}
+	if goal.Sessions < 0 {
+		warnf("ignoring it", "invalid %s goal sessions %d (want 0 or more)", name, goal.Sessions)
+		goal.Sessions = 0
+	}
 }
 
 // applyDefaults applies default values to missing config fields
@@ -205,6 +373,30 @@ func applyDefaults(config *Config) {
 		config.Theme = defaults.Theme
 	}
 
+	if config.History.Backend == "" {
+		config.History.Backend = defaults.History.Backend
+	}
+
+	if config.Stats.WeekStart == "" {
+		config.Stats.WeekStart = defaults.Stats.WeekStart
+	}
+
+	if config.Goals.Weekdays == nil {
+		config.Goals.Weekdays = defaults.Goals.Weekdays
+	}
+
+	if config.Goals.BreakPresets == nil {
+		config.Goals.BreakPresets = defaults.Goals.BreakPresets
+	}
+
+	if config.Privacy.LogLabels == "" {
+		config.Privacy.LogLabels = defaults.Privacy.LogLabels
+	}
+
+	if config.Privacy.ExportLabels == "" {
+		config.Privacy.ExportLabels = defaults.Privacy.ExportLabels
+	}
+
 	if config.Logging.Level == "" {
 		config.Logging.Level = defaults.Logging.Level
 	}
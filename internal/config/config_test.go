@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,6 +19,8 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, "15m", config.Timers["long_break"])
 	assert.Equal(t, "default", config.Theme)
 	assert.False(t, config.Timer.BellOnComplete)
+	assert.Equal(t, "json", config.History.Backend)
+	assert.Equal(t, "monday", config.Stats.WeekStart)
 	assert.Equal(t, "info", config.Logging.Level)
 	assert.Empty(t, config.Logging.File)
 }
@@ -61,6 +64,119 @@ logging:
 	assert.Equal(t, "debug", config.Logging.Level)
 }
 
+func TestLoadFromPath_HistoryBackend(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  string
+		expected string
+	}{
+		{"json", "json", "json"},
+		{"jsonl", "jsonl", "jsonl"},
+		{"sqlite", "sqlite", "sqlite"},
+		{"missing defaults to json", "", "json"},
+		{"invalid falls back to json", "xml", "json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			yamlContent := "version: \"1.0\"\nhistory:\n  backend: \"" + tt.backend + "\"\n"
+			require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0600))
+
+			config, err := LoadFromPath(configPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config.History.Backend)
+		})
+	}
+}
+
+func TestLoadFromPath_HistoryRetention(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := "version: \"1.0\"\nhistory:\n  retention:\n    keep_months: 12\n    rollup_only: true\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0600))
+
+	config, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 12, config.History.Retention.KeepMonths)
+	assert.True(t, config.History.Retention.RollupOnly)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("version: \"1.0\"\nhistory:\n  retention:\n    keep_months: -3\n"), 0600))
+	config, err = LoadFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.History.Retention.KeepMonths, "negative retention keeps all sessions")
+}
+
+func TestLoadFromPath_Privacy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("version: \"1.0\"\n"), 0600))
+	config, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", config.Privacy.LogLabels)
+	assert.Equal(t, "plain", config.Privacy.ExportLabels)
+
+	yamlContent := "version: \"1.0\"\nprivacy:\n  log_labels: hash\n  export_labels: scramble\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0600))
+	config, err = LoadFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "hash", config.Privacy.LogLabels)
+	assert.Equal(t, "redact", config.Privacy.ExportLabels, "invalid modes fail safe")
+}
+
+func TestLoadFromPath_StatsWeekStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		weekStart string
+		expected  string
+		day       time.Weekday
+	}{
+		{"sunday", "sunday", "sunday", time.Sunday},
+		{"missing defaults to monday", "", "monday", time.Monday},
+		{"invalid falls back to monday", "someday", "monday", time.Monday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			yamlContent := "version: \"1.0\"\nstats:\n  week_start: \"" + tt.weekStart + "\"\n"
+			require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0600))
+
+			config, err := LoadFromPath(configPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config.Stats.WeekStart)
+			assert.Equal(t, tt.day, config.Stats.WeekStartDay())
+		})
+	}
+}
+
+func TestLoadFromPath_Goals(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := `version: "1.0"
+goals:
+  daily:
+    minutes: 120
+  weekdays:
+    saturday:
+      sessions: 2
+    sunday: {}
+    someday:
+      minutes: 10
+    friday:
+      minutes: -5
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0600))
+
+	config, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, GoalConfig{Minutes: 120}, config.Goals.ForWeekday(time.Monday))
+	assert.Equal(t, GoalConfig{Sessions: 2}, config.Goals.ForWeekday(time.Saturday))
+	assert.Equal(t, GoalConfig{}, config.Goals.ForWeekday(time.Sunday))
+	// Negative targets are dropped, unknown weekdays ignored
+	assert.Equal(t, GoalConfig{}, config.Goals.ForWeekday(time.Friday))
+	assert.NotContains(t, config.Goals.Weekdays, "someday")
+	assert.Equal(t, []string{"break", "long_break"}, config.Goals.BreakPresets)
+}
+
 func TestSaveToPath(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, "config.yaml")
@@ -13,6 +13,20 @@ func DefaultConfig() *Config {
 		Timer: TimerConfig{
 			BellOnComplete: false,
 		},
+		History: HistoryConfig{
+			Backend: "json",
+		},
+		Stats: StatsConfig{
+			WeekStart: "monday",
+		},
+		Goals: GoalsConfig{
+			Weekdays:     map[string]GoalConfig{},
+			BreakPresets: []string{"break", "long_break"},
+		},
+		Privacy: PrivacyConfig{
+			LogLabels:    "plain",
+			ExportLabels: "plain",
+		},
 		Logging: LoggingConfig{
 			Level: "info",
 			File:  "",
@@ -0,0 +1,445 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultIndent is the indentation yaml.Marshal writes config files with
+const defaultIndent = 4
+
+// Document is a config file kept as a YAML node tree, so keys can be set and
+// unset without losing the file's comments, key order or layout
+type Document struct {
+	root     *yaml.Node
+	indent   int
+	source   []byte   // The file as loaded, whose layout Bytes restores
+	problems []string // Problems the file had when loaded
+}
+
+// LoadDocument reads the config file at path for editing. A missing file
+// starts from the default configuration.
+func LoadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if data, err = marshalConfig(DefaultConfig()); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return ParseDocument(data)
+}
+
+// ParseDocument parses config file contents for editing
+func ParseDocument(data []byte) (*Document, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config must be a mapping of keys to values")
+	}
+
+	_, problems, _ := Validate(data)
+	return &Document{root: &root, indent: detectIndent(data), source: data, problems: problems}, nil
+}
+
+// Bytes returns the document as YAML. Lines that did not change keep their
+// spacing from the loaded file, and so do the blank lines before them.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(d.indent)
+	if err := enc.Encode(d.root); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return restoreLayout(d.source, buf.Bytes()), nil
+}
+
+// Set sets key, a dotted path such as timer.bell_on_complete, to value
+// parsed as the key's type. Lists take YAML flow syntax ([a, b]) or comma
+// separated values.
+func (d *Document) Set(key, value string) error {
+	t, err := keyType(key)
+	if err != nil {
+		return err
+	}
+	node, err := valueNode(key, t, value)
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(key, ".")
+	m := d.root.Content[0]
+	for _, part := range parts[:len(parts)-1] {
+		child := lookupNode(m, part)
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+		} else if child.Kind != yaml.MappingNode {
+			// An empty section (key: or key: {}) becomes a block mapping
+			*child = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: child.HeadComment, LineComment: child.LineComment, FootComment: child.FootComment}
+		}
+		m = child
+	}
+
+	last := parts[len(parts)-1]
+	if existing := lookupNode(m, last); existing != nil {
+		node.HeadComment, node.LineComment, node.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+		*existing = *node
+		return nil
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}, node)
+	return nil
+}
+
+// Unset removes key from the document so its default applies again, and
+// reports whether it was set. Sections left empty are removed too. Unknown
+// keys can be unset.
+func (d *Document) Unset(key string) bool {
+	parts := strings.Split(key, ".")
+	// sections[i] is the mapping holding parts[i]
+	sections := []*yaml.Node{d.root.Content[0]}
+	for _, part := range parts[:len(parts)-1] {
+		m := lookupNode(sections[len(sections)-1], part)
+		if m == nil || m.Kind != yaml.MappingNode {
+			return false
+		}
+		sections = append(sections, m)
+	}
+	if !removeKey(sections[len(sections)-1], parts[len(parts)-1]) {
+		return false
+	}
+	for i := len(sections) - 1; i > 0 && len(sections[i].Content) == 0; i-- {
+		removeKey(sections[i-1], parts[i-1])
+	}
+	return true
+}
+
+// removeKey removes key and its value from mapping m, reporting whether it
+// was there
+func removeKey(m *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save validates the document and atomically writes it to path. Problems
+// the file already had when loaded do not stop the save; new ones do.
+func (d *Document) Save(path string) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	_, problems, err := Validate(data)
+	if err != nil {
+		return err
+	}
+	if added := NewProblems(d.problems, problems); len(added) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(added, "; "))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Validate parses config file contents strictly, rejecting unknown keys and
+// values of the wrong type, and returns the effective configuration with the
+// invalid values that loading it would reset
+func Validate(data []byte) (*Config, []string, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var config Config
+	if err := dec.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	var problems []string
+	for _, problem := range repair(&config) {
+		problems = append(problems, problem.message)
+	}
+	names := make([]string, 0, len(config.Timers))
+	for name := range config.Timers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := time.ParseDuration(config.Timers[name]); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid duration %q for timer %q", config.Timers[name], name))
+		}
+	}
+	return &config, problems, nil
+}
+
+// NewProblems returns the problems in after that are not in before
+func NewProblems(before, after []string) []string {
+	seen := map[string]bool{}
+	for _, p := range before {
+		seen[p] = true
+	}
+	var added []string
+	for _, p := range after {
+		if !seen[p] {
+			added = append(added, p)
+		}
+	}
+	return added
+}
+
+// Lookup returns the value of key in config as YAML: scalars as their bare
+// value, sections as a YAML document
+func Lookup(config *Config, key string) (string, error) {
+	if _, err := keyType(key); err != nil {
+		return "", err
+	}
+	var node yaml.Node
+	if err := node.Encode(config); err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	current := &node
+	for _, part := range strings.Split(key, ".") {
+		if current = lookupNode(current, part); current == nil {
+			return "", fmt.Errorf("%s is not set", key)
+		}
+	}
+	if current.Kind == yaml.ScalarNode {
+		return current.Value, nil
+	}
+	data, err := yaml.Marshal(current)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// keyType returns the Go type of the config value at key. Keys below a map,
+// such as timers.focus, may take any name.
+func keyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	for _, part := range strings.Split(key, ".") {
+		if part == "" {
+			return nil, fmt.Errorf("invalid config key %q", key)
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := yamlField(t, part)
+			if !ok {
+				return nil, fmt.Errorf("unknown config key %q", key)
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("unknown config key %q", key)
+		}
+	}
+	return t, nil
+}
+
+// yamlField finds the struct field with the given YAML key
+func yamlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// valueNode parses value as a YAML node of type t
+func valueNode(key string, t reflect.Type, value string) (*yaml.Node, error) {
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(b)}, nil
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a whole number, got %q", key, value)
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(n)}, nil
+	case reflect.String:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}, nil
+	case reflect.Slice:
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if strings.HasPrefix(strings.TrimSpace(value), "[") {
+			var parsed yaml.Node
+			if err := yaml.Unmarshal([]byte(value), &parsed); err != nil || parsed.Content[0].Kind != yaml.SequenceNode {
+				return nil, fmt.Errorf("%s must be a list such as [a, b], got %q", key, value)
+			}
+			seq = parsed.Content[0]
+		} else {
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item})
+				}
+			}
+		}
+		for _, item := range seq.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("%s must be a list of values, got %q", key, value)
+			}
+		}
+		return seq, nil
+	default:
+		return nil, fmt.Errorf("%s is a section; set one of its keys instead", key)
+	}
+}
+
+// lookupNode returns the value of key in a mapping node, or in the mapping
+// of a document node
+func lookupNode(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// commentGap matches the spacing before an inline comment
+var commentGap = regexp.MustCompile(`[ \t]+#`)
+
+// restoreLayout puts back the layout of source that re-encoding it as data
+// lost: lines matching a source line up to spacing are taken from source,
+// with the blank lines that preceded them. data is returned unchanged if the
+// result would not mean the same.
+func restoreLayout(source, data []byte) []byte {
+	if len(source) == 0 {
+		return data
+	}
+	original := strings.Split(string(source), "\n")
+	encoded := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	matches := matchLines(original, encoded)
+
+	var out strings.Builder
+	blank := true // Nothing written yet, so no blank line is needed
+	for k, line := range encoded {
+		if i := matches[k]; i >= 0 {
+			start := i
+			for start > 0 && strings.TrimSpace(original[start-1]) == "" {
+				start--
+			}
+			for j := start; j < i && !blank; j++ {
+				out.WriteString("\n")
+			}
+			line = strings.TrimRight(original[i], " \t\r")
+		}
+		out.WriteString(line)
+		out.WriteString("\n")
+		blank = strings.TrimSpace(line) == ""
+	}
+
+	restored := []byte(out.String())
+	var before, after interface{}
+	if yaml.Unmarshal(data, &before) != nil || yaml.Unmarshal(restored, &after) != nil || !reflect.DeepEqual(before, after) {
+		return data
+	}
+	return restored
+}
+
+// matchLines pairs each encoded line with the source line it came from, or
+// -1, as the longest common subsequence of non-blank lines equal up to
+// trailing spacing and the spacing before inline comments
+func matchLines(original, encoded []string) []int {
+	normalize := func(line string) string {
+		return commentGap.ReplaceAllString(strings.TrimRight(line, " \t\r"), " #")
+	}
+	a := make([]string, len(original))
+	for i, line := range original {
+		a[i] = normalize(line)
+	}
+	b := make([]string, len(encoded))
+	for k, line := range encoded {
+		b[k] = normalize(line)
+	}
+
+	// lengths[i][k] is the longest common subsequence of a[i:] and b[k:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for k := len(b) - 1; k >= 0; k-- {
+			switch {
+			case a[i] != "" && a[i] == b[k]:
+				lengths[i][k] = lengths[i+1][k+1] + 1
+			case lengths[i+1][k] >= lengths[i][k+1]:
+				lengths[i][k] = lengths[i+1][k]
+			default:
+				lengths[i][k] = lengths[i][k+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(b))
+	for i, k := 0, 0; k < len(b); {
+		switch {
+		case i < len(a) && a[i] != "" && a[i] == b[k]:
+			matches[k] = i
+			i++
+			k++
+		case i < len(a) && lengths[i+1][k] >= lengths[i][k+1]:
+			i++
+		default:
+			matches[k] = -1
+			k++
+		}
+	}
+	return matches
+}
+
+// detectIndent returns the indentation of the first nested key in data
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+		if indent := len(line) - len(trimmed); indent >= 2 && indent <= 8 {
+			return indent
+		}
+		break
+	}
+	return defaultIndent
+}
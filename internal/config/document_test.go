@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const commentedConfig = `# My pomodux settings
+version: "1.0"
+timers:
+  work: 25m # classic pomodoro
+  break: 5m
+# Ring when done
+timer:
+  bell_on_complete: false
+logging:
+  level: info
+`
+
+func TestDocument_SetPreservesCommentsAndOrder(t *testing.T) {
+	doc, err := ParseDocument([]byte(commentedConfig))
+	require.NoError(t, err)
+
+	require.NoError(t, doc.Set("timer.bell_on_complete", "true"))
+	require.NoError(t, doc.Set("timers.work", "50m"))
+	require.NoError(t, doc.Set("timers.deep", "90m"))
+	require.NoError(t, doc.Set("history.retention.keep_months", "12"))
+	require.NoError(t, doc.Set("goals.break_presets", "break, long_break"))
+
+	data, err := doc.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, `# My pomodux settings
+version: "1.0"
+timers:
+  work: 50m # classic pomodoro
+  break: 5m
+  deep: 90m
+# Ring when done
+timer:
+  bell_on_complete: true
+logging:
+  level: info
+history:
+  retention:
+    keep_months: 12
+goals:
+  break_presets:
+    - break
+    - long_break
+`, string(data))
+
+	config, problems, err := Validate(data)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	assert.True(t, config.Timer.BellOnComplete)
+	assert.Equal(t, 12, config.History.Retention.KeepMonths)
+}
+
+func TestDocument_SetRejectsInvalidValues(t *testing.T) {
+	doc, err := ParseDocument([]byte(commentedConfig))
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, doc.Set("timer.bell", "true"), "unknown config key")
+	assert.ErrorContains(t, doc.Set("timer.bell_on_complete", "sometimes"), "true or false")
+	assert.ErrorContains(t, doc.Set("history.retention.keep_months", "a year"), "whole number")
+	assert.ErrorContains(t, doc.Set("history", "json"), "is a section")
+	assert.ErrorContains(t, doc.Set("timers..work", "25m"), "invalid config key")
+}
+
+func TestDocument_SaveValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pomodux", "config.yaml")
+	doc, err := LoadDocument(path)
+	require.NoError(t, err, "a missing file starts from the defaults")
+
+	require.NoError(t, doc.Set("history.backend", "csv"))
+	assert.ErrorContains(t, doc.Save(path), `invalid history backend "csv" (want json|jsonl|sqlite)`)
+	require.NoError(t, doc.Set("timers.work", "soon"))
+	require.NoError(t, doc.Set("history.backend", "sqlite"))
+	assert.ErrorContains(t, doc.Save(path), `invalid duration "soon" for timer "work"`)
+
+	require.NoError(t, doc.Set("timers.work", "30m"))
+	require.NoError(t, doc.Save(path))
+	config, err := LoadFromPath(path)
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", config.History.Backend)
+	assert.Equal(t, "30m", config.Timers["work"])
+	assert.Equal(t, "15m", config.Timers["long_break"])
+
+	// Problems the file already had do not block unrelated changes
+	require.NoError(t, os.WriteFile(path, []byte("stats:\n  week_start: someday\n"), 0600))
+	doc, err = LoadDocument(path)
+	require.NoError(t, err)
+	require.NoError(t, doc.Set("theme", "nord"))
+	require.NoError(t, doc.Save(path))
+}
+
+func TestDocument_Unset(t *testing.T) {
+	doc, err := ParseDocument([]byte(commentedConfig))
+	require.NoError(t, err)
+
+	assert.True(t, doc.Unset("timers.break"))
+	assert.False(t, doc.Unset("timers.break"))
+	assert.False(t, doc.Unset("stats.week_start"))
+	data, err := doc.Bytes()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "break")
+	assert.Contains(t, string(data), "work: 25m # classic pomodoro")
+}
+
+func TestDocument_KeepsLayout(t *testing.T) {
+	doc, err := ParseDocument([]byte(`version: "1.0"
+
+timers:
+  work: 25m   # focus
+  break: 5m
+
+goals:
+  daily:
+    minutes: 120
+
+logging:
+  level: info   # or debug
+`))
+	require.NoError(t, err)
+
+	require.NoError(t, doc.Set("timers.break", "10m"))
+	assert.True(t, doc.Unset("goals.daily.minutes"))
+	data, err := doc.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, `version: "1.0"
+
+timers:
+  work: 25m   # focus
+  break: 10m
+
+logging:
+  level: info   # or debug
+`, string(data), "emptied sections are removed and blank lines and comment spacing kept")
+
+	assert.True(t, doc.Unset("timers.work"))
+	data, err = doc.Bytes()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "timers:\n  break: 10m\n\nlogging:")
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := Validate([]byte("timer:\n  bell: true\n"))
+	assert.ErrorContains(t, err, "field bell not found")
+
+	_, _, err = Validate([]byte("timer:\n  bell_on_complete: loud\n"))
+	assert.Error(t, err)
+
+	config, problems, err := Validate([]byte("logging:\n  level: chatty\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{`invalid log level "chatty" (want debug|info|warn|error)`}, problems)
+	assert.Equal(t, "info", config.Logging.Level)
+
+	// Only loading resets the value, so only its warning says how
+	loaded := &Config{Logging: LoggingConfig{Level: "chatty"}}
+	warnings := repair(loaded)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, `Invalid log level "chatty" (want debug|info|warn|error), defaulting to info`, warnings[0].warning())
+}
+
+func TestLookup(t *testing.T) {
+	config := DefaultConfig()
+
+	value, err := Lookup(config, "timers.work")
+	require.NoError(t, err)
+	assert.Equal(t, "25m", value)
+
+	value, err = Lookup(config, "timer")
+	require.NoError(t, err)
+	assert.Equal(t, "bell_on_complete: false", value)
+
+	_, err = Lookup(config, "timers.deep")
+	assert.ErrorContains(t, err, "not set")
+	_, err = Lookup(config, "nope")
+	assert.ErrorContains(t, err, "unknown config key")
+}
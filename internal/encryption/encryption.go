@@ -0,0 +1,225 @@
+// Package encryption encrypts the files of the state directory at rest.
+//
+// Files are sealed with AES-256-GCM under a random data key. The data key is
+// itself sealed with a key encryption key derived from a passphrase (scrypt)
+// or a keyfile (HKDF) and kept in the state directory's encryption.json, so
+// changing the passphrase or keyfile only rewrites that descriptor.
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+)
+
+// fileMagic starts every encrypted file
+const fileMagic = "PDXENC1\n"
+
+// linePrefix starts every encrypted line of a JSON Lines file
+const linePrefix = "pdxenc1:"
+
+var (
+	// ErrLocked is returned when reading an encrypted file without a key
+	ErrLocked = errors.New("file is encrypted; unlock the history with its passphrase or keyfile")
+	// ErrWrongKey is returned when no key opens an encrypted file or data key
+	ErrWrongKey = errors.New("wrong passphrase or keyfile")
+	// ErrNotEncrypted is returned when a keyring opens data that is not
+	// encrypted, such as a file written by a process started before
+	// encryption was enabled
+	ErrNotEncrypted = errors.New("file is not encrypted although encryption is on; rotate the key to encrypt it")
+)
+
+// Keyring holds the data keys of a state directory. The first key seals new
+// data; every key is tried when opening, so files written before a key
+// rotation finished stay readable. Unencrypted data is rejected, except by
+// the keyrings that convert files when encryption is turned on or off.
+type Keyring struct {
+	raw       [][]byte
+	keys      []cipher.AEAD
+	plaintext bool // Open and OpenLine pass unencrypted data through
+}
+
+// newKeyring creates a keyring from 32-byte AES keys
+func newKeyring(keys ...[]byte) (*Keyring, error) {
+	ring := &Keyring{}
+	for _, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		ring.raw = append(ring.raw, key)
+		ring.keys = append(ring.keys, aead)
+	}
+	return ring, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts data with the first key; a nil keyring returns data as is
+func (r *Keyring) Seal(data []byte) ([]byte, error) {
+	if r == nil {
+		return data, nil
+	}
+	sealed, err := seal(r.keys[0], data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(fileMagic), sealed...), nil
+}
+
+// Open decrypts data sealed with any key of the keyring. A nil keyring
+// returns data that is not encrypted as is.
+func (r *Keyring) Open(data []byte) ([]byte, error) {
+	sealed, ok := bytes.CutPrefix(data, []byte(fileMagic))
+	if !ok {
+		if r != nil && !r.plaintext && len(data) > 0 {
+			return nil, ErrNotEncrypted
+		}
+		return data, nil
+	}
+	if r == nil {
+		return nil, ErrLocked
+	}
+	return r.open(sealed)
+}
+
+// SealLine encrypts one line of a JSON Lines file, without its newline
+func (r *Keyring) SealLine(line []byte) ([]byte, error) {
+	if r == nil {
+		return line, nil
+	}
+	sealed, err := seal(r.keys[0], line)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(linePrefix + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// OpenLine decrypts a line written by SealLine. A nil keyring returns other
+// lines as is.
+func (r *Keyring) OpenLine(line []byte) ([]byte, error) {
+	encoded, ok := bytes.CutPrefix(bytes.TrimRight(line, "\r\n"), []byte(linePrefix))
+	if !ok {
+		if r != nil && !r.plaintext {
+			return nil, ErrNotEncrypted
+		}
+		return line, nil
+	}
+	if r == nil {
+		return nil, ErrLocked
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted line: %w", err)
+	}
+	return r.open(sealed)
+}
+
+// converting returns a copy of the keyring that also accepts unencrypted
+// data, for rewriting files that may be partly converted
+func (r *Keyring) converting() *Keyring {
+	if r == nil {
+		return nil
+	}
+	return &Keyring{raw: r.raw, keys: r.keys, plaintext: true}
+}
+
+func (r *Keyring) open(sealed []byte) ([]byte, error) {
+	for _, aead := range r.keys {
+		if data, err := open(aead, sealed); err == nil {
+			return data, nil
+		}
+	}
+	return nil, ErrWrongKey
+}
+
+// seal returns nonce || ciphertext
+func seal(aead cipher.AEAD, data []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+	data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	return data, nil
+}
+
+// IsEncrypted reports whether data was written by Seal
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(fileMagic))
+}
+
+var (
+	mu     sync.RWMutex
+	active *Keyring
+)
+
+// Unlock makes ring the keyring used by Decrypt, WriteFile and the line
+// helpers; nil leaves files unencrypted
+func Unlock(ring *Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	active = ring
+}
+
+// Active returns the keyring set by Unlock, nil when encryption is off
+func Active() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// Decrypt opens data with the active keyring
+func Decrypt(data []byte) ([]byte, error) {
+	return Active().Open(data)
+}
+
+// DecryptForeign opens data from outside the state directory, such as a
+// copy of another machine's history, with the active keyring. Unlike
+// Decrypt it accepts unencrypted data.
+func DecryptForeign(data []byte) ([]byte, error) {
+	return Active().converting().Open(data)
+}
+
+// WriteFile atomically replaces path with data, sealed with the active
+// keyring when encryption is on
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	sealed, err := Active().Seal(data)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, sealed, perm)
+}
+
+// SealLine encrypts a line with the active keyring
+func SealLine(line []byte) ([]byte, error) {
+	return Active().SealLine(line)
+}
+
+// OpenLine decrypts a line with the active keyring
+func OpenLine(line []byte) ([]byte, error) {
+	return Active().OpenLine(line)
+}
@@ -0,0 +1,208 @@
+package encryption
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cheapScrypt lowers the scrypt cost for the duration of a test
+func cheapScrypt(t *testing.T) {
+	n, r, p := scryptN, scryptR, scryptP
+	scryptN, scryptR, scryptP = 1<<10, 8, 1
+	t.Cleanup(func() {
+		scryptN, scryptR, scryptP = n, r, p
+		Unlock(nil)
+	})
+}
+
+func testKeyring(t *testing.T) *Keyring {
+	key := make([]byte, keySize)
+	key[0] = 1
+	ring, err := newKeyring(key)
+	require.NoError(t, err)
+	return ring
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	ring := testKeyring(t)
+	sealed, err := ring.Seal([]byte(`{"sessions":[]}`))
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(sealed))
+	assert.NotContains(t, string(sealed), "sessions")
+
+	plain, err := ring.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sessions":[]}`, string(plain))
+
+	// Unencrypted data is rejected once encryption is on, except while
+	// converting files
+	_, err = ring.Open([]byte(`{"plain":true}`))
+	assert.ErrorIs(t, err, ErrNotEncrypted)
+	plain, err = ring.converting().Open([]byte(`{"plain":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"plain":true}`, string(plain))
+
+	var locked *Keyring
+	_, err = locked.Open(sealed)
+	assert.ErrorIs(t, err, ErrLocked)
+	plain, err = locked.Open([]byte(`{"plain":true}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"plain":true}`, string(plain), "unencrypted data passes through while encryption is off")
+
+	other, err := newKeyring(make([]byte, keySize))
+	require.NoError(t, err)
+	_, err = other.Open(sealed)
+	assert.ErrorIs(t, err, ErrWrongKey)
+}
+
+func TestKeyring_Lines(t *testing.T) {
+	ring := testKeyring(t)
+	sealed, err := ring.SealLine([]byte(`{"op":"add"}`))
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "\n")
+
+	plain, err := ring.OpenLine(append(sealed, '\n'))
+	require.NoError(t, err)
+	assert.Equal(t, `{"op":"add"}`, string(plain))
+
+	_, err = ring.OpenLine([]byte(`{"op":"edit"}`))
+	assert.ErrorIs(t, err, ErrNotEncrypted)
+	plain, err = ring.converting().OpenLine([]byte(`{"op":"edit"}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"op":"edit"}`, string(plain))
+}
+
+func TestEnableRotateDisable(t *testing.T) {
+	cheapScrypt(t)
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "history.json")
+	lines := filepath.Join(dir, "audit.jsonl")
+	require.NoError(t, os.WriteFile(doc, []byte(`{"sessions":[]}`), 0600))
+	require.NoError(t, os.WriteFile(lines, []byte("{\"a\":1}\n{\"b\":2}\n"), 0600))
+	files := Files{Documents: []string{doc, filepath.Join(dir, "missing.json")}, Lines: []string{lines}}
+
+	d, kek, err := NewDescriptor("correct horse", "")
+	require.NoError(t, err)
+	_, err = Enable(dir, d, kek, files)
+	require.NoError(t, err)
+	_, err = Enable(dir, d, kek, files)
+	assert.Error(t, err, "enabling twice fails")
+
+	data, err := os.ReadFile(doc)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(data))
+	plain, err := Decrypt(data)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sessions":[]}`, string(plain))
+
+	// Unlocking again needs the passphrase
+	Unlock(nil)
+	_, err = UnlockDir(dir, func() (string, error) { return "wrong", nil })
+	assert.ErrorIs(t, err, ErrWrongKey)
+	t.Setenv(PassphraseEnv, "correct horse")
+	loaded, err := UnlockDir(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, KDFScrypt, loaded.KDF)
+	oldRing := Active()
+
+	// Rotating to a keyfile re-encrypts everything under a new data key
+	keyfile := filepath.Join(dir, "keys", "history.key")
+	require.NoError(t, GenerateKeyfile(keyfile))
+	assert.Error(t, GenerateKeyfile(keyfile), "an existing keyfile is not replaced")
+	next, nextKEK, err := NewDescriptor("", keyfile)
+	require.NoError(t, err)
+	_, err = Rotate(dir, oldRing, next, nextKEK, files)
+	require.NoError(t, err)
+
+	data, err = os.ReadFile(doc)
+	require.NoError(t, err)
+	_, err = oldRing.Open(data)
+	assert.ErrorIs(t, err, ErrWrongKey, "the old data key no longer opens rotated files")
+
+	Unlock(nil)
+	loaded, err = UnlockDir(dir, nil)
+	require.NoError(t, err)
+	assert.Equal(t, KDFKeyfile, loaded.KDF)
+	content, err := os.ReadFile(lines)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), `"a":1`)
+	for _, line := range splitLines(content) {
+		plain, err := OpenLine(line)
+		require.NoError(t, err)
+		assert.Contains(t, string(plain), `":`)
+	}
+
+	require.NoError(t, Disable(dir, Active(), files))
+	assert.Nil(t, Active())
+	data, err = os.ReadFile(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sessions":[]}`, string(data))
+	data, err = os.ReadFile(lines)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n{\"b\":2}\n", string(data))
+	d, err = LoadDescriptor(dir)
+	require.NoError(t, err)
+	assert.Nil(t, d)
+}
+
+func TestEnable_RejectsLaterPlaintext(t *testing.T) {
+	cheapScrypt(t)
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "history.json")
+	require.NoError(t, os.WriteFile(doc, []byte(`{"sessions":[]}`), 0600))
+
+	var locked []string
+	files := Files{Documents: []string{doc}, Lock: func(path string, fn func() error) error {
+		locked = append(locked, path)
+		return fn()
+	}}
+	d, kek, err := NewDescriptor("correct horse", "")
+	require.NoError(t, err)
+	_, err = Enable(dir, d, kek, files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{doc}, locked, "files are rewritten under their lock")
+
+	// A process that started before encryption was enabled still writes
+	// plaintext; reading it fails instead of silently accepting it
+	require.NoError(t, os.WriteFile(doc, []byte(`{"sessions":[{}]}`), 0600))
+	data, err := os.ReadFile(doc)
+	require.NoError(t, err)
+	_, err = Decrypt(data)
+	assert.ErrorIs(t, err, ErrNotEncrypted)
+	plain, err := DecryptForeign(data)
+	require.NoError(t, err, "files from elsewhere may be plaintext")
+	assert.Equal(t, `{"sessions":[{}]}`, string(plain))
+
+	// Rotating encrypts it again
+	next, nextKEK, err := NewDescriptor("battery staple", "")
+	require.NoError(t, err)
+	_, err = Rotate(dir, Active(), next, nextKEK, files)
+	require.NoError(t, err)
+	data, err = os.ReadFile(doc)
+	require.NoError(t, err)
+	plain, err = Decrypt(data)
+	require.NoError(t, err)
+	assert.Equal(t, `{"sessions":[{}]}`, string(plain))
+}
+
+func TestDescriptor_ShortKeyfile(t *testing.T) {
+	keyfile := filepath.Join(t.TempDir(), "short.key")
+	require.NoError(t, os.WriteFile(keyfile, []byte("too short\n"), 0600))
+	_, _, err := NewDescriptor("", keyfile)
+	assert.Error(t, err)
+}
+
+func splitLines(data []byte) [][]byte {
+	var lines [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
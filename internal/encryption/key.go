@@ -0,0 +1,432 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+	"golang.org/x/crypto/scrypt"
+)
+
+// DescriptorFileName is the file in the state directory describing how its
+// data keys are protected; encryption is on while it exists
+const DescriptorFileName = "encryption.json"
+
+// PassphraseEnv names the environment variable read for the passphrase
+// before prompting for it
+const PassphraseEnv = "POMODUX_PASSPHRASE"
+
+// NewPassphraseEnv names the environment variable read for a new passphrase
+// when enabling encryption or rotating the key
+const NewPassphraseEnv = "POMODUX_NEW_PASSPHRASE"
+
+// Key derivation functions
+const (
+	KDFScrypt  = "scrypt"
+	KDFKeyfile = "keyfile"
+)
+
+// scrypt cost of new passphrases (N, r, p); tests lower it
+var scryptN, scryptR, scryptP = 1 << 15, 8, 1
+
+// keySize is the size of data keys and key encryption keys (AES-256)
+const keySize = 32
+
+// Descriptor records how the data keys of a state directory are derived
+// and holds them sealed with the key encryption key
+type Descriptor struct {
+	Version  int      `json:"version"`
+	KDF      string   `json:"kdf"` // scrypt or keyfile
+	Salt     []byte   `json:"salt,omitempty"`
+	N        int      `json:"n,omitempty"`
+	R        int      `json:"r,omitempty"`
+	P        int      `json:"p,omitempty"`
+	Keyfile  string   `json:"keyfile,omitempty"`
+	DataKeys [][]byte `json:"data_keys"` // The first is current; more remain while a rotation is unfinished
+}
+
+// Files lists the files of a state directory to encrypt: whole documents
+// and JSON Lines files encrypted line by line. Missing files are skipped.
+// Lock, when set, runs the rewrite of each file while holding the lock its
+// writers take.
+type Files struct {
+	Documents []string
+	Lines     []string
+	Lock      func(path string, fn func() error) error
+}
+
+// NewDescriptor creates a descriptor protected by a passphrase or, when
+// keyfile is set, by that keyfile, and returns its key encryption key
+func NewDescriptor(passphrase, keyfile string) (*Descriptor, []byte, error) {
+	d := &Descriptor{Version: 1}
+	if keyfile != "" {
+		abs, err := filepath.Abs(keyfile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve keyfile path: %w", err)
+		}
+		d.KDF = KDFKeyfile
+		d.Keyfile = abs
+	} else {
+		if passphrase == "" {
+			return nil, nil, fmt.Errorf("passphrase cannot be empty")
+		}
+		d.KDF = KDFScrypt
+		d.Salt = make([]byte, 16)
+		if _, err := rand.Read(d.Salt); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		d.N, d.R, d.P = scryptN, scryptR, scryptP
+	}
+	kek, err := d.KEK(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, kek, nil
+}
+
+// KEK derives the key encryption key from the passphrase, or from the
+// keyfile for keyfile descriptors
+func (d *Descriptor) KEK(passphrase string) ([]byte, error) {
+	switch d.KDF {
+	case KDFScrypt:
+		kek, err := scrypt.Key([]byte(passphrase), d.Salt, d.N, d.R, d.P, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		return kek, nil
+	case KDFKeyfile:
+		secret, err := os.ReadFile(d.Keyfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keyfile: %w", err)
+		}
+		secret = bytes.TrimSpace(secret)
+		if len(secret) < keySize {
+			return nil, fmt.Errorf("keyfile %s is too short (at least %d bytes)", d.Keyfile, keySize)
+		}
+		return hkdf.Key(sha256.New, secret, nil, "pomodux history", keySize)
+	default:
+		return nil, fmt.Errorf("unknown key derivation %q", d.KDF)
+	}
+}
+
+// NeedsPassphrase reports whether deriving the key takes a passphrase
+func (d *Descriptor) NeedsPassphrase() bool {
+	return d.KDF == KDFScrypt
+}
+
+// Keyring opens the data keys with kek
+func (d *Descriptor) Keyring(kek []byte) (*Keyring, error) {
+	keys, err := d.dataKeys(kek)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyring(keys...)
+}
+
+func (d *Descriptor) dataKeys(kek []byte) ([][]byte, error) {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.DataKeys) == 0 {
+		return nil, fmt.Errorf("encryption descriptor holds no data key")
+	}
+	var keys [][]byte
+	for _, sealed := range d.DataKeys {
+		key, err := open(aead, sealed)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (d *Descriptor) setDataKeys(kek []byte, keys [][]byte) error {
+	aead, err := newAEAD(kek)
+	if err != nil {
+		return err
+	}
+	d.DataKeys = nil
+	for _, key := range keys {
+		sealed, err := seal(aead, key)
+		if err != nil {
+			return err
+		}
+		d.DataKeys = append(d.DataKeys, sealed)
+	}
+	return nil
+}
+
+// LoadDescriptor reads the descriptor of dir, nil if encryption is off
+func LoadDescriptor(dir string) (*Descriptor, error) {
+	data, err := os.ReadFile(filepath.Join(dir, DescriptorFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption descriptor: %w", err)
+	}
+	var d Descriptor
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption descriptor: %w", err)
+	}
+	return &d, nil
+}
+
+func (d *Descriptor) save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal encryption descriptor: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(dir, DescriptorFileName), data, 0600); err != nil {
+		return fmt.Errorf("failed to save encryption descriptor: %w", err)
+	}
+	return nil
+}
+
+// UnlockDir unlocks the files of dir when encryption is on. The passphrase
+// comes from $POMODUX_PASSPHRASE, else from prompt, which defaults to
+// asking on the terminal. It returns the descriptor, nil when encryption is
+// off.
+func UnlockDir(dir string, prompt func() (string, error)) (*Descriptor, error) {
+	d, err := LoadDescriptor(dir)
+	if err != nil || d == nil {
+		Unlock(nil)
+		return nil, err
+	}
+
+	passphrase := ""
+	if d.NeedsPassphrase() {
+		passphrase = os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			if prompt == nil {
+				prompt = func() (string, error) { return PromptPassphrase("History passphrase: ") }
+			}
+			if passphrase, err = prompt(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	kek, err := d.KEK(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ring, err := d.Keyring(kek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock history: %w", err)
+	}
+	Unlock(ring)
+	return d, nil
+}
+
+// Enable encrypts files with a new data key protected by next. The
+// descriptor is written first; files an interrupted run left unencrypted are
+// refused when read until rotating the key encrypts them.
+func Enable(dir string, next *Descriptor, kek []byte, files Files) (*Keyring, error) {
+	if d, err := LoadDescriptor(dir); err != nil {
+		return nil, err
+	} else if d != nil {
+		return nil, fmt.Errorf("encryption is already enabled")
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	if err := next.setDataKeys(kek, [][]byte{key}); err != nil {
+		return nil, err
+	}
+	if err := next.save(dir); err != nil {
+		return nil, err
+	}
+	ring, err := newKeyring(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := rewrite(files, ring, ring); err != nil {
+		return nil, err
+	}
+	Unlock(ring)
+	return ring, nil
+}
+
+// Rotate re-encrypts files, opened with current, under a new data key
+// protected by next, which may hold a new passphrase or keyfile. Until every
+// file is rewritten the descriptor keeps the old data keys too, sealed under
+// next, so an interrupted rotation is completed by rotating again.
+func Rotate(dir string, current *Keyring, next *Descriptor, nextKEK []byte, files Files) (*Keyring, error) {
+	if current == nil {
+		return nil, fmt.Errorf("encryption is not enabled")
+	}
+	oldKeys := current.raw
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	keys := append([][]byte{key}, oldKeys...)
+	if err := next.setDataKeys(nextKEK, keys); err != nil {
+		return nil, err
+	}
+	if err := next.save(dir); err != nil {
+		return nil, err
+	}
+	ring, err := newKeyring(keys...)
+	if err != nil {
+		return nil, err
+	}
+	if err := rewrite(files, ring, ring); err != nil {
+		return nil, err
+	}
+
+	if err := next.setDataKeys(nextKEK, [][]byte{key}); err != nil {
+		return nil, err
+	}
+	if err := next.save(dir); err != nil {
+		return nil, err
+	}
+	if ring, err = newKeyring(key); err != nil {
+		return nil, err
+	}
+	Unlock(ring)
+	return ring, nil
+}
+
+// Disable decrypts files with ring and removes the descriptor
+func Disable(dir string, ring *Keyring, files Files) error {
+	if err := rewrite(files, ring, nil); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, DescriptorFileName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove encryption descriptor: %w", err)
+	}
+	Unlock(nil)
+	return nil
+}
+
+// rewrite opens each file with from, or as is if it is not encrypted yet,
+// and seals it with to
+func rewrite(files Files, from, to *Keyring) error {
+	from = from.converting()
+	lock := files.Lock
+	if lock == nil {
+		lock = func(path string, fn func() error) error { return fn() }
+	}
+
+	for _, path := range files.Documents {
+		err := rewriteFile(path, lock, func(data []byte) ([]byte, error) {
+			plain, err := from.Open(data)
+			if err != nil {
+				return nil, err
+			}
+			return to.Seal(plain)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	for _, path := range files.Lines {
+		err := rewriteFile(path, lock, func(data []byte) ([]byte, error) {
+			var out bytes.Buffer
+			reader := bufio.NewReader(bytes.NewReader(data))
+			for {
+				// A partial final line left by a crash is dropped
+				line, err := reader.ReadBytes('\n')
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				plain, err := from.OpenLine(line)
+				if err != nil {
+					return nil, err
+				}
+				sealed, err := to.SealLine(bytes.TrimRight(plain, "\r\n"))
+				if err != nil {
+					return nil, err
+				}
+				out.Write(sealed)
+				out.WriteByte('\n')
+			}
+			return out.Bytes(), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewriteFile replaces the file at path with its converted content, reading
+// and writing it under lock so no concurrent write is lost
+func rewriteFile(path string, lock func(string, func() error) error, convert func([]byte) ([]byte, error)) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	return lock(path, func() error {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		converted, err := convert(data)
+		if err != nil {
+			return fmt.Errorf("failed to convert %s: %w", filepath.Base(path), err)
+		}
+		if err := atomicfile.WriteFile(path, converted, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// GenerateKeyfile writes a new random keyfile to path, refusing to replace
+// an existing file
+func GenerateKeyfile(path string) error {
+	secret := make([]byte, keySize)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate keyfile: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create keyfile directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create keyfile: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(base64.StdEncoding.EncodeToString(secret) + "\n"); err != nil {
+		return fmt.Errorf("failed to write keyfile: %w", err)
+	}
+	return f.Sync()
+}
+
+// Describe summarises how a descriptor protects the data keys
+func (d *Descriptor) Describe() string {
+	switch d.KDF {
+	case KDFScrypt:
+		return fmt.Sprintf("passphrase (scrypt N=%d r=%d p=%d)", d.N, d.R, d.P)
+	case KDFKeyfile:
+		return "keyfile " + d.Keyfile
+	default:
+		return "unknown key derivation " + d.KDF
+	}
+}
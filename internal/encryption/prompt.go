@@ -0,0 +1,24 @@
+package encryption
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// PromptPassphrase reads a passphrase from the terminal without echoing it.
+// It fails when stdin is not a terminal; set $POMODUX_PASSPHRASE instead.
+func PromptPassphrase(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("history is encrypted and stdin is not a terminal; set %s", PassphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
@@ -0,0 +1,202 @@
+// Package export writes session history in machine-readable formats.
+// The field names and column order below are a public interface used by
+// dashboards and scripts: add fields at the end, never rename or remove them
+// without bumping SchemaVersion. See docs/stats-output.md.
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+)
+
+// Output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+)
+
+// SchemaVersion identifies the layout of the machine-readable output
+const SchemaVersion = 1
+
+// Formats lists every supported output format
+var Formats = []string{FormatTable, FormatJSON, FormatCSV, FormatTSV}
+
+// ValidFormat reports whether format is supported
+func ValidFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionRecord is the exported form of a session. Durations are whole
+// seconds and times are RFC 3339 with the offset they were recorded in.
+type SessionRecord struct {
+	ID             string   `json:"id"`
+	StartedAt      string   `json:"started_at"`
+	EndedAt        string   `json:"ended_at"`
+	PlannedSeconds int64    `json:"planned_seconds"`
+	FocusSeconds   int64    `json:"focus_seconds"`
+	Label          string   `json:"label"`
+	Preset         string   `json:"preset"`
+	Project        string   `json:"project"`
+	Tags           []string `json:"tags"`
+	EndStatus      string   `json:"end_status"`
+	PausedCount    int      `json:"paused_count"`
+	PausedSeconds  int64    `json:"paused_seconds"`
+}
+
+// sessionColumns is the header of delimited session output
+var sessionColumns = []string{
+	"id", "started_at", "ended_at", "planned_seconds", "focus_seconds", "label",
+	"preset", "project", "tags", "end_status", "paused_count", "paused_seconds",
+}
+
+// NewSessionRecord converts a session to its exported form
+func NewSessionRecord(s history.Session) SessionRecord {
+	tags := s.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return SessionRecord{
+		ID:             s.ID,
+		StartedAt:      s.StartedAt.Format(time.RFC3339),
+		EndedAt:        s.EndedAt.Format(time.RFC3339),
+		PlannedSeconds: parseSeconds(s.Duration),
+		FocusSeconds:   seconds(s.FocusDuration()),
+		Label:          s.Label,
+		Preset:         s.Preset,
+		Project:        s.Project,
+		Tags:           tags,
+		EndStatus:      s.EndStatus,
+		PausedCount:    s.PausedCount,
+		PausedSeconds:  parseSeconds(s.PausedDuration),
+	}
+}
+
+// fields returns the record as delimited columns; tags are comma separated
+func (r SessionRecord) fields() []string {
+	return []string{
+		r.ID,
+		r.StartedAt,
+		r.EndedAt,
+		fmt.Sprint(r.PlannedSeconds),
+		fmt.Sprint(r.FocusSeconds),
+		r.Label,
+		r.Preset,
+		r.Project,
+		strings.Join(r.Tags, ","),
+		r.EndStatus,
+		fmt.Sprint(r.PausedCount),
+		fmt.Sprint(r.PausedSeconds),
+	}
+}
+
+// SummaryRecord is the exported form of stats.Summary
+type SummaryRecord struct {
+	Sessions            int     `json:"sessions"`
+	FocusSeconds        int64   `json:"focus_seconds"`
+	Completed           int     `json:"completed"`
+	Stopped             int     `json:"stopped"`
+	Cancelled           int     `json:"cancelled"`
+	Interrupted         int     `json:"interrupted"`
+	Manual              int     `json:"manual"`
+	CompletionRate      float64 `json:"completion_rate"`
+	Pauses              int     `json:"pauses"`
+	PausedSeconds       int64   `json:"paused_seconds"`
+	AveragePauseSeconds int64   `json:"average_pause_seconds"`
+}
+
+// NewSummaryRecord converts a summary to its exported form
+func NewSummaryRecord(s stats.Summary) SummaryRecord {
+	return SummaryRecord{
+		Sessions:            s.Sessions,
+		FocusSeconds:        seconds(s.TotalFocus),
+		Completed:           s.Completed,
+		Stopped:             s.Stopped,
+		Cancelled:           s.Cancelled,
+		Interrupted:         s.Interrupted,
+		Manual:              s.Manual,
+		CompletionRate:      s.CompletionRate,
+		Pauses:              s.Pauses,
+		PausedSeconds:       seconds(s.TotalPaused),
+		AveragePauseSeconds: seconds(s.AveragePause),
+	}
+}
+
+// TotalRecord is the exported form of history.Total
+type TotalRecord struct {
+	Key          string `json:"key"`
+	Sessions     int    `json:"sessions"`
+	FocusSeconds int64  `json:"focus_seconds"`
+}
+
+// totalColumns is the header of delimited totals output
+var totalColumns = []string{"key", "sessions", "focus_seconds"}
+
+// NewTotalRecord converts a grouped total to its exported form
+func NewTotalRecord(t history.Total) TotalRecord {
+	return TotalRecord{Key: t.Key, Sessions: t.Sessions, FocusSeconds: seconds(t.Focus)}
+}
+
+func (r TotalRecord) fields() []string {
+	return []string{r.Key, fmt.Sprint(r.Sessions), fmt.Sprint(r.FocusSeconds)}
+}
+
+// BucketRecord is the exported form of one calendar period's totals
+type BucketRecord struct {
+	Start  string        `json:"start"`
+	End    string        `json:"end"`
+	Totals []TotalRecord `json:"totals"`
+}
+
+// bucketColumns is the header of delimited bucket output; each total is a row
+var bucketColumns = []string{"period_start", "period_end", "key", "sessions", "focus_seconds"}
+
+// NewBucketRecord converts a period bucket to its exported form
+func NewBucketRecord(b stats.Bucket) BucketRecord {
+	totals := make([]TotalRecord, 0, len(b.Totals))
+	for _, t := range b.Totals {
+		totals = append(totals, NewTotalRecord(t))
+	}
+	return BucketRecord{
+		Start:  b.Start.Format(time.RFC3339),
+		End:    b.End.Format(time.RFC3339),
+		Totals: totals,
+	}
+}
+
+// rows returns one delimited row per total; an empty period yields a single
+// zero row so series stay continuous
+func (r BucketRecord) rows() [][]string {
+	totals := r.Totals
+	if len(totals) == 0 {
+		totals = []TotalRecord{{}}
+	}
+	rows := make([][]string, 0, len(totals))
+	for _, t := range totals {
+		rows = append(rows, append([]string{r.Start, r.End}, t.fields()...))
+	}
+	return rows
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(d / time.Second)
+}
+
+// parseSeconds converts a stored duration string, treating invalid values as 0
+func parseSeconds(value string) int64 {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return seconds(d)
+}
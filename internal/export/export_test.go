@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run `go test ./internal/export -update` after an intentional schema change
+var update = flag.Bool("update", false, "update golden files")
+
+func testSessions() []history.Session {
+	utc := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	cet := time.Date(2025, 1, 15, 11, 30, 0, 0, time.FixedZone("CET", 3600))
+	return []history.Session{
+		{
+			ID:             "0b5e3c1e-1111-4c3a-9d7e-000000000001",
+			StartedAt:      utc,
+			EndedAt:        utc.Add(27 * time.Minute),
+			Duration:       "25m",
+			Preset:         "work",
+			Label:          "Fix login",
+			Project:        "auth",
+			Tags:           []string{"bugfix", "backend"},
+			EndStatus:      history.StatusCompleted,
+			PausedCount:    2,
+			PausedDuration: "2m",
+		},
+		{
+			ID:             "0b5e3c1e-2222-4c3a-9d7e-000000000002",
+			StartedAt:      cet,
+			EndedAt:        cet.Add(10*time.Minute + 500*time.Millisecond),
+			Duration:       "25m",
+			Label:          "Write \"docs\", part 1",
+			EndStatus:      history.StatusStopped,
+			PausedDuration: "0s",
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestSessionWriter_Golden(t *testing.T) {
+	sessions := testSessions()
+	for _, format := range []string{FormatJSON, FormatCSV, FormatTSV, FormatICS} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewSessionWriter(&buf, format)
+			require.NoError(t, err)
+			for _, s := range sessions {
+				require.NoError(t, w.Write(s))
+			}
+			require.NoError(t, w.Finish(stats.Summarize(sessions)))
+
+			assertGolden(t, "sessions."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestSessionWriter_Empty(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatCSV, FormatTSV, FormatICS} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewSessionWriter(&buf, format)
+			require.NoError(t, err)
+			require.NoError(t, w.Finish(stats.Summary{}))
+
+			assertGolden(t, "empty."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestWriteTotals_Golden(t *testing.T) {
+	totals := []history.Total{
+		{Key: "auth", Sessions: 3, Focus: 75*time.Minute + 900*time.Millisecond},
+		{Key: "", Sessions: 1, Focus: 10 * time.Minute},
+	}
+	for _, format := range []string{FormatJSON, FormatCSV, FormatTSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteTotals(&buf, format, "project", totals))
+
+			assertGolden(t, "totals."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestWriteBuckets_Golden(t *testing.T) {
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	buckets := []stats.Bucket{
+		{Start: day, End: day.AddDate(0, 0, 1), Totals: []history.Total{
+			{Key: "auth", Sessions: 2, Focus: 50 * time.Minute},
+			{Key: "docs", Sessions: 1, Focus: 10 * time.Minute},
+		}},
+		{Start: day.AddDate(0, 0, 1), End: day.AddDate(0, 0, 2), Totals: []history.Total{}},
+		{Start: day.AddDate(0, 0, 2), End: day.AddDate(0, 0, 3), Totals: []history.Total{
+			{Key: "auth", Sessions: 1, Focus: 25 * time.Minute},
+		}},
+	}
+	for _, format := range []string{FormatJSON, FormatCSV, FormatTSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteBuckets(&buf, format, "day", "project", buckets))
+
+			assertGolden(t, "buckets."+format, buf.Bytes())
+		})
+	}
+}
+
+func TestNewSessionWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewSessionWriter(&bytes.Buffer{}, FormatTable)
+	assert.Error(t, err)
+	assert.Error(t, WriteTotals(&bytes.Buffer{}, "xml", "label", nil))
+}
+
+func TestValidFormat(t *testing.T) {
+	assert.True(t, ValidFormat("json"))
+	assert.True(t, ValidFormat("table"))
+	assert.False(t, ValidFormat("xml"))
+}
+
+func TestICS_FoldsLongLinesOnCharacterBoundaries(t *testing.T) {
+	s := testSessions()[0]
+	s.Label = strings.Repeat("é", 60)
+	s.Notes = "Line one\nLine; two, with \\ backslash"
+
+	var buf bytes.Buffer
+	w, err := NewSessionWriter(&buf, FormatICS)
+	require.NoError(t, err)
+	require.NoError(t, w.Write(s))
+	require.NoError(t, w.Finish(stats.Summary{}))
+
+	out := buf.String()
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+		assert.True(t, utf8.ValidString(line), line)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+s.Label+"\r\n")
+	assert.Contains(t, unfolded, `\n\nLine one\nLine\; two\, with \\ backslash`)
+}
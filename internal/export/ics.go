@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/timer"
+)
+
+// FormatICS is the iCalendar format. It holds sessions only, so it is
+// offered by the export command rather than as a report format.
+const FormatICS = "ics"
+
+// icsTimeLayout is the UTC DATE-TIME form of RFC 5545
+const icsTimeLayout = "20060102T150405Z"
+
+// icsLineLimit is the longest content line in octets before folding
+const icsLineLimit = 75
+
+// icsSessionWriter writes a VCALENDAR with one VEVENT per session. Every
+// property derives from the session, so repeated exports are identical and
+// calendar apps update events in place instead of duplicating them.
+type icsSessionWriter struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func (w *icsSessionWriter) begin() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//pomodux//pomodux-stats//EN")
+	w.line("CALSCALE:GREGORIAN")
+}
+
+func (w *icsSessionWriter) Write(s history.Session) error {
+	w.begin()
+
+	end := s.EndedAt
+	if !end.After(s.StartedAt) {
+		end = s.StartedAt.Add(s.FocusDuration())
+	}
+	summary := s.Label
+	if summary == "" {
+		summary = s.Preset
+	}
+
+	w.line("BEGIN:VEVENT")
+	w.line("UID:" + icsText(icsUID(s)))
+	// DTSTAMP is required; deriving it from the session keeps exports stable
+	w.line("DTSTAMP:" + icsTime(end))
+	w.line("DTSTART:" + icsTime(s.StartedAt))
+	w.line("DTEND:" + icsTime(end))
+	w.line("SUMMARY:" + icsText(summary))
+	w.line("DESCRIPTION:" + icsText(icsDescription(s)))
+	if len(s.Tags) > 0 {
+		tags := make([]string, len(s.Tags))
+		for i, tag := range s.Tags {
+			tags[i] = icsText(tag)
+		}
+		w.line("CATEGORIES:" + strings.Join(tags, ","))
+	}
+	w.line("TRANSP:OPAQUE")
+	w.line("END:VEVENT")
+	return w.w.Flush()
+}
+
+// Finish closes the calendar; the summary is not part of iCalendar output
+func (w *icsSessionWriter) Finish(stats.Summary) error {
+	w.begin()
+	w.line("END:VCALENDAR")
+	return w.w.Flush()
+}
+
+// line writes a CRLF-terminated content line, folded at 75 octets without
+// splitting UTF-8 sequences
+func (w *icsSessionWriter) line(s string) {
+	limit := icsLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.w.WriteString(s[:cut])
+		w.w.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space
+		limit = icsLineLimit - 1
+	}
+	w.w.WriteString(s)
+	w.w.WriteString("\r\n")
+}
+
+// icsUID identifies the event of a session. An interrupted session and its
+// resumption share a session ID, so the start time is part of the UID.
+func icsUID(s history.Session) string {
+	return fmt.Sprintf("%s-%d@pomodux", s.ID, s.StartedAt.Unix())
+}
+
+func icsDescription(s history.Session) string {
+	var lines []string
+	if s.Preset != "" {
+		lines = append(lines, "Preset: "+s.Preset)
+	}
+	lines = append(lines,
+		"Status: "+s.EndStatus,
+		"Focus: "+timer.FormatDuration(s.FocusDuration().Truncate(time.Second)),
+		fmt.Sprintf("Pauses: %d (%s paused)", s.PausedCount, timer.FormatDuration(time.Duration(parseSeconds(s.PausedDuration))*time.Second)),
+	)
+	if s.Project != "" {
+		lines = append(lines, "Project: "+s.Project)
+	}
+	if s.Notes != "" {
+		lines = append(lines, "", s.Notes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format(icsTimeLayout)
+}
+
+// icsText escapes a TEXT property value
+var icsText = strings.NewReplacer(
+	`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`,
+).Replace
@@ -0,0 +1,157 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+)
+
+// FormatOrg is Org-mode: one heading per label, project and tags with the
+// sessions as CLOCK lines in its LOGBOOK drawer
+const FormatOrg = "org"
+
+// orgNoLabel is the heading of sessions without a label
+const orgNoLabel = "(none)"
+
+// orgTimeLayout is an inactive Org timestamp
+const orgTimeLayout = "2006-01-02 Mon 15:04"
+
+var (
+	orgHeading  = regexp.MustCompile(`^\*+\s+(.*?)(?:\s+:([\w@#%:]+):)?\s*$`)
+	orgClock    = regexp.MustCompile(`^\s*CLOCK:\s*\[([^\]]+)\]--\[([^\]]+)\]`)
+	orgProject  = regexp.MustCompile(`^\s*:PROJECT:\s*(\S+)\s*$`)
+	orgTagUnfit = regexp.MustCompile(`[^\w@#%]`)
+)
+
+// orgSessionWriter buffers sessions so they can be grouped by label
+type orgSessionWriter struct {
+	out      io.Writer
+	loc      *time.Location
+	sessions []history.Session
+}
+
+func (w *orgSessionWriter) Write(s history.Session) error {
+	w.sessions = append(w.sessions, s)
+	return nil
+}
+
+// Finish writes the grouped sessions; Org output holds no summary
+func (w *orgSessionWriter) Finish(stats.Summary) error {
+	return WriteOrg(w.out, w.sessions, w.loc)
+}
+
+// WriteOrg writes sessions as CLOCK lines in loc, under one heading per
+// label, project and tags in order of first appearance, newest clock first
+// as Org keeps them. Tags become heading tags, with characters Org does not
+// allow in a tag replaced by "_", and the project a PROJECT property. Org
+// timestamps have minute precision.
+func WriteOrg(out io.Writer, sessions []history.Session, loc *time.Location) error {
+	var headings []string
+	byHeading := map[string][]history.Session{}
+	for _, s := range sessions {
+		heading := s.Label
+		if heading == "" {
+			heading = orgNoLabel
+		}
+		heading = strings.ReplaceAll(heading, "\n", " ")
+		if len(s.Tags) > 0 {
+			tags := make([]string, len(s.Tags))
+			for i, tag := range s.Tags {
+				tags[i] = orgTagUnfit.ReplaceAllString(tag, "_")
+			}
+			heading += " :" + strings.Join(tags, ":") + ":"
+		}
+		if s.Project != "" {
+			heading += "\n  :PROPERTIES:\n  :PROJECT: " + s.Project + "\n  :END:"
+		}
+		if _, ok := byHeading[heading]; !ok {
+			headings = append(headings, heading)
+		}
+		byHeading[heading] = append(byHeading[heading], s)
+	}
+
+	w := bufio.NewWriter(out)
+	for _, heading := range headings {
+		fmt.Fprintf(w, "* %s\n", heading)
+		w.WriteString("  :LOGBOOK:\n")
+		clocks := byHeading[heading]
+		for i := len(clocks) - 1; i >= 0; i-- {
+			start := clocks[i].StartedAt.In(loc).Truncate(time.Minute)
+			end := clocks[i].EndedAt.In(loc).Truncate(time.Minute)
+			elapsed := end.Sub(start)
+			fmt.Fprintf(w, "  CLOCK: [%s]--[%s] => %2d:%02d\n", start.Format(orgTimeLayout), end.Format(orgTimeLayout),
+				int(elapsed.Hours()), int(elapsed.Minutes())%60)
+		}
+		w.WriteString("  :END:\n")
+	}
+	return w.Flush()
+}
+
+// ReadOrg parses the CLOCK lines of an Org document, taking times in loc.
+// Each clock becomes a manual session labelled with its nearest heading,
+// tagged with its tags and in the project of its PROJECT property, oldest
+// first; clocks that are still running are skipped.
+func ReadOrg(r io.Reader, loc *time.Location) ([]history.Session, error) {
+	var sessions []history.Session
+	label, project := "", ""
+	var tags []string
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if m := orgHeading.FindStringSubmatch(line); m != nil {
+			label, project, tags = m[1], "", nil
+			if label == orgNoLabel {
+				label = ""
+			}
+			tags = strings.FieldsFunc(m[2], func(r rune) bool { return r == ':' })
+			continue
+		}
+		if m := orgProject.FindStringSubmatch(line); m != nil {
+			project = m[1]
+			continue
+		}
+		m := orgClock.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		start, err := parseOrgTime(m[1], loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		end, err := parseOrgTime(m[2], loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		s := importedSession(start, end)
+		s.Label, s.Project = label, project
+		s.Tags = append([]string(nil), tags...)
+		sessions = append(sessions, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Org document: %w", err)
+	}
+
+	sortOldestFirst(sessions)
+	return sessions, nil
+}
+
+// parseOrgTime parses "2025-01-15 Wed 09:00"; the weekday is not checked
+func parseOrgTime(value string, loc *time.Location) (time.Time, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return time.Time{}, fmt.Errorf("invalid Org timestamp %q", value)
+	}
+	t, err := time.ParseInLocation("2006-01-02 15:04", fields[0]+" "+fields[2], loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid Org timestamp %q", value)
+	}
+	return t, nil
+}
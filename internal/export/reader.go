@@ -0,0 +1,189 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/timer"
+)
+
+// ImportFields lists the session fields ReadCSV can fill, named like the
+// columns of the session CSV export. Only started_at is required.
+var ImportFields = []string{
+	"id", "started_at", "ended_at", "planned_seconds", "label", "preset",
+	"project", "tags", "end_status", "paused_count", "paused_seconds", "notes",
+}
+
+// ReadCSV parses sessions from CSV with a header row. By default each field
+// is read from the column of the same name, so the session CSV export reads
+// back as is; mapping maps fields to other column names. Times are RFC 3339
+// or "YYYY-MM-DD HH:MM[:SS]" in loc. Durations are whole seconds, Go
+// durations (25m) or HH:MM:SS. Without an end the session lasts its planned
+// duration; without an end status it is a manual log.
+func ReadCSV(r io.Reader, mapping map[string]string, loc *time.Location) ([]history.Session, error) {
+	for field := range mapping {
+		if !containsField(field) {
+			return nil, fmt.Errorf("unknown session field %q (expected %s)", field, strings.Join(ImportFields, ", "))
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columnIndex := map[string]int{}
+	for i, name := range header {
+		columnIndex[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	columns := map[string]int{}
+	for _, field := range ImportFields {
+		name := field
+		if mapped, ok := mapping[field]; ok {
+			name = mapped
+		}
+		i, ok := columnIndex[name]
+		if !ok {
+			if _, mapped := mapping[field]; mapped {
+				return nil, fmt.Errorf("CSV has no column %q (mapped to %s)", name, field)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["started_at"]; !ok {
+		return nil, fmt.Errorf("CSV has no started_at column (map one with started_at=<column>)")
+	}
+
+	var sessions []history.Session
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		s, err := csvSession(record, columns, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+func csvSession(record []string, columns map[string]int, loc *time.Location) (history.Session, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	s := history.Session{
+		ID:        field("id"),
+		Label:     field("label"),
+		Preset:    field("preset"),
+		Project:   field("project"),
+		EndStatus: field("end_status"),
+		Notes:     field("notes"),
+	}
+	if s.EndStatus == "" {
+		s.EndStatus = history.StatusManual
+	}
+	for _, tag := range strings.Split(field("tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			s.Tags = append(s.Tags, tag)
+		}
+	}
+
+	var err error
+	if s.StartedAt, err = parseCSVTime(field("started_at"), loc); err != nil {
+		return s, fmt.Errorf("invalid started_at: %w", err)
+	}
+	planned, err := parseCSVDuration(field("planned_seconds"))
+	if err != nil {
+		return s, fmt.Errorf("invalid planned_seconds: %w", err)
+	}
+	if value := field("ended_at"); value != "" {
+		if s.EndedAt, err = parseCSVTime(value, loc); err != nil {
+			return s, fmt.Errorf("invalid ended_at: %w", err)
+		}
+	} else {
+		if planned <= 0 {
+			return s, fmt.Errorf("session needs ended_at or planned_seconds")
+		}
+		s.EndedAt = s.StartedAt.Add(planned)
+	}
+	if planned <= 0 {
+		planned = s.EndedAt.Sub(s.StartedAt)
+	}
+	s.Duration = sessionDuration(planned)
+
+	paused, err := parseCSVDuration(field("paused_seconds"))
+	if err != nil {
+		return s, fmt.Errorf("invalid paused_seconds: %w", err)
+	}
+	s.PausedDuration = sessionDuration(paused)
+	if value := field("paused_count"); value != "" {
+		if s.PausedCount, err = strconv.Atoi(value); err != nil {
+			return s, fmt.Errorf("invalid paused_count %q", value)
+		}
+	}
+	return s, nil
+}
+
+func parseCSVTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{time.DateTime, "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not RFC 3339 or YYYY-MM-DD HH:MM[:SS]", value)
+}
+
+// parseCSVDuration parses whole seconds, a Go duration or HH:MM:SS; empty
+// is zero
+func parseCSVDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return d, nil
+	}
+	return parseClockDuration(value)
+}
+
+// sessionDuration formats d like the durations of recorded sessions. Go's
+// form is kept where timer.FormatDuration would drop the seconds of a
+// session over an hour.
+func sessionDuration(d time.Duration) string {
+	formatted := timer.FormatDuration(d)
+	if parsed, err := time.ParseDuration(formatted); err != nil || parsed != d {
+		return d.String()
+	}
+	return formatted
+}
+
+func containsField(field string) bool {
+	for _, f := range ImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,213 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+)
+
+// FormatTimewarrior is Timewarrior's data file format, one "inc" line per
+// interval as stored in ~/.timewarrior/data/YYYY-MM.data
+const FormatTimewarrior = "timew"
+
+// timewTimeLayout is Timewarrior's UTC timestamp
+const timewTimeLayout = "20060102T150405Z"
+
+// timewSessionWriter writes one interval per session. The label, project
+// and tags become Timewarrior tags in label shorthand (label, +project,
+// @tag) and the notes become the annotation.
+type timewSessionWriter struct {
+	w *bufio.Writer
+}
+
+func (w *timewSessionWriter) Write(s history.Session) error {
+	w.w.WriteString(TimewarriorLine(s))
+	w.w.WriteString("\n")
+	return w.w.Flush()
+}
+
+// Finish is a no-op; Timewarrior data holds intervals only
+func (w *timewSessionWriter) Finish(stats.Summary) error {
+	return w.w.Flush()
+}
+
+// TimewarriorLine formats a session as a Timewarrior interval
+func TimewarriorLine(s history.Session) string {
+	var b strings.Builder
+	b.WriteString("inc ")
+	b.WriteString(s.StartedAt.UTC().Format(timewTimeLayout))
+	b.WriteString(" - ")
+	b.WriteString(s.EndedAt.UTC().Format(timewTimeLayout))
+
+	var tags []string
+	if s.Label != "" {
+		tags = append(tags, s.Label)
+	}
+	if s.Project != "" {
+		tags = append(tags, "+"+s.Project)
+	}
+	for _, tag := range s.Tags {
+		tags = append(tags, "@"+tag)
+	}
+	if len(tags) == 0 && s.Notes == "" {
+		return b.String()
+	}
+
+	b.WriteString(" #")
+	for _, tag := range tags {
+		b.WriteString(" ")
+		b.WriteString(timewQuote(tag, false))
+	}
+	if s.Notes != "" {
+		if len(tags) > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("# ")
+		b.WriteString(timewQuote(s.Notes, true))
+	}
+	return b.String()
+}
+
+// ReadTimewarrior parses Timewarrior data lines. The first plain tag becomes
+// the label, +tags the project and @tags (and further plain tags) the tags.
+// Open intervals, without an end, are skipped. Sessions are returned as
+// manual logs without IDs.
+func ReadTimewarrior(r io.Reader) ([]history.Session, error) {
+	var sessions []history.Session
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		s, ok, err := parseTimewarriorLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if ok {
+			sessions = append(sessions, s)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read Timewarrior data: %w", err)
+	}
+	return sessions, nil
+}
+
+func parseTimewarriorLine(line string) (history.Session, bool, error) {
+	rest, ok := strings.CutPrefix(line, "inc ")
+	if !ok {
+		return history.Session{}, false, fmt.Errorf("expected an \"inc\" interval, got %q", line)
+	}
+	times, meta, _ := strings.Cut(rest, "#")
+	fields := strings.Fields(times)
+	if len(fields) == 1 {
+		// Still running
+		return history.Session{}, false, nil
+	}
+	if len(fields) != 3 || fields[1] != "-" {
+		return history.Session{}, false, fmt.Errorf("invalid interval %q", strings.TrimSpace(times))
+	}
+	start, err := time.Parse(timewTimeLayout, fields[0])
+	if err != nil {
+		return history.Session{}, false, fmt.Errorf("invalid start %q", fields[0])
+	}
+	end, err := time.Parse(timewTimeLayout, fields[2])
+	if err != nil {
+		return history.Session{}, false, fmt.Errorf("invalid end %q", fields[2])
+	}
+
+	s := importedSession(start, end)
+	tokens, err := timewTokens(meta)
+	if err != nil {
+		return history.Session{}, false, err
+	}
+	annotation := false
+	for _, token := range tokens {
+		switch {
+		case annotation:
+			s.Notes = token.text
+		case token.text == "#" && !token.quoted:
+			annotation = true
+		case strings.HasPrefix(token.text, "+") && len(token.text) > 1 && s.Project == "":
+			s.Project = token.text[1:]
+		case strings.HasPrefix(token.text, "@") && len(token.text) > 1:
+			s.Tags = append(s.Tags, token.text[1:])
+		case s.Label == "":
+			s.Label = token.text
+		default:
+			s.Tags = append(s.Tags, token.text)
+		}
+	}
+	return s, true, nil
+}
+
+type timewToken struct {
+	text   string
+	quoted bool
+}
+
+// timewTokens splits the tags and annotation of an interval on spaces,
+// honouring double quotes and backslash escapes
+func timewTokens(s string) ([]timewToken, error) {
+	var tokens []timewToken
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ':
+			i++
+		case s[i] == '"':
+			var b strings.Builder
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+					if s[i] == 'n' {
+						b.WriteByte('\n')
+						continue
+					}
+				}
+				b.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("unterminated quote in %q", s)
+			}
+			i++
+			tokens = append(tokens, timewToken{text: b.String(), quoted: true})
+		default:
+			start := i
+			for i < len(s) && s[i] != ' ' {
+				i++
+			}
+			tokens = append(tokens, timewToken{text: s[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+// timewQuote quotes a tag or annotation when Timewarrior would
+func timewQuote(s string, always bool) string {
+	if !always && s != "" && !strings.ContainsAny(s, " \"#\\\n") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// importedSession returns a manual session spanning [start, end), the form
+// of sessions read from other time trackers
+func importedSession(start, end time.Time) history.Session {
+	return history.Session{
+		StartedAt:      start,
+		EndedAt:        end,
+		Duration:       sessionDuration(end.Sub(start)),
+		EndStatus:      history.StatusManual,
+		PausedDuration: sessionDuration(0),
+	}
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+)
+
+// FormatToggl is the CSV layout of Toggl Track's time entry import
+const FormatToggl = "toggl"
+
+// togglColumns is the header Toggl Track expects; dates and times are local
+var togglColumns = []string{
+	"Description", "Project", "Tags", "Start date", "Start time", "End date", "End time", "Duration",
+}
+
+// togglSessionWriter writes one time entry per session. Toggl has no
+// presets, statuses or notes, so those are not exported.
+type togglSessionWriter struct {
+	w       *csv.Writer
+	loc     *time.Location
+	started bool
+}
+
+func (w *togglSessionWriter) begin() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	return w.w.Write(togglColumns)
+}
+
+func (w *togglSessionWriter) Write(s history.Session) error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+	start := s.StartedAt.In(w.loc)
+	end := s.EndedAt.In(w.loc)
+	elapsed := end.Sub(start).Truncate(time.Second)
+	return w.w.Write([]string{
+		s.Label,
+		s.Project,
+		strings.Join(s.Tags, ", "),
+		start.Format(time.DateOnly),
+		start.Format(time.TimeOnly),
+		end.Format(time.DateOnly),
+		end.Format(time.TimeOnly),
+		fmt.Sprintf("%02d:%02d:%02d", int(elapsed.Hours()), int(elapsed.Minutes())%60, int(elapsed.Seconds())%60),
+	})
+}
+
+// Finish writes the header if no session was written; the summary is not
+// part of Toggl output
+func (w *togglSessionWriter) Finish(stats.Summary) error {
+	if err := w.begin(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// ReadToggl parses Toggl Track CSV (its import layout or a detailed report
+// export), taking dates and times in loc. Columns are matched by header
+// name; the end defaults to start plus Duration when End columns are absent.
+// Entries are returned as manual sessions without IDs, oldest first.
+func ReadToggl(r io.Reader, loc *time.Location) ([]history.Session, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Toggl header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"start date", "start time"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("Toggl CSV has no %q column", required)
+		}
+	}
+
+	var sessions []history.Session
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Toggl CSV: %w", err)
+		}
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		start, err := time.ParseInLocation(time.DateTime, field("start date")+" "+field("start time"), loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid start: %w", line, err)
+		}
+		var end time.Time
+		if field("end date") != "" {
+			if end, err = time.ParseInLocation(time.DateTime, field("end date")+" "+field("end time"), loc); err != nil {
+				return nil, fmt.Errorf("line %d: invalid end: %w", line, err)
+			}
+		} else {
+			elapsed, err := parseClockDuration(field("duration"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			end = start.Add(elapsed)
+		}
+
+		s := importedSession(start, end)
+		s.Label = field("description")
+		s.Project = field("project")
+		for _, tag := range strings.Split(field("tags"), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				s.Tags = append(s.Tags, tag)
+			}
+		}
+		sessions = append(sessions, s)
+	}
+
+	sortOldestFirst(sessions)
+	return sessions, nil
+}
+
+// parseClockDuration parses an HH:MM:SS duration
+func parseClockDuration(value string) (time.Duration, error) {
+	var h, m, s int
+	if n, err := fmt.Sscanf(value, "%d:%d:%d", &h, &m, &s); err != nil || n != 3 {
+		return 0, fmt.Errorf("invalid duration %q (expected HH:MM:SS)", value)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second, nil
+}
+
+// sortOldestFirst orders imported sessions by start time, keeping the file
+// order of sessions starting together
+func sortOldestFirst(sessions []history.Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+}
@@ -0,0 +1,234 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip reads a sample file with read and writes the sessions back in
+// format, which must reproduce the sample byte for byte
+func roundTrip(t *testing.T, sample, format string, read func(io.Reader) ([]history.Session, error)) []history.Session {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", sample))
+	require.NoError(t, err)
+
+	sessions, err := read(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := newSessionWriter(&buf, format, time.UTC)
+	require.NoError(t, err)
+	for _, s := range sessions {
+		require.NoError(t, w.Write(s))
+	}
+	require.NoError(t, w.Finish(stats.Summary{}))
+	assert.Equal(t, string(data), buf.String())
+	return sessions
+}
+
+func utc(day, hour, minute int) time.Time {
+	return time.Date(2025, 1, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestTimewarrior_RoundTrip(t *testing.T) {
+	sessions := roundTrip(t, "sample.timew", FormatTimewarrior, ReadTimewarrior)
+	require.Len(t, sessions, 5)
+
+	assert.Equal(t, history.Session{
+		StartedAt:      utc(13, 8, 0),
+		EndedAt:        utc(13, 8, 25),
+		Duration:       "25m",
+		Label:          "Fix login",
+		Project:        "auth",
+		Tags:           []string{"bugfix", "backend"},
+		EndStatus:      history.StatusManual,
+		PausedDuration: "0s",
+	}, sessions[0])
+	assert.Equal(t, "Planning", sessions[1].Label)
+	assert.Equal(t, "Scoped the \"auth\" rewrite\nand split tickets", sessions[1].Notes)
+	assert.Equal(t, "", sessions[3].Label)
+	assert.Equal(t, "", sessions[4].Label)
+	assert.Equal(t, "Untagged, annotated", sessions[4].Notes)
+}
+
+func TestReadTimewarrior(t *testing.T) {
+	sessions, err := ReadTimewarrior(strings.NewReader(
+		"inc 20250113T080000Z # still running\n" +
+			"inc 20250113T070000Z - 20250113T073000Z # meeting standup @daily\n"))
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	// Extra plain tags are kept as tags
+	assert.Equal(t, "meeting", sessions[0].Label)
+	assert.Equal(t, []string{"standup", "daily"}, sessions[0].Tags)
+
+	_, err = ReadTimewarrior(strings.NewReader("inc 20250113T070000Z - tomorrow\n"))
+	assert.ErrorContains(t, err, "line 1")
+	_, err = ReadTimewarrior(strings.NewReader("\ninc 20250113T070000Z - 20250113T073000Z # \"open\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestOrg_RoundTrip(t *testing.T) {
+	read := func(r io.Reader) ([]history.Session, error) { return ReadOrg(r, time.UTC) }
+	sessions := roundTrip(t, "sample.org", FormatOrg, read)
+	require.Len(t, sessions, 5)
+
+	// Oldest first across headings
+	assert.Equal(t, "Fix login", sessions[0].Label)
+	assert.Equal(t, utc(13, 8, 0), sessions[0].StartedAt)
+	assert.Equal(t, "auth", sessions[0].Project)
+	assert.Equal(t, []string{"bugfix", "backend"}, sessions[0].Tags)
+	assert.Equal(t, "Planning", sessions[1].Label)
+	assert.Equal(t, utc(13, 10, 45), sessions[1].EndedAt)
+	assert.Equal(t, "", sessions[1].Project)
+	assert.Empty(t, sessions[1].Tags)
+	assert.Equal(t, "Fix login", sessions[2].Label)
+	assert.Equal(t, "auth", sessions[2].Project)
+	// The same label in another project gets its own heading
+	assert.Equal(t, "billing", sessions[3].Project)
+	assert.Empty(t, sessions[3].Tags)
+	assert.Equal(t, "", sessions[4].Label)
+	assert.Equal(t, utc(15, 0, 20), sessions[4].EndedAt)
+}
+
+func TestReadOrg_IgnoresOtherContent(t *testing.T) {
+	doc := "#+TITLE: Work\n" +
+		"* TODO Write docs :writing:\n" +
+		"Some text\n" +
+		":LOGBOOK:\n" +
+		"CLOCK: [2025-01-13 Mon 08:00]--[2025-01-13 Mon 08:30] =>  0:30\n" +
+		"CLOCK: [2025-01-13 Mon 09:00]\n" +
+		":END:\n"
+	sessions, err := ReadOrg(strings.NewReader(doc), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "TODO Write docs", sessions[0].Label)
+	assert.Equal(t, []string{"writing"}, sessions[0].Tags)
+	assert.Equal(t, 30*time.Minute, sessions[0].FocusDuration())
+}
+
+func TestToggl_RoundTrip(t *testing.T) {
+	read := func(r io.Reader) ([]history.Session, error) { return ReadToggl(r, time.UTC) }
+	sessions := roundTrip(t, "sample.toggl.csv", FormatToggl, read)
+	require.Len(t, sessions, 3)
+
+	assert.Equal(t, "Fix login", sessions[0].Label)
+	assert.Equal(t, "auth", sessions[0].Project)
+	assert.Equal(t, []string{"bugfix", "backend"}, sessions[0].Tags)
+	assert.Equal(t, "Planning, Q1", sessions[1].Label)
+	assert.Equal(t, 105*time.Minute+30*time.Second, sessions[1].FocusDuration())
+	// Seconds past the hour are kept rather than formatted away
+	assert.Equal(t, "1h45m30s", sessions[1].Duration)
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 20, 0, 0, time.UTC), sessions[2].EndedAt)
+}
+
+func TestReadToggl_DetailedReport(t *testing.T) {
+	// Toggl's detailed report has more columns, in another order
+	csv := "\ufeffUser,Email,Client,Project,Task,Description,Billable,Start date,Start time,Duration,Tags\n" +
+		"Sam,sam@example.com,,auth,,Fix login,No,2025-01-13,08:00:00,00:25:00,bugfix\n"
+	sessions, err := ReadToggl(strings.NewReader(csv), time.UTC)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Fix login", sessions[0].Label)
+	assert.Equal(t, utc(13, 8, 25), sessions[0].EndedAt)
+	assert.Equal(t, []string{"bugfix"}, sessions[0].Tags)
+
+	_, err = ReadToggl(strings.NewReader("Description,Duration\n"), time.UTC)
+	assert.Error(t, err)
+}
+
+func TestExporters_PreserveSessions(t *testing.T) {
+	sessions := testSessions()
+	sessions[0].Notes = "Root cause: cookie path"
+
+	var buf bytes.Buffer
+	w, err := newSessionWriter(&buf, FormatTimewarrior, time.UTC)
+	require.NoError(t, err)
+	for _, s := range sessions {
+		require.NoError(t, w.Write(s))
+	}
+	require.NoError(t, w.Finish(stats.Summary{}))
+
+	read, err := ReadTimewarrior(&buf)
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	for i, s := range read {
+		assert.True(t, sessions[i].StartedAt.Equal(s.StartedAt))
+		assert.Equal(t, sessions[i].Label, s.Label)
+		assert.Equal(t, sessions[i].Project, s.Project)
+		assert.Equal(t, sessions[i].Tags, s.Tags)
+		assert.Equal(t, sessions[i].Notes, s.Notes)
+	}
+}
+
+func TestReadCSV_SessionExport(t *testing.T) {
+	// The session CSV export reads back without a mapping
+	var buf bytes.Buffer
+	w, err := newSessionWriter(&buf, FormatCSV, time.UTC)
+	require.NoError(t, err)
+	for _, s := range testSessions() {
+		require.NoError(t, w.Write(s))
+	}
+	require.NoError(t, w.Finish(stats.Summary{}))
+
+	sessions, err := ReadCSV(&buf, nil, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for i, want := range testSessions() {
+		got := sessions[i]
+		assert.Equal(t, want.ID, got.ID)
+		assert.True(t, want.StartedAt.Equal(got.StartedAt))
+		assert.Equal(t, want.Label, got.Label)
+		assert.Equal(t, want.Preset, got.Preset)
+		assert.Equal(t, want.Project, got.Project)
+		assert.Equal(t, want.Tags, got.Tags)
+		assert.Equal(t, want.EndStatus, got.EndStatus)
+		assert.Equal(t, want.PausedCount, got.PausedCount)
+		assert.Equal(t, want.FocusDuration().Truncate(time.Second), got.FocusDuration().Truncate(time.Second))
+	}
+}
+
+func TestReadCSV_Mapping(t *testing.T) {
+	csv := "When,What,Length,Kind\n" +
+		"2025-01-13 08:00,Fix login,25m,\n" +
+		"2025-01-13 09:00,Planning,00:45:00,meeting\n"
+	mapping := map[string]string{"started_at": "When", "label": "What", "planned_seconds": "Length", "preset": "Kind"}
+	sessions, err := ReadCSV(strings.NewReader(csv), mapping, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, history.Session{
+		StartedAt:      utc(13, 8, 0),
+		EndedAt:        utc(13, 8, 25),
+		Duration:       "25m",
+		Label:          "Fix login",
+		EndStatus:      history.StatusManual,
+		PausedDuration: "0s",
+	}, sessions[0])
+	assert.Equal(t, utc(13, 9, 45), sessions[1].EndedAt)
+	assert.Equal(t, "meeting", sessions[1].Preset)
+
+	_, err = ReadCSV(strings.NewReader(csv), map[string]string{"started_at": "Begin"}, time.UTC)
+	assert.ErrorContains(t, err, `no column "Begin"`)
+	_, err = ReadCSV(strings.NewReader(csv), map[string]string{"colour": "Kind"}, time.UTC)
+	assert.ErrorContains(t, err, "unknown session field")
+	_, err = ReadCSV(strings.NewReader(csv), nil, time.UTC)
+	assert.ErrorContains(t, err, "no started_at column")
+	_, err = ReadCSV(strings.NewReader("started_at\n2025-01-13 08:00\n"), nil, time.UTC)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestWriteOrg_TagCharacters(t *testing.T) {
+	s := history.Session{StartedAt: utc(13, 8, 0), EndedAt: utc(13, 8, 25), Label: "Review", Tags: []string{"needs-review", "v2"}}
+	var buf bytes.Buffer
+	require.NoError(t, WriteOrg(&buf, []history.Session{s}, time.UTC))
+	assert.True(t, strings.HasPrefix(buf.String(), "* Review :needs_review:v2:\n"), buf.String())
+}
@@ -0,0 +1,194 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+)
+
+// SessionWriter streams sessions one at a time so large histories are never
+// buffered in full
+type SessionWriter interface {
+	// Write emits one session
+	Write(session history.Session) error
+	// Finish completes the output. JSON documents end with the summary;
+	// delimited formats hold sessions only and ignore it.
+	Finish(summary stats.Summary) error
+}
+
+// NewSessionWriter returns a SessionWriter for the json, csv, tsv, ics,
+// timew, org or toggl format. Org and Toggl output use local time.
+func NewSessionWriter(out io.Writer, format string) (SessionWriter, error) {
+	return newSessionWriter(out, format, time.Local)
+}
+
+func newSessionWriter(out io.Writer, format string, loc *time.Location) (SessionWriter, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonSessionWriter{out: out}, nil
+	case FormatCSV, FormatTSV:
+		return &delimitedSessionWriter{w: newDelimitedWriter(out, format)}, nil
+	case FormatICS:
+		return &icsSessionWriter{w: bufio.NewWriter(out)}, nil
+	case FormatTimewarrior:
+		return &timewSessionWriter{w: bufio.NewWriter(out)}, nil
+	case FormatOrg:
+		return &orgSessionWriter{out: out, loc: loc}, nil
+	case FormatToggl:
+		return &togglSessionWriter{w: csv.NewWriter(out), loc: loc}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// WriteTotals writes grouped totals in the json, csv or tsv format
+func WriteTotals(out io.Writer, format string, groupBy string, totals []history.Total) error {
+	records := make([]TotalRecord, 0, len(totals))
+	for _, t := range totals {
+		records = append(records, NewTotalRecord(t))
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(struct {
+			SchemaVersion int           `json:"schema_version"`
+			GroupBy       string        `json:"group_by"`
+			Totals        []TotalRecord `json:"totals"`
+		}{SchemaVersion, groupBy, records}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal totals: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	case FormatCSV, FormatTSV:
+		w := newDelimitedWriter(out, format)
+		if err := w.Write(totalColumns); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := w.Write(r.fields()); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// WriteBuckets writes per-period totals in the json, csv or tsv format
+func WriteBuckets(out io.Writer, format string, by string, groupBy string, buckets []stats.Bucket) error {
+	records := make([]BucketRecord, 0, len(buckets))
+	for _, b := range buckets {
+		records = append(records, NewBucketRecord(b))
+	}
+
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(struct {
+			SchemaVersion int            `json:"schema_version"`
+			By            string         `json:"by"`
+			GroupBy       string         `json:"group_by"`
+			Buckets       []BucketRecord `json:"buckets"`
+		}{SchemaVersion, by, groupBy, records}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal buckets: %w", err)
+		}
+		_, err = fmt.Fprintf(out, "%s\n", data)
+		return err
+	case FormatCSV, FormatTSV:
+		w := newDelimitedWriter(out, format)
+		if err := w.Write(bucketColumns); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := w.WriteAll(r.rows()); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+func newDelimitedWriter(out io.Writer, format string) *csv.Writer {
+	w := csv.NewWriter(out)
+	if format == FormatTSV {
+		w.Comma = '\t'
+	}
+	return w
+}
+
+// jsonSessionWriter writes {"schema_version", "sessions", "summary"} with one
+// session object per line
+type jsonSessionWriter struct {
+	out     io.Writer
+	written int
+}
+
+func (w *jsonSessionWriter) Write(session history.Session) error {
+	data, err := json.Marshal(NewSessionRecord(session))
+	if err != nil {
+		return fmt.Errorf("failed to marshal session %s: %w", session.ID, err)
+	}
+
+	prefix := ",\n    "
+	if w.written == 0 {
+		prefix = fmt.Sprintf("{\n  \"schema_version\": %d,\n  \"sessions\": [\n    ", SchemaVersion)
+	}
+	w.written++
+	_, err = fmt.Fprintf(w.out, "%s%s", prefix, data)
+	return err
+}
+
+func (w *jsonSessionWriter) Finish(summary stats.Summary) error {
+	data, err := json.MarshalIndent(NewSummaryRecord(summary), "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary: %w", err)
+	}
+
+	if w.written == 0 {
+		_, err = fmt.Fprintf(w.out, "{\n  \"schema_version\": %d,\n  \"sessions\": [],\n  \"summary\": %s\n}\n", SchemaVersion, data)
+		return err
+	}
+	_, err = fmt.Fprintf(w.out, "\n  ],\n  \"summary\": %s\n}\n", data)
+	return err
+}
+
+// delimitedSessionWriter writes a header row followed by one row per session
+type delimitedSessionWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *delimitedSessionWriter) Write(session history.Session) error {
+	if err := w.header(); err != nil {
+		return err
+	}
+	return w.w.Write(NewSessionRecord(session).fields())
+}
+
+func (w *delimitedSessionWriter) Finish(stats.Summary) error {
+	if err := w.header(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *delimitedSessionWriter) header() error {
+	if w.headerWritten {
+		return nil
+	}
+	w.headerWritten = true
+	return w.w.Write(sessionColumns)
+}
@@ -0,0 +1,513 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/encryption"
+)
+
+// ArchiveDirName is the directory inside the state directory holding the
+// yearly archives written by Compact
+const ArchiveDirName = "archive"
+
+// Archive holds one year of sessions compacted out of the history: the
+// sessions themselves, unless only rollups were kept, and a rollup of each day
+type Archive struct {
+	Version  string           `json:"version"`
+	Year     int              `json:"year"`
+	Sessions []Session        `json:"sessions,omitempty"`
+	Days     []DayRollup      `json:"days"`
+	RolledUp []ArchivedRecord `json:"rolled_up,omitempty"` // Sessions kept only in Days
+}
+
+// ArchivedRecord identifies a session record counted into a rollup, so
+// compacting or merging it again does not count it twice
+type ArchivedRecord struct {
+	ID        string    `json:"id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at"`
+	EndStatus string    `json:"end_status"`
+}
+
+// archivedRecord returns the identity of record s
+func archivedRecord(s Session) ArchivedRecord {
+	return ArchivedRecord{ID: s.ID, StartedAt: s.StartedAt, EndedAt: s.EndedAt, EndStatus: s.EndStatus}
+}
+
+// session returns a session carrying only the record's identity
+func (r ArchivedRecord) session() Session {
+	return Session{ID: r.ID, StartedAt: r.StartedAt, EndedAt: r.EndedAt, EndStatus: r.EndStatus}
+}
+
+// DayRollup aggregates the sessions of one day that share a label, project,
+// tags, preset and end status
+type DayRollup struct {
+	Date          string    `json:"date"` // YYYY-MM-DD in local time
+	Label         string    `json:"label"`
+	Private       bool      `json:"private,omitempty"`
+	Project       string    `json:"project,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	Preset        string    `json:"preset,omitempty"`
+	EndStatus     string    `json:"end_status"`
+	Sessions      int       `json:"sessions"`
+	FocusSeconds  int64     `json:"focus_seconds"`
+	PausedCount   int       `json:"paused_count"`
+	PausedSeconds int64     `json:"paused_seconds"`
+	FirstStart    time.Time `json:"first_start"`
+}
+
+// CompactResult describes what a compaction moved out of the history
+type CompactResult struct {
+	Sessions int   // Session records moved to archives
+	Days     int   // Days they were recorded on
+	Years    []int // Archives written
+}
+
+// ArchivePath returns the archive file for year inside dir
+func ArchivePath(dir string, year int) string {
+	return filepath.Join(dir, fmt.Sprintf("history-%d.json", year))
+}
+
+// RetentionCutoff returns the start of the month keepMonths months before
+// the month of now. Sessions started earlier are compacted.
+func RetentionCutoff(now time.Time, keepMonths int) time.Time {
+	return time.Date(now.Year(), now.Month()-time.Month(keepMonths), 1, 0, 0, 0, 0, now.Location())
+}
+
+// Compact moves the sessions started before cutoff from store into yearly
+// archives in dir and rolls them up by day. With rollupOnly set only the
+// rollups are kept, except for days an archive already holds sessions of;
+// days already kept only as rollups stay that way in either mode.
+// Archives are written before the store is, so an interrupted compaction
+// leaves sessions in both places and running it again completes it without
+// counting them twice. With dryRun set nothing is written.
+func Compact(store Store, dir string, cutoff time.Time, rollupOnly, dryRun bool) (*CompactResult, error) {
+	if dryRun {
+		h, err := store.Load()
+		if err != nil {
+			return nil, err
+		}
+		_, result, err := compactInto(h, dir, cutoff, rollupOnly)
+		return result, err
+	}
+
+	var result *CompactResult
+	err := store.Update(func(h *History) error {
+		archives, r, err := compactInto(h, dir, cutoff, rollupOnly)
+		if err != nil {
+			return err
+		}
+		for _, year := range r.Years {
+			if err := saveArchive(archives[year], ArchivePath(dir, year)); err != nil {
+				return err
+			}
+		}
+		result = r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// compactInto removes the sessions started before cutoff from h and returns
+// the updated archives they belong in, keyed by year
+func compactInto(h *History, dir string, cutoff time.Time, rollupOnly bool) (map[int]*Archive, *CompactResult, error) {
+	result := &CompactResult{}
+	byYear := map[int][]Session{}
+	days := map[string]bool{}
+	kept := h.Sessions[:0:0]
+	for _, s := range h.Sessions {
+		if !s.StartedAt.Before(cutoff) {
+			kept = append(kept, s)
+			continue
+		}
+		local := s.StartedAt.Local()
+		byYear[local.Year()] = append(byYear[local.Year()], s)
+		days[local.Format(time.DateOnly)] = true
+		result.Sessions++
+	}
+	result.Days = len(days)
+
+	archives := map[int]*Archive{}
+	for year, old := range byYear {
+		a, err := LoadArchive(ArchivePath(dir, year))
+		if err != nil {
+			return nil, nil, err
+		}
+		a.Year = year
+
+		var detailed, rolled []Session
+		detailDays := a.detailDays()
+		rolledDays := map[string]bool{}
+		for _, r := range a.Days {
+			rolledDays[r.Date] = !detailDays[r.Date]
+		}
+		rolledUp := a.rolledUp()
+		for _, s := range old {
+			day := s.StartedAt.Local().Format(time.DateOnly)
+			if detailDays[day] || (!rollupOnly && !rolledDays[day]) {
+				detailed = append(detailed, s)
+				continue
+			}
+			// Already counted by a compaction that did not finish
+			if rolledUp[recordKey(s)] {
+				continue
+			}
+			rolled = append(rolled, s)
+			a.RolledUp = append(a.RolledUp, archivedRecord(s))
+		}
+		a.Sessions = mergeRecords(a.Sessions, detailed)
+
+		// Rollups of days with sessions are recomputed from them
+		detailDays = a.detailDays()
+		var rollups []DayRollup
+		for _, r := range a.Days {
+			if !detailDays[r.Date] {
+				rollups = append(rollups, r)
+			}
+		}
+		a.Days = addRollups(addRollups(rollups, a.Sessions), rolled)
+
+		archives[year] = a
+		result.Years = append(result.Years, year)
+	}
+	sort.Ints(result.Years)
+
+	h.Sessions = kept
+	return archives, result, nil
+}
+
+// mergeRecords adds the records of sessions not in records yet, keeping
+// them ordered by start time
+func mergeRecords(records, sessions []Session) []Session {
+	seen := map[string]bool{}
+	for _, s := range records {
+		seen[recordKey(s)] = true
+	}
+	for _, s := range sessions {
+		if !seen[recordKey(s)] {
+			seen[recordKey(s)] = true
+			records = append(records, s)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartedAt.Before(records[j].StartedAt)
+	})
+	return records
+}
+
+// recordKey identifies one record of a session. The records of a resumed
+// session share an ID and a start, so the end and end status tell them
+// apart.
+func recordKey(s Session) string {
+	return fmt.Sprintf("%s\x00%d\x00%d\x00%s", s.ID, s.StartedAt.UnixNano(), s.EndedAt.UnixNano(), s.EndStatus)
+}
+
+// addRollups counts sessions into the matching rollups, adding rollups as
+// needed, and returns them ordered by date
+func addRollups(rollups []DayRollup, sessions []Session) []DayRollup {
+	key := func(date, label string, private bool, project string, tags []string, preset, status string) string {
+		return strings.Join([]string{date, label, strconv.FormatBool(private), project, strings.Join(tags, ","), preset, status}, "\x00")
+	}
+	index := map[string]int{}
+	for i, r := range rollups {
+		index[key(r.Date, r.Label, r.Private, r.Project, r.Tags, r.Preset, r.EndStatus)] = i
+	}
+
+	for _, s := range sessions {
+		date := s.StartedAt.Local().Format(time.DateOnly)
+		k := key(date, s.Label, s.Private, s.Project, s.Tags, s.Preset, s.EndStatus)
+		i, ok := index[k]
+		if !ok {
+			i = len(rollups)
+			index[k] = i
+			rollups = append(rollups, DayRollup{
+				Date: date, Label: s.Label, Private: s.Private, Project: s.Project, Tags: s.Tags,
+				Preset: s.Preset, EndStatus: s.EndStatus, FirstStart: s.StartedAt,
+			})
+		}
+		r := &rollups[i]
+		r.Sessions++
+		r.FocusSeconds += int64(s.FocusDuration() / time.Second)
+		r.PausedCount += s.PausedCount
+		if paused, err := time.ParseDuration(s.PausedDuration); err == nil {
+			r.PausedSeconds += int64(paused / time.Second)
+		}
+		if s.StartedAt.Before(r.FirstStart) {
+			r.FirstStart = s.StartedAt
+		}
+	}
+
+	sort.SliceStable(rollups, func(i, j int) bool {
+		if rollups[i].Date != rollups[j].Date {
+			return rollups[i].Date < rollups[j].Date
+		}
+		return rollups[i].FirstStart.Before(rollups[j].FirstStart)
+	})
+	return rollups
+}
+
+// detailDays returns the dates the archive holds sessions for
+func (a *Archive) detailDays() map[string]bool {
+	days := map[string]bool{}
+	for _, s := range a.Sessions {
+		days[s.StartedAt.Local().Format(time.DateOnly)] = true
+	}
+	return days
+}
+
+// rolledUp returns the record keys of the sessions counted into rollups
+func (a *Archive) rolledUp() map[string]bool {
+	keys := map[string]bool{}
+	for _, r := range a.RolledUp {
+		keys[recordKey(r.session())] = true
+	}
+	return keys
+}
+
+// Expand returns the archived sessions, standing in for the days kept only
+// as rollups with sessions that add up to each rollup: its focus and pauses
+// are split evenly across back-to-back sessions from its first start
+func (a *Archive) Expand() []Session {
+	detailDays := a.detailDays()
+	sessions := append([]Session(nil), a.Sessions...)
+	for i, r := range a.Days {
+		if detailDays[r.Date] || r.Sessions <= 0 {
+			continue
+		}
+		n := int64(r.Sessions)
+		start := r.FirstStart
+		for k := int64(0); k < n; k++ {
+			focus := r.FocusSeconds / n
+			paused := r.PausedSeconds / n
+			if k == n-1 {
+				focus = r.FocusSeconds - focus*(n-1)
+				paused = r.PausedSeconds - paused*(n-1)
+			}
+			pauses := r.PausedCount / r.Sessions
+			if int(k) < r.PausedCount%r.Sessions {
+				pauses++
+			}
+			length := time.Duration(focus+paused) * time.Second
+			sessions = append(sessions, Session{
+				ID:             fmt.Sprintf("rollup-%d-%d-%d", a.Year, i, k),
+				StartedAt:      start,
+				EndedAt:        start.Add(length),
+				Duration:       max(time.Duration(focus)*time.Second, time.Second).String(),
+				Preset:         r.Preset,
+				Label:          r.Label,
+				Private:        r.Private,
+				Project:        r.Project,
+				Tags:           r.Tags,
+				EndStatus:      r.EndStatus,
+				PausedCount:    pauses,
+				PausedDuration: (time.Duration(paused) * time.Second).String(),
+			})
+			start = start.Add(length)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+	return sessions
+}
+
+// LoadArchive reads the archive at path; a missing archive is empty
+func LoadArchive(path string) (*Archive, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Archive{Version: "1.0", Days: []DayRollup{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if data, err = encryption.Decrypt(data); err != nil {
+		return nil, fmt.Errorf("failed to decrypt archive %s: %w", path, err)
+	}
+	var a Archive
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse archive %s: %w", path, err)
+	}
+	return &a, nil
+}
+
+func saveArchive(a *Archive, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive: %w", err)
+	}
+	if err := encryption.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+	return nil
+}
+
+// ArchiveYears returns the years dir holds archives for, oldest first
+func ArchiveYears(dir string) ([]int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "history-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archives: %w", err)
+	}
+	var years []int
+	for _, path := range paths {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "history-"), ".json")
+		if year, err := strconv.Atoi(name); err == nil {
+			years = append(years, year)
+		}
+	}
+	sort.Ints(years)
+	return years, nil
+}
+
+// archivedRecords returns the records the archives in dir hold: their
+// sessions, and the identity of each rolled-up one
+func archivedRecords(dir string) ([]Session, error) {
+	years, err := ArchiveYears(dir)
+	if err != nil {
+		return nil, err
+	}
+	var records []Session
+	for _, year := range years {
+		a, err := LoadArchive(ArchivePath(dir, year))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, a.Sessions...)
+		for _, r := range a.RolledUp {
+			records = append(records, r.session())
+		}
+	}
+	return records, nil
+}
+
+// ArchivedStore reads a store together with the yearly archives Compact
+// moved its old sessions to. Archives are only read for ranges reaching
+// into an archived year; writes go to the store alone.
+type ArchivedStore struct {
+	Store
+	dir string
+}
+
+// NewArchivedStore wraps store to also read the archives in dir
+func NewArchivedStore(store Store, dir string) *ArchivedStore {
+	return &ArchivedStore{Store: store, dir: dir}
+}
+
+// archived returns the archived sessions started in [from, to); zero
+// bounds are open
+func (s *ArchivedStore) archived(from, to time.Time) ([]Session, error) {
+	years, err := ArchiveYears(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var sessions []Session
+	for _, year := range years {
+		start := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+		end := start.AddDate(1, 0, 0)
+		if (!to.IsZero() && !start.Before(to)) || (!from.IsZero() && !from.Before(end)) {
+			continue
+		}
+		a, err := LoadArchive(ArchivePath(s.dir, year))
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, filterBetween(&History{Sessions: a.Expand()}, from, to)...)
+	}
+	return sessions, nil
+}
+
+// Load returns the archived and stored sessions, oldest first
+func (s *ArchivedStore) Load() (*History, error) {
+	sessions, err := s.Between(time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return &History{Version: "1.0", Sessions: sessions}, nil
+}
+
+// Between returns archived and stored sessions started in [from, to)
+func (s *ArchivedStore) Between(from, to time.Time) ([]Session, error) {
+	sessions, err := s.archived(from, to)
+	if err != nil {
+		return nil, err
+	}
+	live, err := s.Store.Between(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return live, nil
+	}
+	sessions = append(sessions, live...)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+	})
+	return sessions, nil
+}
+
+// Query runs q against the store, in memory over both when the range
+// reaches into an archived year
+func (s *ArchivedStore) Query(q Query) (*Page, error) {
+	archived, err := s.archived(q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return s.Store.Query(q)
+	}
+	live, err := s.Store.Between(q.From, q.To)
+	if err != nil {
+		return nil, err
+	}
+	return q.Apply(append(archived, live...))
+}
+
+// Walk streams from the store unless the range reaches into an archived year
+func (s *ArchivedStore) Walk(q Query, fn func(Session) error) error {
+	archived, err := s.archived(q.From, q.To)
+	if err != nil {
+		return err
+	}
+	if len(archived) == 0 {
+		return Walk(s.Store, q, fn)
+	}
+	page, err := s.Query(q)
+	if err != nil {
+		return err
+	}
+	for _, session := range page.Sessions {
+		if err := fn(session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Totals aggregates in the store unless the range reaches into an archived
+// year
+func (s *ArchivedStore) Totals(from, to time.Time, field string) ([]Total, error) {
+	archived, err := s.archived(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(archived) == 0 {
+		return Totals(s.Store, from, to, field)
+	}
+	sessions, err := s.Between(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return SumBy(sessions, field)
+}
@@ -0,0 +1,259 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sessionOn returns a 25m session on the given local date and hour
+func sessionOn(id string, year int, month time.Month, day, hour int, label string) Session {
+	start := time.Date(year, month, day, hour, 0, 0, 0, time.Local)
+	return Session{
+		ID:             id,
+		StartedAt:      start,
+		EndedAt:        start.Add(30 * time.Minute),
+		Duration:       "25m",
+		Label:          label,
+		EndStatus:      StatusCompleted,
+		PausedCount:    1,
+		PausedDuration: "5m",
+	}
+}
+
+// resumedOn returns the two records of a sessionOn interrupted after ten
+// minutes and resumed: like real ones they share the ID and start
+func resumedOn(id string, year int, month time.Month, day, hour int, label string) (Session, Session) {
+	completed := sessionOn(id, year, month, day, hour, label)
+	interrupted := completed
+	interrupted.EndedAt = completed.StartedAt.Add(10 * time.Minute)
+	interrupted.EndStatus = StatusInterrupted
+	interrupted.PausedCount, interrupted.PausedDuration = 0, "0s"
+	return interrupted, completed
+}
+
+func TestRetentionCutoff(t *testing.T) {
+	now := time.Date(2026, 3, 18, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), RetentionCutoff(now, 12))
+	assert.Equal(t, time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), RetentionCutoff(now, 3))
+}
+
+func TestCompact(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := Open(backend, dir)
+			require.NoError(t, err)
+			t.Cleanup(func() { store.Close() })
+			archiveDir := filepath.Join(dir, ArchiveDirName)
+
+			for _, s := range []Session{
+				sessionOn("a", 2024, time.December, 30, 9, "A"),
+				sessionOn("b", 2025, time.January, 10, 9, "B"),
+				sessionOn("c", 2025, time.January, 10, 10, "B"),
+				sessionOn("d", 2025, time.March, 2, 9, "D"),
+			} {
+				require.NoError(t, store.Append(s))
+			}
+			cutoff := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)
+
+			result, err := Compact(store, archiveDir, cutoff, false, true)
+			require.NoError(t, err)
+			assert.Equal(t, &CompactResult{Sessions: 3, Days: 2, Years: []int{2024, 2025}}, result)
+			h, err := store.Load()
+			require.NoError(t, err)
+			assert.Len(t, h.Sessions, 4, "dry run leaves the history unchanged")
+
+			_, err = Compact(store, archiveDir, cutoff, false, false)
+			require.NoError(t, err)
+			h, err = store.Load()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"d"}, ids(h.Sessions))
+
+			a, err := LoadArchive(ArchivePath(archiveDir, 2025))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b", "c"}, ids(a.Sessions))
+			require.Len(t, a.Days, 1)
+			assert.Equal(t, DayRollup{
+				Date: "2025-01-10", Label: "B", EndStatus: StatusCompleted, Sessions: 2,
+				FocusSeconds: 3000, PausedCount: 2, PausedSeconds: 600,
+				FirstStart: a.Sessions[0].StartedAt,
+			}, a.Days[0])
+
+			// Compacting again finds nothing left to archive
+			result, err = Compact(store, archiveDir, cutoff, false, false)
+			require.NoError(t, err)
+			assert.Zero(t, result.Sessions)
+		})
+	}
+}
+
+func TestCompact_ResumedSession(t *testing.T) {
+	for _, rollupOnly := range []bool{false, true} {
+		dir := t.TempDir()
+		store := NewJSONStore(filepath.Join(dir, "history.json"))
+		archiveDir := filepath.Join(dir, ArchiveDirName)
+		interrupted, completed := resumedOn("r", 2025, time.January, 10, 9, "R")
+		require.NoError(t, store.Append(interrupted))
+		require.NoError(t, store.Append(completed))
+
+		result, err := Compact(store, archiveDir, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local), rollupOnly, false)
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.Sessions)
+
+		a, err := LoadArchive(ArchivePath(archiveDir, 2025))
+		require.NoError(t, err)
+		if rollupOnly {
+			assert.Len(t, a.RolledUp, 2, "both records are rolled up")
+		} else {
+			require.Len(t, a.Sessions, 2, "both records are archived")
+			assert.Equal(t, StatusInterrupted, a.Sessions[0].EndStatus)
+			assert.Equal(t, StatusCompleted, a.Sessions[1].EndStatus)
+		}
+		var sessions int
+		for _, r := range a.Days {
+			sessions += r.Sessions
+		}
+		assert.Equal(t, 2, sessions, "rollup only: %v", rollupOnly)
+	}
+}
+
+func TestCompact_RollupOnly(t *testing.T) {
+	dir := t.TempDir()
+	store := NewJSONStore(filepath.Join(dir, "history.json"))
+	archiveDir := filepath.Join(dir, ArchiveDirName)
+	b := sessionOn("b", 2025, time.January, 10, 9, "B")
+	c := sessionOn("c", 2025, time.January, 10, 10, "B")
+	c.PausedCount = 2
+	require.NoError(t, store.Append(b))
+	require.NoError(t, store.Append(c))
+
+	_, err := Compact(store, archiveDir, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local), true, false)
+	require.NoError(t, err)
+	a, err := LoadArchive(ArchivePath(archiveDir, 2025))
+	require.NoError(t, err)
+	assert.Empty(t, a.Sessions)
+	require.Len(t, a.Days, 1)
+
+	// Rolled up days stand in for their sessions with the same totals
+	expanded := a.Expand()
+	require.Len(t, expanded, 2)
+	var focus time.Duration
+	var pauses int
+	for _, s := range expanded {
+		require.NoError(t, s.Validate())
+		assert.Equal(t, "B", s.Label)
+		focus += s.FocusDuration()
+		pauses += s.PausedCount
+	}
+	assert.Equal(t, 50*time.Minute, focus)
+	assert.Equal(t, 3, pauses)
+	assert.True(t, b.StartedAt.Equal(expanded[0].StartedAt))
+	assert.False(t, expanded[0].Overlaps(expanded[1]))
+}
+
+// interruptedStore runs updates but fails before saving them, like a
+// compaction killed after writing its archives
+type interruptedStore struct {
+	Store
+}
+
+func (s interruptedStore) Update(fn func(h *History) error) error {
+	h, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(h); err != nil {
+		return err
+	}
+	return errors.New("interrupted")
+}
+
+func TestCompact_RerunAfterInterruption(t *testing.T) {
+	dir := t.TempDir()
+	store := NewJSONStore(filepath.Join(dir, "history.json"))
+	archiveDir := filepath.Join(dir, ArchiveDirName)
+	// The records of a resumed session share an ID and start
+	interrupted, completed := resumedOn("b", 2025, time.January, 10, 9, "B")
+	completed.EndStatus = StatusInterrupted
+	completed.EndedAt = completed.StartedAt.Add(20 * time.Minute)
+	for _, s := range []Session{interrupted, completed, sessionOn("c", 2025, time.January, 10, 10, "B")} {
+		require.NoError(t, store.Append(s))
+	}
+	cutoff := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)
+
+	_, err := Compact(interruptedStore{store}, archiveDir, cutoff, true, false)
+	require.Error(t, err)
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 3, "the history still holds the sessions")
+
+	_, err = Compact(store, archiveDir, cutoff, true, false)
+	require.NoError(t, err)
+	a, err := LoadArchive(ArchivePath(archiveDir, 2025))
+	require.NoError(t, err)
+	require.Len(t, a.Days, 2)
+	assert.Equal(t, 2, a.Days[0].Sessions, "the rerun does not count the sessions again")
+	assert.Equal(t, 1, a.Days[1].Sessions)
+	assert.Len(t, a.RolledUp, 3)
+	h, err = store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, h.Sessions)
+
+	// A day kept only as rollups stays one when later sessions of it are
+	// compacted with details
+	require.NoError(t, store.Append(sessionOn("e", 2025, time.January, 10, 11, "B")))
+	_, err = Compact(store, archiveDir, cutoff, false, false)
+	require.NoError(t, err)
+	a, err = LoadArchive(ArchivePath(archiveDir, 2025))
+	require.NoError(t, err)
+	assert.Empty(t, a.Sessions)
+	require.Len(t, a.Days, 2)
+	assert.Equal(t, 2, a.Days[1].Sessions)
+}
+
+func TestArchivedStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewJSONStore(filepath.Join(dir, "history.json"))
+	archiveDir := filepath.Join(dir, ArchiveDirName)
+	for _, s := range []Session{
+		sessionOn("a", 2024, time.December, 30, 9, "A"),
+		sessionOn("b", 2025, time.January, 10, 9, "B"),
+		sessionOn("d", 2025, time.March, 2, 9, "D"),
+	} {
+		require.NoError(t, store.Append(s))
+	}
+	_, err := Compact(store, archiveDir, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local), false, false)
+	require.NoError(t, err)
+
+	archived := NewArchivedStore(store, archiveDir)
+	h, err := archived.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "d"}, ids(h.Sessions))
+
+	sessions, err := archived.Between(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local), time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "d"}, ids(sessions))
+
+	page, err := archived.Query(Query{Order: NewestFirst, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"d", "b"}, ids(page.Sessions))
+	page, err = archived.Query(Query{Order: NewestFirst, Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(page.Sessions))
+
+	totals, err := Totals(archived, time.Time{}, time.Time{}, GroupByLabel)
+	require.NoError(t, err)
+	assert.Len(t, totals, 3)
+
+	var walked []string
+	require.NoError(t, Walk(archived, Query{From: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)}, func(s Session) error {
+		walked = append(walked, s.ID)
+		return nil
+	}))
+	assert.Equal(t, []string{"d"}, walked, "recent ranges do not read the archives")
+}
@@ -0,0 +1,154 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pomodux/pomodux/internal/encryption"
+)
+
+// AuditFileName is the audit log file name inside the state directory
+const AuditFileName = "history_audit.jsonl"
+
+// ErrNothingToUndo is returned by Undo when no modification is left to revert
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// Audit actions
+const (
+	ActionAdd    = "add"
+	ActionEdit   = "edit"
+	ActionDelete = "delete"
+	ActionUndo   = "undo"
+)
+
+// AuditEntry records one modification of the history.
+// Before is nil for additions, After is nil for deletions.
+type AuditEntry struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Action    string    `json:"action"`
+	SessionID string    `json:"session_id"`
+	Before    *Session  `json:"before,omitempty"`
+	After     *Session  `json:"after,omitempty"`
+	Removed   []Session `json:"removed,omitempty"` // Every record deleted when a session had several
+	Undoes    string    `json:"undoes,omitempty"`  // ID of the entry reverted by an undo
+}
+
+// removedRecords returns the records a deletion removed
+func (e AuditEntry) removedRecords() []Session {
+	if len(e.Removed) > 0 {
+		return e.Removed
+	}
+	return []Session{*e.Before}
+}
+
+// AuditLog is an append-only JSON Lines log of history modifications
+type AuditLog struct {
+	path string
+}
+
+// NewAuditLog creates an audit log backed by the file at path
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record appends an entry, filling in its ID and time
+func (a *AuditLog) Record(entry AuditEntry) (AuditEntry, error) {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return entry, fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if line, err = encryption.SealLine(line); err != nil {
+		return entry, fmt.Errorf("failed to encrypt audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	err = withLock(a.path, func() error {
+		f, err := os.OpenFile(a.path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		end, err := truncatePartialLine(f)
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteAt(line, end); err != nil {
+			return err
+		}
+		return f.Sync()
+	})
+	if err != nil {
+		return entry, fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Entries returns all recorded entries, oldest first.
+// A partial final line left by a crash is ignored.
+func (a *AuditLog) Entries() ([]AuditEntry, error) {
+	f, err := os.Open(a.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	reader := bufio.NewReader(f)
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		if line, err = encryption.OpenLine(line); err != nil {
+			return nil, fmt.Errorf("failed to decrypt audit log line %d: %w", lineNo, err)
+		}
+		var entry AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", lineNo, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// lastUndoable returns the most recent entry that is not an undo and has not
+// been undone yet
+func (a *AuditLog) lastUndoable() (*AuditEntry, error) {
+	entries, err := a.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	undone := map[string]bool{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Action == ActionUndo {
+			undone[entry.Undoes] = true
+			continue
+		}
+		if !undone[entry.ID] {
+			return &entry, nil
+		}
+	}
+
+	return nil, ErrNothingToUndo
+}
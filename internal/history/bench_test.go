@@ -0,0 +1,86 @@
+package history
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const benchSessions = 100000
+
+// benchHistory builds a history with n sessions
+func benchHistory(n int) *History {
+	h := &History{Version: "1.0", Sessions: make([]Session, 0, n)}
+	start := time.Date(2020, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		s := testSession(fmt.Sprintf("bench-%d", i))
+		s.StartedAt = start.Add(time.Duration(i) * 30 * time.Minute)
+		s.EndedAt = s.StartedAt.Add(25 * time.Minute)
+		h.Sessions = append(h.Sessions, s)
+	}
+	return h
+}
+
+// BenchmarkJSON_Append100k measures one session end with the JSON document backend
+func BenchmarkJSON_Append100k(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "history.json")
+	if err := Save(benchHistory(benchSessions), path); err != nil {
+		b.Fatal(err)
+	}
+	store := NewJSONStore(path)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.Append(testSession(fmt.Sprintf("new-%d", i))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSONL_Append100k measures one session end with the JSONL backend
+func BenchmarkJSONL_Append100k(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "history.jsonl")
+	if err := writeJSONL(benchHistory(benchSessions), path); err != nil {
+		b.Fatal(err)
+	}
+	store := NewJSONLStore(path)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.Append(testSession(fmt.Sprintf("new-%d", i))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSON_Load100k measures reading the full JSON document
+func BenchmarkJSON_Load100k(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "history.json")
+	if err := Save(benchHistory(benchSessions), path); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Load(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkJSONL_Load100k measures reading the full JSONL file
+func BenchmarkJSONL_Load100k(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "history.jsonl")
+	if err := writeJSONL(benchHistory(benchSessions), path); err != nil {
+		b.Fatal(err)
+	}
+	store := NewJSONLStore(path)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.Load(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session end statuses
+const (
+	StatusCompleted   = "completed"
+	StatusStopped     = "stopped"
+	StatusCancelled   = "cancelled"
+	StatusInterrupted = "interrupted"
+	StatusManual      = "manual" // Logged after the fact without running a timer
+)
+
+// EndStatuses lists every valid end status
+var EndStatuses = []string{StatusCompleted, StatusStopped, StatusCancelled, StatusInterrupted, StatusManual}
+
+var (
+	// ErrSessionNotFound is returned when no session matches an ID prefix
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrAmbiguousID is returned when an ID prefix matches several sessions
+	ErrAmbiguousID = errors.New("session ID prefix is ambiguous")
+	// ErrSeveralRecords is returned when an edit does not say which record of
+	// a resumed session to change
+	ErrSeveralRecords = errors.New("session has several records")
+)
+
+// Validate checks that a session is internally consistent
+func (s Session) Validate() error {
+	if s.ID == "" {
+		return fmt.Errorf("session ID cannot be empty")
+	}
+	if s.StartedAt.IsZero() || s.EndedAt.IsZero() {
+		return fmt.Errorf("session must have a start and end time")
+	}
+	if s.EndedAt.Before(s.StartedAt) {
+		return fmt.Errorf("session ends (%s) before it starts (%s)", s.EndedAt.Format(time.RFC3339), s.StartedAt.Format(time.RFC3339))
+	}
+
+	planned, err := time.ParseDuration(s.Duration)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s.Duration, err)
+	}
+	if planned <= 0 {
+		return fmt.Errorf("duration must be positive, got %v", planned)
+	}
+
+	if s.PausedDuration != "" {
+		paused, err := time.ParseDuration(s.PausedDuration)
+		if err != nil {
+			return fmt.Errorf("invalid paused duration %q: %w", s.PausedDuration, err)
+		}
+		if paused < 0 {
+			return fmt.Errorf("paused duration must not be negative, got %v", paused)
+		}
+		if paused > s.EndedAt.Sub(s.StartedAt) {
+			return fmt.Errorf("paused duration %v exceeds session length %v", paused, s.EndedAt.Sub(s.StartedAt))
+		}
+	}
+	if s.PausedCount < 0 {
+		return fmt.Errorf("paused count must not be negative, got %d", s.PausedCount)
+	}
+
+	if !contains(EndStatuses, s.EndStatus) {
+		return fmt.Errorf("invalid end status %q (expected one of %s)", s.EndStatus, strings.Join(EndStatuses, ", "))
+	}
+	if len(s.Label) > 200 {
+		return fmt.Errorf("label too long (max 200 chars), got %d", len(s.Label))
+	}
+	if len(s.Notes) > MaxNotesLength {
+		return fmt.Errorf("notes too long (max %d chars), got %d", MaxNotesLength, len(s.Notes))
+	}
+
+	return nil
+}
+
+// MaxNotesLength is the maximum length of a session's notes
+const MaxNotesLength = 2000
+
+// Overlaps reports whether two sessions share any moment of time
+func (s Session) Overlaps(other Session) bool {
+	return s.StartedAt.Before(other.EndedAt) && other.StartedAt.Before(s.EndedAt)
+}
+
+// CheckOverlap returns an error if s overlaps any session in h other than
+// records sharing its ID
+func (h *History) CheckOverlap(s Session) error {
+	for _, other := range h.Sessions {
+		if other.ID == s.ID {
+			continue
+		}
+		if s.Overlaps(other) {
+			return fmt.Errorf("session overlaps %s (%s, %s - %s)",
+				ShortID(other.ID), other.Label,
+				other.StartedAt.Local().Format("2006-01-02 15:04"),
+				other.EndedAt.Local().Format("15:04"))
+		}
+	}
+	return nil
+}
+
+// Find returns the indices of the records whose ID starts with prefix.
+// Several records may share one ID (an interrupted session that was resumed),
+// but the prefix must not match more than one distinct ID.
+func (h *History) Find(prefix string) ([]int, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: empty ID", ErrSessionNotFound)
+	}
+
+	var matches []int
+	var matchedID string
+	for i, s := range h.Sessions {
+		if !strings.HasPrefix(s.ID, prefix) {
+			continue
+		}
+		if matchedID != "" && s.ID != matchedID {
+			return nil, fmt.Errorf("%w: %q matches %s and %s", ErrAmbiguousID, prefix, ShortID(matchedID), ShortID(s.ID))
+		}
+		matchedID = s.ID
+		matches = append(matches, i)
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrSessionNotFound, prefix)
+	}
+	return matches, nil
+}
+
+// ShortID returns the abbreviated form of a session ID used in listings
+func ShortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// Editor applies validated modifications to a store and records each one in
+// an audit log so it can be undone
+type Editor struct {
+	store   Store
+	audit   *AuditLog
+	archive string // Directory of the archives Compact wrote, if any
+	now     func() time.Time
+}
+
+// NewEditor creates an editor for store that records changes in audit
+func NewEditor(store Store, audit *AuditLog) *Editor {
+	return &Editor{store: store, audit: audit, now: time.Now}
+}
+
+// SetArchiveDir makes merges and imports skip sessions already compacted
+// into the archives in dir
+func (e *Editor) SetArchiveDir(dir string) {
+	e.archive = dir
+}
+
+// archived returns the records held by the archives, if any
+func (e *Editor) archived() ([]Session, error) {
+	if e.archive == "" {
+		return nil, nil
+	}
+	return archivedRecords(e.archive)
+}
+
+// Get returns the session matching an ID prefix. When several records share
+// the ID the most recent one is returned.
+func (e *Editor) Get(prefix string) (Session, error) {
+	h, err := e.store.Load()
+	if err != nil {
+		return Session{}, err
+	}
+	matches, err := h.Find(prefix)
+	if err != nil {
+		return Session{}, err
+	}
+	return h.Sessions[matches[len(matches)-1]], nil
+}
+
+// Records returns every record of the session matching an ID prefix, in the
+// order they were stored
+func (e *Editor) Records(prefix string) ([]Session, error) {
+	h, err := e.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	matches, err := h.Find(prefix)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Session, len(matches))
+	for i, m := range matches {
+		records[i] = h.Sessions[m]
+	}
+	return records, nil
+}
+
+// Add validates and stores a new session
+func (e *Editor) Add(session Session) error {
+	if err := session.Validate(); err != nil {
+		return err
+	}
+
+	err := e.store.Update(func(h *History) error {
+		if _, err := h.Find(session.ID); err == nil {
+			return fmt.Errorf("session %s already exists", ShortID(session.ID))
+		}
+		if err := h.CheckOverlap(session); err != nil {
+			return err
+		}
+		h.AddSession(session)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = e.audit.Record(AuditEntry{Action: ActionAdd, SessionID: session.ID, After: &session})
+	return err
+}
+
+// Edit applies fn to the session matching prefix, validates the result and
+// stores it. It returns the session before and after the change.
+func (e *Editor) Edit(prefix string, fn func(s *Session) error) (Session, Session, error) {
+	return e.EditRecord(prefix, 0, fn)
+}
+
+// EditRecord is like Edit for a session with several records: record picks
+// one of them, counting from 1 in the order Records returns them. A record of
+// 0 requires the session to have a single record.
+func (e *Editor) EditRecord(prefix string, record int, fn func(s *Session) error) (Session, Session, error) {
+	var before, after Session
+	err := e.store.Update(func(h *History) error {
+		matches, err := h.Find(prefix)
+		if err != nil {
+			return err
+		}
+		switch {
+		case record == 0 && len(matches) > 1:
+			return fmt.Errorf("%s: %w, pick one of 1-%d", ShortID(h.Sessions[matches[0]].ID), ErrSeveralRecords, len(matches))
+		case record < 0 || record > len(matches):
+			return fmt.Errorf("session %s has no record %d (it has %d)", ShortID(h.Sessions[matches[0]].ID), record, len(matches))
+		case record > 0:
+			matches = matches[record-1:]
+		}
+
+		i := matches[0]
+		before = h.Sessions[i]
+		after = before
+		after.Tags = append([]string(nil), before.Tags...)
+		if err := fn(&after); err != nil {
+			return err
+		}
+		after.ID = before.ID
+		after.Revision = before.Revision + 1
+		after.ModifiedAt = e.now().UTC()
+
+		if err := after.Validate(); err != nil {
+			return err
+		}
+		if err := h.CheckOverlap(after); err != nil {
+			return err
+		}
+
+		h.Sessions[i] = after
+		return nil
+	})
+	if err != nil {
+		return Session{}, Session{}, err
+	}
+
+	_, err = e.audit.Record(AuditEntry{Action: ActionEdit, SessionID: after.ID, Before: &before, After: &after})
+	return before, after, err
+}
+
+// Delete removes every record of the session matching prefix and returns the
+// most recent of them
+func (e *Editor) Delete(prefix string) (Session, error) {
+	var removed []Session
+	err := e.store.Update(func(h *History) error {
+		matches, err := h.Find(prefix)
+		if err != nil {
+			return err
+		}
+
+		kept := h.Sessions[:0:0]
+		next := 0
+		for i, s := range h.Sessions {
+			if next < len(matches) && matches[next] == i {
+				removed = append(removed, s)
+				next++
+				continue
+			}
+			kept = append(kept, s)
+		}
+		h.Sessions = kept
+		return nil
+	})
+	if err != nil {
+		return Session{}, err
+	}
+
+	last := removed[len(removed)-1]
+	entry := AuditEntry{Action: ActionDelete, SessionID: last.ID, Before: &last}
+	if len(removed) > 1 {
+		entry.Removed = removed
+	}
+	_, err = e.audit.Record(entry)
+	return last, err
+}
+
+// Undo reverts the most recent modification that has not been undone yet
+// and returns the entry that was reverted. A reverted edit counts as a new
+// revision so merges prefer it over copies of the edited session.
+func (e *Editor) Undo() (*AuditEntry, error) {
+	entry, err := e.audit.lastUndoable()
+	if err != nil {
+		return nil, err
+	}
+
+	restored := entry.Before
+	if entry.Action == ActionEdit {
+		reverted := *entry.Before
+		reverted.Revision = entry.After.Revision + 1
+		reverted.ModifiedAt = e.now().UTC()
+		restored = &reverted
+	}
+
+	err = e.store.Update(func(h *History) error {
+		switch entry.Action {
+		case ActionAdd:
+			return h.replace(entry.SessionID, entry.After, nil)
+		case ActionDelete:
+			for _, s := range entry.removedRecords() {
+				h.AddSession(s)
+			}
+			return nil
+		case ActionEdit:
+			return h.replace(entry.SessionID, entry.After, restored)
+		default:
+			return fmt.Errorf("cannot undo audit action %q", entry.Action)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to undo %s of session %s: %w", entry.Action, ShortID(entry.SessionID), err)
+	}
+
+	undo := AuditEntry{Action: ActionUndo, SessionID: entry.SessionID, Before: entry.After, After: restored, Undoes: entry.ID}
+	if _, err := e.audit.Record(undo); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// replace swaps the record of id equal to expected for with, or removes it
+// when with is nil. It fails if the session changed since the audited
+// modification.
+func (h *History) replace(id string, expected *Session, with *Session) error {
+	for i, s := range h.Sessions {
+		if s.ID != id || (expected != nil && !sameSession(s, *expected)) {
+			continue
+		}
+		if with == nil {
+			h.Sessions = append(h.Sessions[:i], h.Sessions[i+1:]...)
+		} else {
+			h.Sessions[i] = *with
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s was modified or removed since", ErrSessionNotFound, ShortID(id))
+}
+
+// sameSession compares two sessions by their persisted form
+func sameSession(a, b Session) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
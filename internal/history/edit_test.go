@@ -0,0 +1,258 @@
+package history
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Validate(t *testing.T) {
+	valid := sessionAt("valid", 9, "Work", "work", "completed")
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(s *Session)
+	}{
+		{"missing ID", func(s *Session) { s.ID = "" }},
+		{"missing end", func(s *Session) { s.EndedAt = time.Time{} }},
+		{"negative length", func(s *Session) { s.EndedAt = s.StartedAt.Add(-time.Minute) }},
+		{"bad duration", func(s *Session) { s.Duration = "soon" }},
+		{"zero duration", func(s *Session) { s.Duration = "0s" }},
+		{"negative pause", func(s *Session) { s.PausedDuration = "-1m" }},
+		{"pause longer than session", func(s *Session) { s.PausedDuration = "1h" }},
+		{"unknown status", func(s *Session) { s.EndStatus = "finished" }},
+		{"notes too long", func(s *Session) { s.Notes = strings.Repeat("x", MaxNotesLength+1) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			assert.Error(t, s.Validate())
+		})
+	}
+}
+
+func TestHistory_CheckOverlap(t *testing.T) {
+	h := &History{Version: "1.0", Sessions: []Session{sessionAt("a", 9, "Work", "work", "completed")}}
+
+	overlapping := sessionAt("b", 9, "Other", "work", "completed")
+	overlapping.StartedAt = overlapping.StartedAt.Add(20 * time.Minute)
+	overlapping.EndedAt = overlapping.StartedAt.Add(25 * time.Minute)
+	assert.Error(t, h.CheckOverlap(overlapping))
+
+	// Back-to-back sessions do not overlap
+	adjacent := sessionAt("c", 9, "Other", "work", "completed")
+	adjacent.StartedAt = adjacent.StartedAt.Add(25 * time.Minute)
+	adjacent.EndedAt = adjacent.StartedAt.Add(25 * time.Minute)
+	assert.NoError(t, h.CheckOverlap(adjacent))
+
+	// A session never overlaps its own record
+	assert.NoError(t, h.CheckOverlap(h.Sessions[0]))
+}
+
+func TestHistory_Find(t *testing.T) {
+	h := &History{Version: "1.0", Sessions: []Session{
+		sessionAt("abc123", 8, "A", "work", "interrupted"),
+		sessionAt("abd456", 9, "B", "work", "completed"),
+		sessionAt("abc123", 8, "A", "work", "completed"),
+	}}
+
+	matches, err := h.Find("abc")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, matches)
+
+	_, err = h.Find("ab")
+	assert.ErrorIs(t, err, ErrAmbiguousID)
+
+	_, err = h.Find("zzz")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func newTestEditor(t *testing.T, backend string) (*Editor, Store, *AuditLog) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := Open(backend, dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	audit := NewAuditLog(filepath.Join(dir, AuditFileName))
+	return NewEditor(store, audit), store, audit
+}
+
+func TestEditor_EditDeleteUndo(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			editor, store, audit := newTestEditor(t, backend)
+			require.NoError(t, store.Append(sessionAt("first-id", 9, "Fix login", "work", "completed")))
+			require.NoError(t, store.Append(sessionAt("second-id", 10, "Docs", "work", "stopped")))
+
+			before, after, err := editor.Edit("first", func(s *Session) error {
+				s.Label = "Fix login redirect"
+				s.Tags = []string{"bugfix"}
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "Fix login", before.Label)
+			assert.Equal(t, "Fix login redirect", after.Label)
+
+			deleted, err := editor.Delete("second")
+			require.NoError(t, err)
+			assert.Equal(t, "second-id", deleted.ID)
+
+			h, err := store.Load()
+			require.NoError(t, err)
+			require.Len(t, h.Sessions, 1)
+			assert.Equal(t, "Fix login redirect", h.Sessions[0].Label)
+
+			// Undo the delete, then the edit
+			entry, err := editor.Undo()
+			require.NoError(t, err)
+			assert.Equal(t, ActionDelete, entry.Action)
+			entry, err = editor.Undo()
+			require.NoError(t, err)
+			assert.Equal(t, ActionEdit, entry.Action)
+
+			h, err = store.Load()
+			require.NoError(t, err)
+			require.Len(t, h.Sessions, 2)
+			s, err := editor.Get("first")
+			require.NoError(t, err)
+			assert.Equal(t, "Fix login", s.Label)
+			assert.Empty(t, s.Tags)
+
+			_, err = editor.Undo()
+			assert.ErrorIs(t, err, ErrNothingToUndo)
+
+			entries, err := audit.Entries()
+			require.NoError(t, err)
+			actions := make([]string, len(entries))
+			for i, e := range entries {
+				actions[i] = e.Action
+			}
+			assert.Equal(t, []string{ActionEdit, ActionDelete, ActionUndo, ActionUndo}, actions)
+		})
+	}
+}
+
+func TestEditor_ResumedSession(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			editor, store, audit := newTestEditor(t, backend)
+			// The records of a resumed session share an ID and start
+			interrupted := sessionAt("resumed", 9, "A", "work", "interrupted")
+			interrupted.EndedAt = interrupted.StartedAt.Add(10 * time.Minute)
+			require.NoError(t, store.Append(interrupted))
+			require.NoError(t, store.Append(sessionAt("resumed", 9, "A", "work", "completed")))
+
+			_, _, err := editor.Edit("res", func(s *Session) error {
+				s.Label = "B"
+				return nil
+			})
+			assert.ErrorIs(t, err, ErrSeveralRecords)
+
+			_, _, err = editor.EditRecord("res", 3, func(s *Session) error { return nil })
+			assert.Error(t, err)
+
+			before, after, err := editor.EditRecord("res", 2, func(s *Session) error {
+				s.Label = "B"
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, StatusCompleted, before.EndStatus)
+			assert.Equal(t, "B", after.Label)
+
+			records, err := editor.Records("res")
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			assert.Equal(t, "A", records[0].Label)
+			assert.Equal(t, "B", records[1].Label)
+
+			// Deleting removes both records in one audited change, and one undo
+			// brings both back
+			_, err = editor.Delete("res")
+			require.NoError(t, err)
+			entries, err := audit.Entries()
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			assert.Len(t, entries[1].Removed, 2)
+
+			entry, err := editor.Undo()
+			require.NoError(t, err)
+			assert.Equal(t, ActionDelete, entry.Action)
+			records, err = editor.Records("res")
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			assert.Equal(t, []string{"A", "B"}, []string{records[0].Label, records[1].Label})
+		})
+	}
+}
+
+func TestEditor_EditRejectsInvalidChanges(t *testing.T) {
+	editor, store, audit := newTestEditor(t, BackendJSON)
+	require.NoError(t, store.Append(sessionAt("a", 9, "A", "work", "completed")))
+	require.NoError(t, store.Append(sessionAt("b", 10, "B", "work", "completed")))
+
+	// Negative session
+	_, _, err := editor.Edit("a", func(s *Session) error {
+		s.EndedAt = s.StartedAt.Add(-time.Minute)
+		return nil
+	})
+	assert.Error(t, err)
+
+	// Overlap with b
+	_, _, err = editor.Edit("a", func(s *Session) error {
+		s.EndedAt = s.StartedAt.Add(70 * time.Minute)
+		return nil
+	})
+	assert.ErrorContains(t, err, "overlaps")
+
+	// Nothing was stored or audited
+	s, err := editor.Get("a")
+	require.NoError(t, err)
+	assert.True(t, s.EndedAt.Equal(sessionAt("a", 9, "A", "work", "completed").EndedAt))
+	entries, err := audit.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestEditor_AddAndUndo(t *testing.T) {
+	editor, store, _ := newTestEditor(t, BackendJSONL)
+	require.NoError(t, store.Append(sessionAt("a", 9, "A", "work", "completed")))
+
+	overlapping := sessionAt("b", 9, "B", "work", "completed")
+	assert.Error(t, editor.Add(overlapping))
+
+	added := sessionAt("c", 11, "C", "work", "completed")
+	require.NoError(t, editor.Add(added))
+	assert.Error(t, editor.Add(added), "duplicate ID")
+
+	_, err := editor.Undo()
+	require.NoError(t, err)
+	h, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, ids(h.Sessions))
+}
+
+func TestEditor_UndoRefusesWhenSessionChangedSince(t *testing.T) {
+	editor, store, _ := newTestEditor(t, BackendJSON)
+	require.NoError(t, store.Append(sessionAt("a", 9, "A", "work", "completed")))
+
+	_, _, err := editor.Edit("a", func(s *Session) error {
+		s.Label = "Edited"
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Changed outside the editor, so the audited "after" no longer matches
+	require.NoError(t, store.Update(func(h *History) error {
+		h.Sessions[0].Label = "Changed elsewhere"
+		return nil
+	}))
+
+	_, err = editor.Undo()
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
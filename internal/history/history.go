@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/pomodux/pomodux/internal/encryption"
 )
 
 // Session represents a completed timer session
@@ -16,9 +18,35 @@ type Session struct {
 	Duration       string    `json:"duration"` // e.g., "25m"
 	Preset         string    `json:"preset,omitempty"`
 	Label          string    `json:"label"`
-	EndStatus      string    `json:"end_status"` // completed, stopped, cancelled, interrupted
+	Project        string    `json:"project,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	EndStatus      string    `json:"end_status"` // completed, stopped, cancelled, interrupted, manual
 	PausedCount    int       `json:"paused_count"`
 	PausedDuration string    `json:"paused_duration"` // e.g., "3m"
+	Notes          string    `json:"notes,omitempty"`
+	Private        bool      `json:"private,omitempty"` // Label and notes stay out of logs, stats and exports
+	Revision       int       `json:"revision,omitempty"`   // Number of edits since the session was recorded
+	ModifiedAt     time.Time `json:"modified_at,omitzero"` // Time of the last edit
+}
+
+// FocusDuration returns the time actually spent focused: wall-clock time
+// between start and end minus pauses, capped at the planned duration
+func (s Session) FocusDuration() time.Duration {
+	if s.StartedAt.IsZero() || s.EndedAt.IsZero() {
+		return 0
+	}
+
+	focus := s.EndedAt.Sub(s.StartedAt)
+	if paused, err := time.ParseDuration(s.PausedDuration); err == nil {
+		focus -= paused
+	}
+	if planned, err := time.ParseDuration(s.Duration); err == nil && focus > planned {
+		focus = planned
+	}
+	if focus < 0 {
+		return 0
+	}
+	return focus
 }
 
 // History represents the session history
@@ -29,6 +57,11 @@ type History struct {
 
 // Load loads history from the given path
 func Load(path string) (*History, error) {
+	return load(path, encryption.Decrypt)
+}
+
+// load loads history from path, opening the file with decrypt
+func load(path string, decrypt func([]byte) ([]byte, error)) (*History, error) {
 	// Check if file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return &History{
@@ -38,17 +71,21 @@ func Load(path string) (*History, error) {
 	}
 
 	// Read file
-	data, err := os.ReadFile(path)
+	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read history file: %w", err)
 	}
+	data, err := decrypt(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt history file: %w", err)
+	}
 
 	// Parse JSON
 	var history History
 	if err := json.Unmarshal(data, &history); err != nil {
 		// Try to backup corrupted file
 		backupPath := path + ".backup"
-		os.WriteFile(backupPath, data, 0600)
+		os.WriteFile(backupPath, raw, 0600)
 		return nil, fmt.Errorf("failed to parse history file (backed up to %s): %w", backupPath, err)
 	}
 
@@ -69,13 +106,7 @@ func Save(history *History, path string) error {
 		return fmt.Errorf("failed to marshal history: %w", err)
 	}
 
-	// Atomic write: write to temp file, then rename
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write history file: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, path); err != nil {
+	if err := encryption.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to save history file: %w", err)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pomodux/pomodux/internal/encryption"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -147,6 +148,35 @@ func TestSave_AtomicWrite(t *testing.T) {
 	assert.Equal(t, "atomic-test", loaded.Sessions[0].ID)
 }
 
+func TestSaveLoad_Encrypted(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyfile := filepath.Join(tmpDir, "history.key")
+	require.NoError(t, encryption.GenerateKeyfile(keyfile))
+	d, kek, err := encryption.NewDescriptor("", keyfile)
+	require.NoError(t, err)
+	_, err = encryption.Enable(tmpDir, d, kek, encryption.Files{})
+	require.NoError(t, err)
+	t.Cleanup(func() { encryption.Unlock(nil) })
+
+	path := filepath.Join(tmpDir, "history.json")
+	h := &History{Version: "1.0", Sessions: []Session{{ID: "secret-session", Label: "Secret"}}}
+	require.NoError(t, Save(h, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(data))
+	assert.NotContains(t, string(data), "Secret")
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.Sessions, 1)
+	assert.Equal(t, "secret-session", loaded.Sessions[0].ID)
+
+	encryption.Unlock(nil)
+	_, err = Load(path)
+	assert.ErrorIs(t, err, encryption.ErrLocked)
+}
+
 func TestAddSession(t *testing.T) {
 	h := &History{
 		Version:  "1.0",
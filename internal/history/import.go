@@ -0,0 +1,186 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Strategies for recognising sessions that are already in the history
+const (
+	DedupID         = "id"          // Same session ID
+	DedupStartLabel = "start-label" // Same start time (to the second) and label
+)
+
+// DedupStrategies lists every valid dedup strategy
+var DedupStrategies = []string{DedupID, DedupStartLabel}
+
+// ImportPlan sorts the sessions of an import by what merging them does
+type ImportPlan struct {
+	Added      []Session
+	Duplicates []ImportSkip // Already in the history or earlier in the import
+	Conflicts  []ImportSkip // Overlap a session they do not duplicate, or reuse its ID
+	Invalid    []ImportSkip // Fail validation, such as zero-length entries
+}
+
+// ImportSkip is a session left out of an import and the reason why
+type ImportSkip struct {
+	Session Session
+	Reason  string
+}
+
+// PlanImport decides which sessions would be added to h. Sessions without an
+// ID are given one. Invalid sessions, duplicates (by dedup) and sessions
+// overlapping existing or already added ones are skipped, and so are
+// sessions whose ID is taken in any dedup mode. An overlapping session that
+// matches the one it overlaps once times are truncated to precision, the
+// precision of the format it was read from, is a duplicate: formats without
+// IDs read back an export of the history that way. h is modified to hold
+// the added sessions.
+func PlanImport(h *History, sessions []Session, dedup string, precision time.Duration) (*ImportPlan, error) {
+	return planImport(h, sessions, dedup, precision, nil)
+}
+
+// planImport is PlanImport that also skips duplicates of archived records
+func planImport(h *History, sessions []Session, dedup string, precision time.Duration, archived []Session) (*ImportPlan, error) {
+	key := func(s Session) string { return s.ID }
+	if dedup == DedupStartLabel {
+		key = func(s Session) string {
+			return fmt.Sprintf("%d\x00%s", s.StartedAt.Truncate(time.Second).Unix(), s.Label)
+		}
+	} else if dedup != DedupID {
+		return nil, fmt.Errorf("invalid dedup strategy %q (expected %s)", dedup, strings.Join(DedupStrategies, " or "))
+	}
+
+	existing := map[string]Session{}
+	byID := map[string]Session{}
+	for _, s := range h.Sessions {
+		existing[key(s)] = s
+		byID[s.ID] = s
+	}
+	archivedKeys := map[string]Session{}
+	for _, s := range archived {
+		archivedKeys[key(s)] = s
+		archivedKeys[s.ID] = s
+	}
+
+	plan := &ImportPlan{}
+	importedIDs := map[string]bool{}
+	importedRecords := map[string]bool{}
+	for i, s := range sessions {
+		if s.ID == "" {
+			s.ID = uuid.New().String()
+		}
+		if err := s.Validate(); err != nil {
+			plan.Invalid = append(plan.Invalid, ImportSkip{Session: s, Reason: fmt.Sprintf("session %d: %v", i+1, err)})
+			continue
+		}
+
+		other, ok := archivedKeys[key(s)]
+		if !ok {
+			other, ok = archivedKeys[s.ID]
+		}
+		if !ok {
+			other, ok = matchAt(archived, s, precision)
+		}
+		if ok {
+			plan.Duplicates = append(plan.Duplicates, ImportSkip{Session: s, Reason: "duplicates archived " + ShortID(other.ID)})
+			continue
+		}
+
+		record := recordKey(s)
+		if importedRecords[record] {
+			plan.Duplicates = append(plan.Duplicates, ImportSkip{Session: s, Reason: "duplicates " + ShortID(s.ID) + " earlier in the import"})
+			continue
+		}
+		// Other records of a session this import added come from resuming it
+		if !importedIDs[s.ID] {
+			if other, ok := existing[key(s)]; ok {
+				reason := "duplicates " + ShortID(other.ID)
+				if importedIDs[other.ID] {
+					reason += " earlier in the import"
+				}
+				plan.Duplicates = append(plan.Duplicates, ImportSkip{Session: s, Reason: reason})
+				continue
+			}
+			if other, ok := byID[s.ID]; ok {
+				reason := fmt.Sprintf("reuses the ID of %s (%s, %s)", ShortID(other.ID), other.Label, other.StartedAt.Local().Format("2006-01-02 15:04"))
+				plan.Conflicts = append(plan.Conflicts, ImportSkip{Session: s, Reason: reason})
+				continue
+			}
+		}
+		if err := h.CheckOverlap(s); err != nil {
+			if other, ok := matchAt(h.Sessions, s, precision); ok {
+				reason := "duplicates " + ShortID(other.ID)
+				if importedIDs[other.ID] {
+					reason += " earlier in the import"
+				}
+				plan.Duplicates = append(plan.Duplicates, ImportSkip{Session: s, Reason: reason})
+				continue
+			}
+			plan.Conflicts = append(plan.Conflicts, ImportSkip{Session: s, Reason: strings.TrimPrefix(err.Error(), "session ")})
+			continue
+		}
+
+		h.AddSession(s)
+		existing[key(s)] = s
+		byID[s.ID] = s
+		importedIDs[s.ID] = true
+		importedRecords[record] = true
+		plan.Added = append(plan.Added, s)
+	}
+	return plan, nil
+}
+
+// matchAt returns the session of sessions that s matches once start and end
+// are truncated to precision, with the same label, project and tags
+func matchAt(sessions []Session, s Session, precision time.Duration) (Session, bool) {
+	for _, other := range sessions {
+		if other.StartedAt.Truncate(precision).Equal(s.StartedAt.Truncate(precision)) &&
+			other.EndedAt.Truncate(precision).Equal(s.EndedAt.Truncate(precision)) &&
+			other.Label == s.Label && other.Project == s.Project &&
+			strings.Join(other.Tags, "\x00") == strings.Join(s.Tags, "\x00") {
+			return other, true
+		}
+	}
+	return Session{}, false
+}
+
+// Import merges sessions into the history in one exclusive update, so
+// either every planned session is stored or none is. Sessions already in
+// the archives count as duplicates. precision is that of the format the
+// sessions were read from, as for PlanImport. Added sessions are recorded
+// in the audit log. With dryRun set the history is left unchanged.
+func (e *Editor) Import(sessions []Session, dedup string, precision time.Duration, dryRun bool) (*ImportPlan, error) {
+	archived, err := e.archived()
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		h, err := e.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		return planImport(h, sessions, dedup, precision, archived)
+	}
+
+	var plan *ImportPlan
+	err = e.store.Update(func(h *History) error {
+		var err error
+		plan, err = planImport(h, sessions, dedup, precision, archived)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range plan.Added {
+		s := s
+		if _, err := e.audit.Record(AuditEntry{Action: ActionAdd, SessionID: s.ID, After: &s}); err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,162 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanImport_DedupByID(t *testing.T) {
+	h := &History{Version: "1.0", Sessions: []Session{sessionAt("a", 9, "A", "work", "completed")}}
+
+	// An interrupted session and its resumption share an ID and start
+	resumed := sessionAt("r", 13, "R", "work", "completed")
+	interrupted := resumed
+	interrupted.EndedAt = interrupted.StartedAt.Add(10 * time.Minute)
+	interrupted.EndStatus = StatusInterrupted
+	overlapping := sessionAt("o", 9, "O", "work", "completed")
+	overlapping.StartedAt = overlapping.StartedAt.Add(10 * time.Minute)
+	noID := sessionAt("", 16, "New", "work", "manual")
+
+	plan, err := PlanImport(h, []Session{
+		sessionAt("a", 9, "A renamed", "work", "completed"),
+		sessionAt("b", 11, "B", "work", "completed"),
+		sessionAt("b", 11, "B", "work", "completed"),
+		interrupted, resumed, resumed, overlapping, noID,
+	}, DedupID, 0)
+	require.NoError(t, err)
+
+	require.Len(t, plan.Added, 4)
+	assert.Equal(t, []string{"b", "r", "r"}, ids(plan.Added[:3]))
+	assert.NotEmpty(t, plan.Added[3].ID, "sessions without an ID get one")
+
+	require.Len(t, plan.Duplicates, 3)
+	assert.Equal(t, "duplicates a", plan.Duplicates[0].Reason)
+	assert.Equal(t, "duplicates b earlier in the import", plan.Duplicates[1].Reason)
+	assert.Equal(t, "duplicates r earlier in the import", plan.Duplicates[2].Reason)
+	require.Len(t, plan.Conflicts, 1)
+	assert.Equal(t, "o", plan.Conflicts[0].Session.ID)
+	assert.Contains(t, plan.Conflicts[0].Reason, "overlaps a")
+
+	assert.Len(t, h.Sessions, 5)
+}
+
+func TestPlanImport_DedupByStartAndLabel(t *testing.T) {
+	h := &History{Version: "1.0", Sessions: []Session{sessionAt("a", 9, "A", "work", "completed")}}
+
+	sameStart := sessionAt("x", 9, "A", "work", "manual")
+	sameStart.StartedAt = sameStart.StartedAt.Add(400 * time.Millisecond)
+	plan, err := PlanImport(h, []Session{sameStart, sessionAt("a", 11, "Other", "work", "completed")}, DedupStartLabel, 0)
+	require.NoError(t, err)
+
+	require.Len(t, plan.Duplicates, 1)
+	assert.Equal(t, "x", plan.Duplicates[0].Session.ID)
+	// A session reusing an ID is never added, whatever its start and label
+	assert.Empty(t, plan.Added)
+	require.Len(t, plan.Conflicts, 1)
+	assert.Contains(t, plan.Conflicts[0].Reason, "reuses the ID of a")
+
+	// The records of a resumed session share their start and label too
+	interrupted, completed := sessionAt("r", 13, "R", "work", StatusInterrupted), sessionAt("r", 13, "R", "work", StatusCompleted)
+	interrupted.EndedAt = interrupted.StartedAt.Add(10 * time.Minute)
+	plan, err = PlanImport(h, []Session{interrupted, completed}, DedupStartLabel, 0)
+	require.NoError(t, err)
+	assert.Len(t, plan.Added, 2)
+}
+
+func TestPlanImport_SkipsInvalidSessions(t *testing.T) {
+	h := &History{Version: "1.0"}
+	invalid := sessionAt("bad", 9, "Bad", "work", "finished")
+	empty := sessionAt("empty", 10, "Empty", "", "manual")
+	empty.EndedAt, empty.Duration = empty.StartedAt, "0s"
+	plan, err := PlanImport(h, []Session{sessionAt("ok", 8, "OK", "work", "completed"), invalid, empty}, DedupID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ok"}, ids(plan.Added))
+	require.Len(t, plan.Invalid, 2)
+	assert.Contains(t, plan.Invalid[0].Reason, "session 2")
+	assert.Contains(t, plan.Invalid[1].Reason, "duration must be positive")
+	assert.Equal(t, []string{"ok"}, ids(h.Sessions))
+
+	_, err = PlanImport(h, nil, "fuzzy", 0)
+	assert.Error(t, err)
+}
+
+func TestEditor_Import(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			editor, store, audit := newTestEditor(t, backend)
+			require.NoError(t, store.Append(sessionAt("a", 9, "A", "work", "completed")))
+			sessions := []Session{sessionAt("a", 9, "A", "work", "completed"), sessionAt("b", 11, "B", "work", "completed")}
+
+			plan, err := editor.Import(sessions, DedupID, 0, true)
+			require.NoError(t, err)
+			assert.Len(t, plan.Added, 1)
+			h, err := store.Load()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a"}, ids(h.Sessions), "dry run leaves the history unchanged")
+
+			plan, err = editor.Import(sessions, DedupID, 0, false)
+			require.NoError(t, err)
+			assert.Len(t, plan.Added, 1)
+			h, err = store.Load()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b"}, ids(h.Sessions))
+
+			entries, err := audit.Entries()
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			assert.Equal(t, ActionAdd, entries[0].Action)
+
+			// Importing again changes nothing
+			plan, err = editor.Import(sessions, DedupID, 0, false)
+			require.NoError(t, err)
+			assert.Empty(t, plan.Added)
+			assert.Len(t, plan.Duplicates, 2)
+		})
+	}
+}
+
+func TestEditor_ImportIsAtomic(t *testing.T) {
+	editor, store, _ := newTestEditor(t, BackendJSONL)
+	// A failed plan stores nothing
+	_, err := editor.Import([]Session{
+		sessionAt("b", 11, "B", "work", "completed"),
+		sessionAt("c", 12, "C", "work", "completed"),
+	}, "fuzzy", 0, false)
+	require.Error(t, err)
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, h.Sessions)
+}
+
+func TestPlanImport_MatchesAtPrecision(t *testing.T) {
+	recorded := sessionAt("a", 9, "A", "work", "completed")
+	recorded.StartedAt = recorded.StartedAt.Add(12*time.Second + 345*time.Millisecond)
+	recorded.EndedAt = recorded.EndedAt.Add(40 * time.Second)
+	recorded.Project, recorded.Tags = "acme", []string{"billable"}
+	h := &History{Version: "1.0", Sessions: []Session{recorded}}
+
+	// As an Org export reads back: no ID, minute precision, a manual log
+	exported := recorded
+	exported.ID, exported.EndStatus, exported.Preset = "", StatusManual, ""
+	exported.StartedAt = exported.StartedAt.Truncate(time.Minute)
+	exported.EndedAt = exported.EndedAt.Truncate(time.Minute)
+	renamed := exported
+	renamed.Label = "B"
+
+	plan, err := PlanImport(h, []Session{exported, renamed}, DedupID, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Added)
+	require.Len(t, plan.Duplicates, 1)
+	assert.Equal(t, "duplicates a", plan.Duplicates[0].Reason)
+	require.Len(t, plan.Conflicts, 1, "a different session at the same time still conflicts")
+	assert.Contains(t, plan.Conflicts[0].Reason, "overlaps a")
+
+	// At second precision the minutes no longer match
+	plan, err = PlanImport(h, []Session{exported}, DedupID, time.Second)
+	require.NoError(t, err)
+	assert.Len(t, plan.Conflicts, 1)
+}
@@ -0,0 +1,300 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/atomicfile"
+)
+
+// maxLineSize bounds a single JSONL record
+const maxLineSize = 1024 * 1024
+
+// JSONLStore keeps history as JSON Lines: one session per line, append-only.
+// A final line without a trailing newline that is not a valid session is the
+// remnant of an interrupted append; it is ignored on load and discarded by
+// the next append. A valid one, as written by hand or another tool, is kept
+// and completed with its newline by the next append.
+type JSONLStore struct {
+	path string
+}
+
+// NewJSONLStore creates a store backed by the JSON Lines file at path
+func NewJSONLStore(path string) *JSONLStore {
+	return &JSONLStore{path: path}
+}
+
+// Path returns the file backing the store
+func (s *JSONLStore) Path() string {
+	return s.path
+}
+
+// Append writes a single line for session under an exclusive lock
+func (s *JSONLStore) Append(session Session) error {
+	line, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+	line = append(line, '\n')
+
+	return withLock(s.path, func() error {
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open history file: %w", err)
+		}
+		defer f.Close()
+
+		end, err := repairLastLine(f)
+		if err != nil {
+			return fmt.Errorf("failed to repair history file: %w", err)
+		}
+
+		if _, err := f.WriteAt(line, end); err != nil {
+			return fmt.Errorf("failed to append to history file: %w", err)
+		}
+
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("failed to sync history file: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Load reads every complete line of the file
+func (s *JSONLStore) Load() (*History, error) {
+	h := &History{
+		Version:  "1.0",
+		Sessions: []Session{},
+	}
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReaderSize(f, 64*1024)
+	lineNo := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A line without a newline is kept unless it is an
+			// interrupted append
+			if session, ok := parseLastLine(line); ok {
+				h.Sessions = append(h.Sessions, session)
+			}
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history file: %w", err)
+		}
+		lineNo++
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if len(line) > maxLineSize {
+			return nil, fmt.Errorf("history line %d exceeds %d bytes", lineNo, maxLineSize)
+		}
+
+		var session Session
+		if err := json.Unmarshal(line, &session); err != nil {
+			return nil, fmt.Errorf("failed to parse history line %d: %w", lineNo, err)
+		}
+		h.Sessions = append(h.Sessions, session)
+	}
+
+	return h, nil
+}
+
+// Update rewrites the whole file under an exclusive lock
+func (s *JSONLStore) Update(fn func(h *History) error) error {
+	return withLock(s.path, func() error {
+		h, err := s.Load()
+		if err != nil {
+			return err
+		}
+
+		if err := fn(h); err != nil {
+			return err
+		}
+
+		return writeJSONL(h, s.path)
+	})
+}
+
+// Between reads the file and filters it by start time
+func (s *JSONLStore) Between(from, to time.Time) ([]Session, error) {
+	h, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return filterBetween(h, from, to), nil
+}
+
+// Query reads the file and runs q in memory
+func (s *JSONLStore) Query(q Query) (*Page, error) {
+	h, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return h.Query(q)
+}
+
+// Close is a no-op for the JSONL store
+func (s *JSONLStore) Close() error {
+	return nil
+}
+
+// parseLastLine parses a final line that lacks its newline, reporting
+// whether it holds a whole session
+func parseLastLine(line []byte) (Session, bool) {
+	var session Session
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || len(line) > maxLineSize || json.Unmarshal(line, &session) != nil {
+		return Session{}, false
+	}
+	return session, true
+}
+
+// repairLastLine completes a final line that lacks its newline with one if
+// it holds a whole session, and cuts it off otherwise. It returns the
+// resulting file size.
+func repairLastLine(f *os.File) (int64, error) {
+	start, size, err := partialLine(f)
+	if err != nil || start == size {
+		return size, err
+	}
+
+	tail := make([]byte, size-start)
+	if _, err := f.ReadAt(tail, start); err != nil {
+		return 0, err
+	}
+	if _, ok := parseLastLine(tail); ok {
+		if _, err := f.WriteAt([]byte{'\n'}, size); err != nil {
+			return 0, err
+		}
+		return size + 1, nil
+	}
+	if err := f.Truncate(start); err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+// truncatePartialLine cuts off a trailing line that lacks its newline and
+// returns the resulting file size
+func truncatePartialLine(f *os.File) (int64, error) {
+	start, size, err := partialLine(f)
+	if err != nil || start == size {
+		return size, err
+	}
+	if err := f.Truncate(start); err != nil {
+		return 0, err
+	}
+	return start, nil
+}
+
+// partialLine returns where a trailing line that lacks its newline starts,
+// and the file size; without such a line both are the size
+func partialLine(f *os.File) (int64, int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return 0, 0, nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, size-1); err != nil {
+		return 0, 0, err
+	}
+	if last[0] == '\n' {
+		return size, size, nil
+	}
+
+	// Scan backwards in chunks for the last newline
+	const chunk = 4096
+	end := size
+	for end > 0 {
+		start := end - chunk
+		if start < 0 {
+			start = 0
+		}
+		buf := make([]byte, end-start)
+		if _, err := f.ReadAt(buf, start); err != nil {
+			return 0, 0, err
+		}
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			return start + int64(i) + 1, size, nil
+		}
+		end = start
+	}
+	return 0, size, nil
+}
+
+// writeJSONL writes all sessions of h to path atomically
+func writeJSONL(h *History, path string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, session := range h.Sessions {
+		if err := enc.Encode(session); err != nil {
+			return fmt.Errorf("failed to marshal session %s: %w", session.ID, err)
+		}
+	}
+
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to save history file: %w", err)
+	}
+	return nil
+}
+
+// MigrateToJSONL converts the history.json document at jsonPath into a JSON
+// Lines file at jsonlPath and renames the original to jsonPath+".migrated".
+// It does nothing if jsonPath does not exist or jsonlPath already exists, and
+// returns the number of sessions migrated.
+func MigrateToJSONL(jsonPath string, jsonlPath string) (int, error) {
+	if _, err := os.Stat(jsonPath); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	migrated := 0
+	err := withLock(jsonPath, func() error {
+		if _, err := os.Stat(jsonlPath); err == nil {
+			return nil
+		}
+
+		h, err := Load(jsonPath)
+		if err != nil {
+			return err
+		}
+
+		if err := writeJSONL(h, jsonlPath); err != nil {
+			return err
+		}
+
+		if err := os.Rename(jsonPath, jsonPath+".migrated"); err != nil {
+			return fmt.Errorf("failed to retire migrated history file: %w", err)
+		}
+
+		migrated = len(h.Sessions)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to migrate history to JSONL: %w", err)
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,200 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSession(id string) Session {
+	return Session{
+		ID:             id,
+		StartedAt:      time.Date(2025, 1, 15, 14, 0, 0, 0, time.UTC),
+		EndedAt:        time.Date(2025, 1, 15, 14, 25, 0, 0, time.UTC),
+		Duration:       "25m",
+		Preset:         "work",
+		Label:          "Session " + id,
+		EndStatus:      "completed",
+		PausedCount:    0,
+		PausedDuration: "0s",
+	}
+}
+
+func TestJSONLStore_LoadMissingFile(t *testing.T) {
+	store := NewJSONLStore(filepath.Join(t.TempDir(), "history.jsonl"))
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", h.Version)
+	assert.Empty(t, h.Sessions)
+}
+
+func TestJSONLStore_AppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "history.jsonl")
+	store := NewJSONLStore(path)
+
+	require.NoError(t, store.Append(testSession("a")))
+	require.NoError(t, store.Append(testSession("b")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, countLines(data))
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+	assert.Equal(t, "a", h.Sessions[0].ID)
+	assert.Equal(t, "b", h.Sessions[1].ID)
+	assert.True(t, h.Sessions[0].StartedAt.Equal(testSession("a").StartedAt))
+}
+
+func TestJSONLStore_TruncatedFinalLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := NewJSONLStore(path)
+	require.NoError(t, store.Append(testSession("complete")))
+
+	// Simulate a crash halfway through the next append
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"partial","started_at":"2025-01`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 1)
+	assert.Equal(t, "complete", h.Sessions[0].ID)
+
+	// The next append discards the partial line instead of gluing onto it
+	require.NoError(t, store.Append(testSession("next")))
+	h, err = store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+	assert.Equal(t, "next", h.Sessions[1].ID)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "partial")
+}
+
+func TestJSONLStore_FinalLineWithoutNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	store := NewJSONLStore(path)
+	require.NoError(t, store.Append(testSession("a")))
+
+	// Edited by hand or written by another tool, without a final newline
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"b","started_at":"2025-01-15T15:00:00Z","ended_at":"2025-01-15T15:25:00Z","duration":"25m","label":"B","end_status":"manual"}`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, ids(h.Sessions))
+
+	// The next append completes the line instead of discarding it
+	require.NoError(t, store.Append(testSession("c")))
+	h, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ids(h.Sessions))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, countLines(data))
+}
+
+func TestJSONLStore_CorruptMiddleLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := `{"id":"a","end_status":"completed"}
+not json
+{"id":"b","end_status":"completed"}
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+	_, err := NewJSONLStore(path).Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestMigrateToJSONL(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "history.json")
+	jsonlPath := filepath.Join(dir, "history.jsonl")
+
+	original := &History{Version: "1.0", Sessions: []Session{testSession("1"), testSession("2"), testSession("3")}}
+	require.NoError(t, Save(original, jsonPath))
+
+	n, err := MigrateToJSONL(jsonPath, jsonlPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	h, err := NewJSONLStore(jsonlPath).Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 3)
+	assert.Equal(t, "3", h.Sessions[2].ID)
+
+	// Original is retired, not deleted
+	_, err = os.Stat(jsonPath)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(jsonPath + ".migrated")
+	assert.NoError(t, err)
+
+	// Second run is a no-op
+	n, err = MigrateToJSONL(jsonPath, jsonlPath)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestMigrateToJSONL_ExistingTargetUntouched(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "history.json")
+	jsonlPath := filepath.Join(dir, "history.jsonl")
+
+	require.NoError(t, Save(&History{Version: "1.0", Sessions: []Session{testSession("old")}}, jsonPath))
+	require.NoError(t, NewJSONLStore(jsonlPath).Append(testSession("new")))
+
+	n, err := MigrateToJSONL(jsonPath, jsonlPath)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	h, err := NewJSONLStore(jsonlPath).Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 1)
+	assert.Equal(t, "new", h.Sessions[0].ID)
+}
+
+func TestOpen_MigratesOnFirstJSONLOpen(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, Save(&History{Version: "1.0", Sessions: []Session{testSession("legacy")}}, filepath.Join(dir, "history.json")))
+
+	store, err := Open(BackendJSONL, dir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	require.NoError(t, store.Append(testSession("fresh")))
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+	assert.Equal(t, "legacy", h.Sessions[0].ID)
+	assert.Equal(t, "fresh", h.Sessions[1].ID)
+}
+
+func TestOpen_UnknownBackend(t *testing.T) {
+	_, err := Open("csv", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown history backend")
+}
+
+func countLines(data []byte) int {
+	n := 0
+	for _, b := range data {
+		if b == '\n' {
+			n++
+		}
+	}
+	return n
+}
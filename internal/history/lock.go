@@ -0,0 +1,62 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockPath returns the path of the lock file guarding the history file at path
+func lockPath(path string) string {
+	return path + ".lock"
+}
+
+// withLock runs fn while holding an exclusive, cross-process lock on path
+func withLock(path string, fn func() error) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	unlock, err := lockFile(lockPath(path))
+	if err != nil {
+		return fmt.Errorf("failed to lock history file: %w", err)
+	}
+	defer unlock()
+
+	return fn()
+}
+
+// WithLock runs fn while holding the lock that writers of the history or
+// audit file at path take, so the file can be rewritten elsewhere, such as
+// when encrypting it, without losing a concurrent write
+func WithLock(path string, fn func() error) error {
+	return withLock(path, fn)
+}
+
+// Update performs a locked read-modify-write of the history file at path.
+// The lock is held across Load, fn and Save so concurrent writers in this or
+// other processes never lose each other's changes. If fn returns an error the
+// file is left untouched.
+func Update(path string, fn func(h *History) error) error {
+	return withLock(path, func() error {
+		h, err := Load(path)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(h); err != nil {
+			return err
+		}
+
+		return Save(h, path)
+	})
+}
+
+// Append adds a session to the history file at path under an exclusive lock
+func Append(path string, session Session) error {
+	return Update(path, func(h *History) error {
+		h.AddSession(session)
+		return nil
+	})
+}
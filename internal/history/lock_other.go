@@ -0,0 +1,39 @@
+//go:build !unix
+
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	lockStaleAfter    = 30 * time.Second
+)
+
+// lockFile acquires an exclusive lock by creating the lock file with O_EXCL,
+// retrying until it succeeds. Lock files older than lockStaleAfter are assumed
+// to belong to a crashed process and are removed.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(path)
+			continue
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
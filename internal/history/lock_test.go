@@ -0,0 +1,145 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	appendHelperEnv = "HISTORY_APPEND_HELPER"
+	stressProcesses = 4
+	stressWorkers   = 4
+	stressAppends   = 10
+)
+
+func TestAppend_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "history.json")
+
+	err := Append(path, Session{ID: "first", Label: "First", EndStatus: "completed", PausedDuration: "0s"})
+	require.NoError(t, err)
+
+	h, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 1)
+	assert.Equal(t, "first", h.Sessions[0].ID)
+	assert.Equal(t, "1.0", h.Version)
+}
+
+func TestUpdate_ErrorLeavesFileUntouched(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, Append(path, Session{ID: "kept", EndStatus: "completed"}))
+
+	boom := errors.New("boom")
+	err := Update(path, func(h *History) error {
+		h.AddSession(Session{ID: "dropped"})
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+
+	h, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 1)
+	assert.Equal(t, "kept", h.Sessions[0].ID)
+}
+
+func TestUpdate_CorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, os.WriteFile(path, []byte("{ invalid json }"), 0600))
+
+	err := Append(path, Session{ID: "lost"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse history file")
+}
+
+// TestAppend_ConcurrentProcesses runs many appenders across several processes
+// and goroutines against one file and checks that no session is lost
+func TestAppend_ConcurrentProcesses(t *testing.T) {
+	if os.Getenv(appendHelperEnv) != "" {
+		runAppendHelper(t)
+		return
+	}
+	if testing.Short() {
+		t.Skip("skipping multi-process stress test in short mode")
+	}
+
+	path := filepath.Join(t.TempDir(), "history.json")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, stressProcesses)
+	for p := 0; p < stressProcesses; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestAppend_ConcurrentProcesses$")
+			cmd.Env = append(os.Environ(),
+				appendHelperEnv+"="+strconv.Itoa(p),
+				"HISTORY_APPEND_PATH="+path,
+			)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("helper %d failed: %w\n%s", p, err, out)
+			}
+		}(p)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	h, err := Load(path)
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, stressProcesses*stressWorkers*stressAppends)
+
+	seen := make(map[string]bool, len(h.Sessions))
+	for _, s := range h.Sessions {
+		assert.False(t, seen[s.ID], "duplicate session %s", s.ID)
+		seen[s.ID] = true
+	}
+	for p := 0; p < stressProcesses; p++ {
+		for w := 0; w < stressWorkers; w++ {
+			for i := 0; i < stressAppends; i++ {
+				id := fmt.Sprintf("p%d-w%d-%d", p, w, i)
+				assert.True(t, seen[id], "lost session %s", id)
+			}
+		}
+	}
+}
+
+// runAppendHelper is the body of one appender process in the stress test
+func runAppendHelper(t *testing.T) {
+	proc := os.Getenv(appendHelperEnv)
+	path := os.Getenv("HISTORY_APPEND_PATH")
+
+	var wg sync.WaitGroup
+	for w := 0; w < stressWorkers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < stressAppends; i++ {
+				session := Session{
+					ID:             fmt.Sprintf("p%s-w%d-%d", proc, w, i),
+					StartedAt:      time.Now(),
+					EndedAt:        time.Now(),
+					Duration:       "25m",
+					Label:          "Stress",
+					EndStatus:      "completed",
+					PausedDuration: "0s",
+				}
+				if err := Append(path, session); err != nil {
+					t.Errorf("append failed: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
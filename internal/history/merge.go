@@ -0,0 +1,239 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/encryption"
+)
+
+// MergeResult describes what merging other copies into a history changed
+type MergeResult struct {
+	Added    []Session     // Records of sessions found only in other copies
+	Updated  []MergeUpdate // Sessions replaced by a newer version from another copy
+	Overlaps []string      // Merged sessions that overlap a different session
+	Archived []Session     // Records of sessions already compacted into the archives
+}
+
+// MergeUpdate is a session whose records were replaced by another copy's
+type MergeUpdate struct {
+	Before []Session
+	After  []Session
+}
+
+// Merge unions the sessions of copies into h by session ID. When copies
+// disagree about a session the last modified version wins, then the one
+// with the highest revision, then the one with more records (a resumed
+// session); remaining ties are broken by content, so merging the same
+// copies in any order gives the same history. Sessions deleted in one copy
+// but present in another are kept.
+func Merge(h *History, copies ...*History) *MergeResult {
+	order, versions := groupByID(h.Sessions)
+	local := map[string][]Session{}
+	for id, records := range versions {
+		local[id] = records
+	}
+	for _, c := range copies {
+		copyOrder, copyVersions := groupByID(c.Sessions)
+		for _, id := range copyOrder {
+			current, ok := versions[id]
+			if !ok {
+				order = append(order, id)
+				versions[id] = copyVersions[id]
+				continue
+			}
+			if compareVersions(copyVersions[id], current) > 0 {
+				versions[id] = copyVersions[id]
+			}
+		}
+	}
+
+	result := &MergeResult{}
+	var changed []Session
+	for _, id := range order {
+		before, existed := local[id]
+		after := versions[id]
+		switch {
+		case !existed:
+			result.Added = append(result.Added, after...)
+			changed = append(changed, after...)
+		case compareVersions(after, before) != 0:
+			result.Updated = append(result.Updated, MergeUpdate{Before: before, After: after})
+			changed = append(changed, after...)
+		}
+	}
+	if len(changed) == 0 {
+		return result
+	}
+
+	h.Sessions = h.Sessions[:0:0]
+	for _, id := range order {
+		h.Sessions = append(h.Sessions, versions[id]...)
+	}
+	sort.SliceStable(h.Sessions, func(i, j int) bool {
+		return h.Sessions[i].StartedAt.Before(h.Sessions[j].StartedAt)
+	})
+	for _, s := range changed {
+		if err := h.CheckOverlap(s); err != nil {
+			result.Overlaps = append(result.Overlaps, fmt.Sprintf("%s (%s, %s) %s", ShortID(s.ID), s.Label,
+				s.StartedAt.Local().Format("2006-01-02 15:04"), strings.TrimPrefix(err.Error(), "session ")))
+		}
+	}
+	return result
+}
+
+// groupByID returns the distinct session IDs in order of first appearance
+// and the records of each
+func groupByID(sessions []Session) ([]string, map[string][]Session) {
+	var order []string
+	records := map[string][]Session{}
+	for _, s := range sessions {
+		if _, ok := records[s.ID]; !ok {
+			order = append(order, s.ID)
+		}
+		records[s.ID] = append(records[s.ID], s)
+	}
+	return order, records
+}
+
+// compareVersions orders two versions of a session's records, returning a
+// positive number when a should win over b and 0 when they are identical
+func compareVersions(a, b []Session) int {
+	revA, modA := latestRevision(a)
+	revB, modB := latestRevision(b)
+	if c := modA.Compare(modB); c != 0 {
+		return c
+	}
+	if revA != revB {
+		return revA - revB
+	}
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	dataA, _ := json.Marshal(a)
+	dataB, _ := json.Marshal(b)
+	return bytes.Compare(dataA, dataB)
+}
+
+func latestRevision(records []Session) (int, time.Time) {
+	var revision int
+	var modified time.Time
+	for _, s := range records {
+		revision = max(revision, s.Revision)
+		if s.ModifiedAt.After(modified) {
+			modified = s.ModifiedAt
+		}
+	}
+	return revision, modified
+}
+
+// LoadCopy reads a copy of the history from a file of any backend, chosen
+// by extension: .jsonl, .db or otherwise a history.json document. Copies
+// may be unencrypted even when this history is encrypted.
+func LoadCopy(path string) (*History, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	switch filepath.Ext(path) {
+	case ".jsonl":
+		return NewJSONLStore(path).Load()
+	case ".db":
+		store, err := OpenSQLiteStore(path)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+		return store.Load()
+	default:
+		return load(path, encryption.DecryptForeign)
+	}
+}
+
+// Merge merges copies into the store in one exclusive update and records
+// each change in the audit log. Sessions already compacted into the archives
+// are left out. With dryRun set the history is left unchanged.
+func (e *Editor) Merge(copies []*History, dryRun bool) (*MergeResult, error) {
+	archived, err := e.archived()
+	if err != nil {
+		return nil, err
+	}
+	copies, skipped := withoutArchived(copies, archived)
+
+	if dryRun {
+		h, err := e.store.Load()
+		if err != nil {
+			return nil, err
+		}
+		result := Merge(h, copies...)
+		result.Archived = skipped
+		return result, nil
+	}
+
+	var result *MergeResult
+	err = e.store.Update(func(h *History) error {
+		result = Merge(h, copies...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Archived = skipped
+
+	var entries []AuditEntry
+	for _, s := range result.Added {
+		s := s
+		entries = append(entries, AuditEntry{Action: ActionAdd, SessionID: s.ID, After: &s})
+	}
+	for _, u := range result.Updated {
+		if len(u.Before) == 1 && len(u.After) == 1 {
+			entries = append(entries, AuditEntry{Action: ActionEdit, SessionID: u.After[0].ID, Before: &u.Before[0], After: &u.After[0]})
+			continue
+		}
+		for i := range u.Before {
+			entries = append(entries, AuditEntry{Action: ActionDelete, SessionID: u.Before[i].ID, Before: &u.Before[i]})
+		}
+		for i := range u.After {
+			entries = append(entries, AuditEntry{Action: ActionAdd, SessionID: u.After[i].ID, After: &u.After[i]})
+		}
+	}
+	for _, entry := range entries {
+		if _, err := e.audit.Record(entry); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// withoutArchived removes the records of archived sessions from copies and
+// returns them separately. Sessions are matched by ID, so versions edited
+// since they were archived are left out too.
+func withoutArchived(copies []*History, archived []Session) ([]*History, []Session) {
+	if len(archived) == 0 {
+		return copies, nil
+	}
+	ids := map[string]bool{}
+	for _, s := range archived {
+		ids[s.ID] = true
+	}
+
+	var skipped []Session
+	filtered := make([]*History, len(copies))
+	for i, c := range copies {
+		kept := &History{Version: c.Version}
+		for _, s := range c.Sessions {
+			if ids[s.ID] {
+				skipped = append(skipped, s)
+			} else {
+				kept.Sessions = append(kept.Sessions, s)
+			}
+		}
+		filtered[i] = kept
+	}
+	return filtered, skipped
+}
@@ -0,0 +1,233 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// edited returns s with a new label at the given revision
+func edited(s Session, label string, revision int, minute int) Session {
+	s.Label = label
+	s.Revision = revision
+	s.ModifiedAt = time.Date(2025, 1, 16, 8, minute, 0, 0, time.UTC)
+	return s
+}
+
+func TestMerge(t *testing.T) {
+	a := sessionAt("a", 9, "A", "work", "completed")
+	b := sessionAt("b", 10, "B", "work", "completed")
+	c := sessionAt("c", 11, "C", "work", "completed")
+	// The records of a resumed session share an ID and start
+	interrupted := sessionAt("r", 13, "R", "work", "interrupted")
+	interrupted.EndedAt = interrupted.StartedAt.Add(10 * time.Minute)
+	resumed := sessionAt("r", 13, "R", "work", "completed")
+
+	laptop := &History{Version: "1.0", Sessions: []Session{edited(a, "A laptop", 1, 30), b, interrupted}}
+	desktop := &History{Version: "1.0", Sessions: []Session{
+		edited(a, "A desktop", 1, 10), edited(b, "B desktop", 2, 0), c, interrupted, resumed,
+	}}
+
+	result := Merge(laptop, desktop)
+	assert.Equal(t, []string{"a", "b", "c", "r", "r"}, ids(laptop.Sessions))
+	assert.Equal(t, "A laptop", laptop.Sessions[0].Label, "same revision: later modification wins")
+	assert.Equal(t, "B desktop", laptop.Sessions[1].Label, "edits win over the unedited session")
+
+	assert.Equal(t, []string{"c"}, ids(result.Added))
+	require.Len(t, result.Updated, 2)
+	assert.Equal(t, "B", result.Updated[0].Before[0].Label)
+	assert.Len(t, result.Updated[1].After, 2, "the resumed session's records replace the interrupted one")
+	assert.Empty(t, result.Overlaps)
+
+	// Merging again, or the other way round, converges on the same history
+	assert.Empty(t, Merge(laptop, desktop).Added)
+	desktopFirst := &History{Version: "1.0", Sessions: append([]Session(nil), desktop.Sessions...)}
+	Merge(desktopFirst, &History{Version: "1.0", Sessions: []Session{edited(a, "A laptop", 1, 30), b, interrupted}})
+	assert.Equal(t, laptop.Sessions, desktopFirst.Sessions)
+}
+
+func TestMerge_LastModificationWins(t *testing.T) {
+	a := sessionAt("a", 9, "A", "work", "completed")
+	// Edited twice early on one machine, once later on the other
+	often := edited(a, "Edited twice", 2, 10)
+	recent := edited(a, "Edited last", 1, 30)
+
+	h := &History{Sessions: []Session{often}}
+	Merge(h, &History{Sessions: []Session{recent}})
+	assert.Equal(t, "Edited last", h.Sessions[0].Label)
+
+	h = &History{Sessions: []Session{recent}}
+	Merge(h, &History{Sessions: []Session{often}})
+	assert.Equal(t, "Edited last", h.Sessions[0].Label, "merge order does not matter")
+
+	// Revisions break ties between edits made at the same time
+	h = &History{Sessions: []Session{edited(a, "Edited twice", 2, 30)}}
+	Merge(h, &History{Sessions: []Session{recent}})
+	assert.Equal(t, "Edited twice", h.Sessions[0].Label)
+}
+
+func TestMerge_TiesAreBrokenByContent(t *testing.T) {
+	one := sessionAt("a", 9, "One", "work", "completed")
+	two := sessionAt("a", 9, "Two", "work", "completed")
+
+	h1 := &History{Sessions: []Session{one}}
+	Merge(h1, &History{Sessions: []Session{two}})
+	h2 := &History{Sessions: []Session{two}}
+	Merge(h2, &History{Sessions: []Session{one}})
+	assert.Equal(t, h1.Sessions, h2.Sessions)
+}
+
+func TestMerge_ReportsOverlaps(t *testing.T) {
+	h := &History{Sessions: []Session{sessionAt("a", 9, "A", "work", "completed")}}
+	other := sessionAt("b", 9, "B", "work", "completed")
+	other.StartedAt = other.StartedAt.Add(10 * time.Minute)
+	other.EndedAt = other.EndedAt.Add(10 * time.Minute)
+
+	result := Merge(h, &History{Sessions: []Session{other}})
+	assert.Len(t, h.Sessions, 2, "overlapping sessions are still merged")
+	require.Len(t, result.Overlaps, 1)
+	assert.Contains(t, result.Overlaps[0], "overlaps a")
+}
+
+func TestEditor_EditBumpsRevision(t *testing.T) {
+	editor, store, _ := newTestEditor(t, BackendJSON)
+	now := time.Date(2025, 1, 16, 8, 0, 0, 0, time.UTC)
+	editor.now = func() time.Time { return now }
+	require.NoError(t, store.Append(sessionAt("a", 9, "A", "work", "completed")))
+
+	_, after, err := editor.Edit("a", func(s *Session) error { s.Label = "A2"; return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 1, after.Revision)
+	assert.Equal(t, now, after.ModifiedAt)
+
+	// Undoing the edit is a newer revision of the original
+	now = now.Add(time.Hour)
+	_, err = editor.Undo()
+	require.NoError(t, err)
+	s, err := editor.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "A", s.Label)
+	assert.Equal(t, 2, s.Revision)
+	assert.Equal(t, now, s.ModifiedAt)
+}
+
+func TestEditor_Merge(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			editor, store, audit := newTestEditor(t, backend)
+			a := sessionAt("a", 9, "A", "work", "completed")
+			require.NoError(t, store.Append(a))
+
+			other := &History{Version: "1.0", Sessions: []Session{edited(a, "A edited", 1, 0), sessionAt("b", 10, "B", "work", "completed")}}
+			path := filepath.Join(t.TempDir(), "history (conflicted copy).json")
+			require.NoError(t, Save(other, path))
+			loaded, err := LoadCopy(path)
+			require.NoError(t, err)
+
+			result, err := editor.Merge([]*History{loaded}, true)
+			require.NoError(t, err)
+			assert.Len(t, result.Added, 1)
+			h, err := store.Load()
+			require.NoError(t, err)
+			assert.Equal(t, "A", h.Sessions[0].Label, "dry run leaves the history unchanged")
+
+			_, err = editor.Merge([]*History{loaded}, false)
+			require.NoError(t, err)
+			h, err = store.Load()
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b"}, ids(h.Sessions))
+			assert.Equal(t, "A edited", h.Sessions[0].Label)
+
+			entries, err := audit.Entries()
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			assert.Equal(t, ActionAdd, entries[0].Action)
+			assert.Equal(t, ActionEdit, entries[1].Action)
+		})
+	}
+}
+
+func TestEditor_MergeAfterCompact(t *testing.T) {
+	for _, rollupOnly := range []bool{false, true} {
+		editor, store, _ := newTestEditor(t, BackendJSON)
+		archiveDir := filepath.Join(t.TempDir(), ArchiveDirName)
+		editor.SetArchiveDir(archiveDir)
+		old := []Session{
+			sessionOn("a", 2025, time.January, 10, 9, "A"),
+			sessionOn("b", 2025, time.January, 11, 9, "B"),
+		}
+		recent := sessionOn("c", 2025, time.March, 2, 9, "C")
+		for _, s := range append(old, recent) {
+			require.NoError(t, store.Append(s))
+		}
+		// Another machine's copy still holds the sessions compacted here
+		other := &History{Version: "1.0", Sessions: append(old, recent, sessionOn("d", 2025, time.March, 3, 9, "D"))}
+
+		_, err := Compact(store, archiveDir, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local), rollupOnly, false)
+		require.NoError(t, err)
+
+		result, err := editor.Merge([]*History{other}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"d"}, ids(result.Added))
+		assert.Equal(t, []string{"a", "b"}, ids(result.Archived))
+
+		sessions, err := NewArchivedStore(store, archiveDir).Between(time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, sessions, 4, "rollup only: %v", rollupOnly)
+
+		// Imports of the same sessions count them as duplicates too
+		plan, err := editor.Import(other.Sessions, DedupID, 0, true)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Added)
+		assert.Len(t, plan.Duplicates, 4)
+	}
+}
+
+func TestEditor_MergePlaintextCopyWhileEncrypted(t *testing.T) {
+	// Copies from a machine without encryption are plaintext
+	copies := t.TempDir()
+	merged := filepath.Join(copies, "history.json")
+	require.NoError(t, Save(&History{Version: "1.0", Sessions: []Session{sessionAt("b", 10, "B", "work", "completed")}}, merged))
+	imported := filepath.Join(copies, "export.json")
+	require.NoError(t, Save(&History{Version: "1.0", Sessions: []Session{sessionAt("c", 11, "C", "work", "completed")}}, imported))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "history.json")
+	store := NewJSONStore(path)
+	require.NoError(t, store.Append(sessionAt("a", 9, "A", "work", "completed")))
+	keyfile := filepath.Join(dir, "history.key")
+	require.NoError(t, encryption.GenerateKeyfile(keyfile))
+	d, kek, err := encryption.NewDescriptor("", keyfile)
+	require.NoError(t, err)
+	_, err = encryption.Enable(dir, d, kek, encryption.Files{Documents: []string{path}})
+	require.NoError(t, err)
+	t.Cleanup(func() { encryption.Unlock(nil) })
+	editor := NewEditor(store, NewAuditLog(filepath.Join(dir, AuditFileName)))
+
+	other, err := LoadCopy(merged)
+	require.NoError(t, err)
+	result, err := editor.Merge([]*History{other}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, ids(result.Added))
+
+	other, err = LoadCopy(imported)
+	require.NoError(t, err)
+	plan, err := editor.Import(other.Sessions, DedupID, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, ids(plan.Added))
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, ids(h.Sessions))
+	_, err = Load(merged)
+	assert.ErrorIs(t, err, encryption.ErrNotEncrypted, "the history's own files must still be encrypted")
+}
+
+func TestLoadCopy_MissingFile(t *testing.T) {
+	_, err := LoadCopy(filepath.Join(t.TempDir(), "history.json"))
+	assert.Error(t, err)
+}
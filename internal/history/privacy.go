@@ -0,0 +1,119 @@
+package history
+
+import (
+	"time"
+
+	"github.com/pomodux/pomodux/internal/privacy"
+)
+
+// Redacted returns the session as it may be shown outside the history:
+// private sessions lose their label, notes, project and tags, others have
+// them masked with mode (plain, hash or redact)
+func (s Session) Redacted(mode string) Session {
+	if s.Private {
+		s.Label = privacy.PrivateLabel
+		s.Notes = ""
+		s.Project = ""
+		s.Tags = nil
+		return s
+	}
+	s.Label = privacy.Mask(s.Label, mode)
+	s.Notes = privacy.Mask(s.Notes, mode)
+	s.Project = privacy.Mask(s.Project, mode)
+	s.Tags = privacy.MaskAll(s.Tags, mode)
+	return s
+}
+
+// RedactedStore reads a store with every session Redacted, for output
+// leaving the history. Label, project and tag filters never match private
+// sessions. Writes go to the store unchanged.
+type RedactedStore struct {
+	Store
+	mode string
+}
+
+// NewRedactedStore wraps store to redact sessions with mode
+func NewRedactedStore(store Store, mode string) *RedactedStore {
+	return &RedactedStore{Store: store, mode: mode}
+}
+
+// redact redacts sessions in place
+func (s *RedactedStore) redact(sessions []Session) []Session {
+	for i := range sessions {
+		sessions[i] = sessions[i].Redacted(s.mode)
+	}
+	return sessions
+}
+
+// Load returns every session, redacted
+func (s *RedactedStore) Load() (*History, error) {
+	h, err := s.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &History{Version: h.Version, Sessions: s.redact(h.Sessions)}, nil
+}
+
+// Between returns the redacted sessions started in [from, to)
+func (s *RedactedStore) Between(from, to time.Time) ([]Session, error) {
+	sessions, err := s.Store.Between(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.redact(sessions), nil
+}
+
+// Query returns one page of redacted sessions
+func (s *RedactedStore) Query(q Query) (*Page, error) {
+	page, err := s.Store.Query(q.redacted())
+	if err != nil {
+		return nil, err
+	}
+	page.Sessions = s.redact(page.Sessions)
+	return page, nil
+}
+
+// Walk calls fn for each redacted session matching q
+func (s *RedactedStore) Walk(q Query, fn func(Session) error) error {
+	return Walk(s.Store, q.redacted(), func(session Session) error {
+		return fn(session.Redacted(s.mode))
+	})
+}
+
+// redacted returns q leaving out private sessions when it selects sessions
+// by label, or by the project and tags parsed from it, so the store pages
+// over the sessions that are kept
+func (q Query) redacted() Query {
+	if q.Label != "" || q.LabelRegex != nil || len(q.Projects) > 0 || len(q.Tags) > 0 {
+		q.ExcludePrivate = true
+	}
+	return q
+}
+
+// Totals groups sessions like the store does, masking label, project and tag
+// keys. Private sessions already share one key.
+func (s *RedactedStore) Totals(from, to time.Time, field string) ([]Total, error) {
+	totals, err := Totals(s.Store, from, to, field)
+	masks := field == GroupByLabel || field == GroupByProject || field == GroupByTag
+	if err != nil || !masks || s.mode == privacy.ModePlain {
+		return totals, err
+	}
+
+	index := map[string]int{}
+	var masked []Total
+	for _, t := range totals {
+		if t.Key != privacy.PrivateLabel {
+			t.Key = privacy.Mask(t.Key, s.mode)
+		}
+		i, ok := index[t.Key]
+		if !ok {
+			index[t.Key] = len(masked)
+			masked = append(masked, t)
+			continue
+		}
+		masked[i].Sessions += t.Sessions
+		masked[i].Focus += t.Focus
+	}
+	SortTotals(masked)
+	return masked, nil
+}
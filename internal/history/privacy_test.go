@@ -0,0 +1,130 @@
+package history
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/privacy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_Redacted(t *testing.T) {
+	s := Session{ID: "a", Label: "Write report +acme @billable @call", Project: "acme", Tags: []string{"billable", "call"}, Notes: "draft"}
+	assert.Equal(t, s, s.Redacted(privacy.ModePlain))
+
+	redacted := s.Redacted(privacy.ModeRedact)
+	assert.Equal(t, privacy.Redacted, redacted.Label)
+	assert.Equal(t, privacy.Redacted, redacted.Notes)
+	assert.Equal(t, privacy.Redacted, redacted.Project)
+	assert.Equal(t, []string{privacy.Redacted}, redacted.Tags)
+	assert.Equal(t, "Write report +acme @billable @call", s.Label, "the original is unchanged")
+	assert.Equal(t, []string{"billable", "call"}, s.Tags)
+
+	hashed := s.Redacted(privacy.ModeHash)
+	assert.Equal(t, privacy.Mask("acme", privacy.ModeHash), hashed.Project)
+	assert.Len(t, hashed.Tags, 2)
+
+	s.Private = true
+	private := s.Redacted(privacy.ModePlain)
+	assert.Equal(t, privacy.PrivateLabel, private.Label)
+	assert.Empty(t, private.Notes)
+	assert.Empty(t, private.Project)
+	assert.Empty(t, private.Tags)
+}
+
+func TestRedactedStore(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			require.NoError(t, err)
+			t.Cleanup(func() { store.Close() })
+
+			secret := sessionOn("a", 2025, time.January, 10, 9, "Doctor")
+			secret.Private = true
+			secret.Project, secret.Tags = "acme", []string{"billable"}
+			report := sessionOn("b", 2025, time.January, 10, 10, "Write report")
+			report.Project, report.Tags = "acme", []string{"billable"}
+			for _, s := range []Session{
+				secret,
+				report,
+				sessionOn("c", 2025, time.January, 10, 11, "Doctor"),
+			} {
+				require.NoError(t, store.Append(s))
+			}
+
+			redacted := NewRedactedStore(store, privacy.ModePlain)
+			h, err := redacted.Load()
+			require.NoError(t, err)
+			labels := []string{}
+			for _, s := range h.Sessions {
+				labels = append(labels, s.Label)
+			}
+			assert.Equal(t, []string{privacy.PrivateLabel, "Write report", "Doctor"}, labels)
+
+			page, err := redacted.Query(Query{Label: "doctor"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"c"}, ids(page.Sessions), "label filters never match private sessions")
+			var walked []string
+			require.NoError(t, Walk(redacted, Query{LabelRegex: regexp.MustCompile("Doc")}, func(s Session) error {
+				walked = append(walked, s.ID)
+				return nil
+			}))
+			assert.Equal(t, []string{"c"}, walked)
+			page, err = redacted.Query(Query{Projects: []string{"acme"}})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b"}, ids(page.Sessions), "project filters never match private sessions")
+
+			totals, err := Totals(redacted, time.Time{}, time.Time{}, GroupByLabel)
+			require.NoError(t, err)
+			keys := []string{}
+			for _, total := range totals {
+				keys = append(keys, total.Key)
+			}
+			assert.ElementsMatch(t, []string{privacy.PrivateLabel, "Write report", "Doctor"}, keys)
+
+			totals, err = Totals(NewRedactedStore(store, privacy.ModeRedact), time.Time{}, time.Time{}, GroupByLabel)
+			require.NoError(t, err)
+			require.Len(t, totals, 2)
+			assert.Equal(t, Total{Key: privacy.Redacted, Sessions: 2, Focus: 50 * time.Minute}, totals[0])
+			assert.Equal(t, privacy.PrivateLabel, totals[1].Key)
+
+			for _, field := range []string{GroupByProject, GroupByTag} {
+				totals, err = Totals(NewRedactedStore(store, privacy.ModeRedact), time.Time{}, time.Time{}, field)
+				require.NoError(t, err)
+				assert.Equal(t, []Total{
+					{Key: "", Sessions: 2, Focus: 50 * time.Minute},
+					{Key: privacy.Redacted, Sessions: 1, Focus: 25 * time.Minute},
+				}, totals, "private sessions have no %s", field)
+			}
+		})
+	}
+}
+
+func TestRedactedStore_PagesSkipPrivate(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			require.NoError(t, err)
+			t.Cleanup(func() { store.Close() })
+
+			for i, private := range []bool{false, true, true, false, true} {
+				s := sessionOn(string(rune('a'+i)), 2025, time.January, 10, 8+i, "Doctor")
+				s.Private = private
+				require.NoError(t, store.Append(s))
+			}
+
+			redacted := NewRedactedStore(store, privacy.ModePlain)
+			page, err := redacted.Query(Query{Label: "doctor", Limit: 1})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a"}, ids(page.Sessions))
+			require.NotEmpty(t, page.NextCursor)
+
+			page, err = redacted.Query(Query{Label: "doctor", Limit: 1, Cursor: page.NextCursor})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"d"}, ids(page.Sessions), "private sessions never fill a page")
+			assert.Empty(t, page.NextCursor, "no page of only private sessions follows")
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package history
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid history cursor")
+
+// SortOrder controls the order of query results
+type SortOrder int
+
+const (
+	// OldestFirst sorts by start time ascending
+	OldestFirst SortOrder = iota
+	// NewestFirst sorts by start time descending
+	NewestFirst
+)
+
+// Query selects sessions from the history. Zero-valued fields do not filter.
+// Results are sorted by start time (ties broken by ID) and paginated with
+// Limit and the opaque cursor returned in Page.NextCursor.
+type Query struct {
+	From           time.Time      // Sessions started at or after From
+	To             time.Time      // Sessions started before To
+	Label          string         // Case-insensitive label substring
+	LabelRegex     *regexp.Regexp // Label must match
+	Presets        []string       // Preset must be one of these
+	EndStatuses    []string       // End status must be one of these
+	Projects       []string       // Project must be one of these
+	MinDuration    time.Duration  // Minimum focus duration
+	Tags           []string       // Session must carry all of these tags
+	ExcludePrivate bool           // Leave out private sessions
+	Order          SortOrder
+	Limit          int    // Page size, 0 for no limit
+	Cursor         string // NextCursor of the previous page
+}
+
+// Page is one page of query results
+type Page struct {
+	Sessions   []Session
+	NextCursor string // Empty on the last page
+}
+
+// Matches reports whether a session satisfies every filter of the query.
+// Pagination fields are ignored.
+func (q Query) Matches(s Session) bool {
+	if !q.From.IsZero() && s.StartedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !s.StartedAt.Before(q.To) {
+		return false
+	}
+	if q.Label != "" && !strings.Contains(strings.ToLower(s.Label), strings.ToLower(q.Label)) {
+		return false
+	}
+	if q.LabelRegex != nil && !q.LabelRegex.MatchString(s.Label) {
+		return false
+	}
+	if len(q.Presets) > 0 && !contains(q.Presets, s.Preset) {
+		return false
+	}
+	if len(q.EndStatuses) > 0 && !contains(q.EndStatuses, s.EndStatus) {
+		return false
+	}
+	if len(q.Projects) > 0 && !contains(q.Projects, s.Project) {
+		return false
+	}
+	if q.ExcludePrivate && s.Private {
+		return false
+	}
+	if q.MinDuration > 0 && s.FocusDuration() < q.MinDuration {
+		return false
+	}
+	for _, tag := range q.Tags {
+		if !contains(s.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query runs q against the sessions held in memory
+func (h *History) Query(q Query) (*Page, error) {
+	return q.Apply(h.Sessions)
+}
+
+// Apply filters, sorts and paginates sessions in memory
+func (q Query) Apply(sessions []Session) (*Page, error) {
+	matched := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		if q.Matches(s) {
+			matched = append(matched, s)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return q.before(cursorKeyOf(matched[i]), cursorKeyOf(matched[j]))
+	})
+
+	pager, err := q.newPager()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range matched {
+		if pager.add(s) {
+			break
+		}
+	}
+	return pager.page(), nil
+}
+
+// DayRange returns the start of t's calendar day and the start of the next
+// day, both in t's location, so DST transitions yield 23 or 25 hour days
+func DayRange(t time.Time) (time.Time, time.Time) {
+	y, m, d := t.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return start, time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+// ParseDay parses a YYYY-MM-DD date as midnight in loc
+func ParseDay(value string, loc *time.Location) (time.Time, error) {
+	day, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD): %w", value, err)
+	}
+	return day, nil
+}
+
+// ParseTime parses a timestamp given as RFC 3339, "YYYY-MM-DD HH:MM" or a bare
+// "HH:MM". Values without a zone use day's location; a bare clock time is
+// taken on day's calendar date.
+func ParseTime(value string, day time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, day.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if clock, err := time.Parse("15:04", value); err == nil {
+		y, m, d := day.Date()
+		return time.Date(y, m, d, clock.Hour(), clock.Minute(), 0, 0, day.Location()), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected HH:MM, YYYY-MM-DD HH:MM or RFC 3339)", value)
+}
+
+// cursorKey is the sort key of a session plus how many sessions sharing that
+// exact key were already returned (duplicate IDs occur for resumed sessions)
+type cursorKey struct {
+	startedAt int64
+	id        string
+	seen      int
+}
+
+func cursorKeyOf(s Session) cursorKey {
+	return cursorKey{startedAt: s.StartedAt.UnixNano(), id: s.ID}
+}
+
+// before reports whether a sorts before b in the query's order
+func (q Query) before(a, b cursorKey) bool {
+	if a.startedAt != b.startedAt {
+		if q.Order == NewestFirst {
+			return a.startedAt > b.startedAt
+		}
+		return a.startedAt < b.startedAt
+	}
+	if q.Order == NewestFirst {
+		return a.id > b.id
+	}
+	return a.id < b.id
+}
+
+func encodeCursor(k cursorKey) string {
+	raw := strconv.FormatInt(k.startedAt, 10) + ":" + strconv.Itoa(k.seen) + ":" + k.id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (cursorKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return cursorKey{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return cursorKey{}, ErrInvalidCursor
+	}
+	startedAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return cursorKey{}, ErrInvalidCursor
+	}
+	seen, err := strconv.Atoi(parts[1])
+	if err != nil || seen < 1 {
+		return cursorKey{}, ErrInvalidCursor
+	}
+	return cursorKey{startedAt: startedAt, id: parts[2], seen: seen}, nil
+}
+
+// pager collects sorted sessions into a page, skipping everything up to and
+// including the cursor position
+type pager struct {
+	q       Query
+	after   *cursorKey
+	skipped int
+	last    cursorKey
+	items   []Session
+	more    bool
+}
+
+func (q Query) newPager() (*pager, error) {
+	if q.Limit < 0 {
+		return nil, fmt.Errorf("query limit must not be negative, got %d", q.Limit)
+	}
+	p := &pager{q: q}
+	if q.Cursor != "" {
+		k, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		p.after = &k
+	}
+	return p, nil
+}
+
+// add offers the next session in sort order and reports whether the page is full
+func (p *pager) add(s Session) bool {
+	key := cursorKeyOf(s)
+
+	if p.after != nil {
+		sameKey := key.startedAt == p.after.startedAt && key.id == p.after.id
+		if sameKey && p.skipped < p.after.seen {
+			p.skipped++
+			return false
+		}
+		if !sameKey && !p.q.before(cursorKey{startedAt: p.after.startedAt, id: p.after.id}, key) {
+			return false
+		}
+	}
+
+	if p.q.Limit > 0 && len(p.items) == p.q.Limit {
+		p.more = true
+		return true
+	}
+
+	if key.startedAt == p.last.startedAt && key.id == p.last.id && len(p.items) > 0 {
+		p.last.seen++
+	} else {
+		p.last = key
+		p.last.seen = 1
+		if p.after != nil && key.startedAt == p.after.startedAt && key.id == p.after.id {
+			p.last.seen = p.after.seen + 1
+		}
+	}
+	p.items = append(p.items, s)
+	return false
+}
+
+func (p *pager) page() *Page {
+	page := &Page{Sessions: p.items}
+	if page.Sessions == nil {
+		page.Sessions = []Session{}
+	}
+	if p.more {
+		page.NextCursor = encodeCursor(p.last)
+	}
+	return page
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package history
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryFixture returns sessions with a mix of labels, presets, statuses and tags
+func queryFixture() []Session {
+	sessions := []Session{
+		sessionAt("a", 8, "Fix login bug", "work", "completed"),
+		sessionAt("b", 9, "Code review", "work", "stopped"),
+		sessionAt("c", 10, "Coffee", "break", "completed"),
+		sessionAt("d", 11, "Fix signup flow", "work", "completed"),
+		sessionAt("e", 12, "Café planning", "", "interrupted"),
+	}
+	sessions[0].Tags = []string{"bugfix", "backend"}
+	sessions[3].Tags = []string{"bugfix"}
+	// Stopped after 10 minutes
+	sessions[1].EndedAt = sessions[1].StartedAt.Add(10 * time.Minute)
+	return sessions
+}
+
+func ids(sessions []Session) []string {
+	result := make([]string, len(sessions))
+	for i, s := range sessions {
+		result[i] = s.ID
+	}
+	return result
+}
+
+func TestQuery_Filters(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"no filters", Query{}, []string{"a", "b", "c", "d", "e"}},
+		{"time range", Query{From: time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC), To: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)}, []string{"b", "c"}},
+		{"label substring case-insensitive", Query{Label: "FIX"}, []string{"a", "d"}},
+		{"label substring non-ASCII", Query{Label: "CAFÉ"}, []string{"e"}},
+		{"label regex", Query{LabelRegex: regexp.MustCompile(`^Co`)}, []string{"b", "c"}},
+		{"preset", Query{Presets: []string{"break"}}, []string{"c"}},
+		{"end status", Query{EndStatuses: []string{"stopped", "interrupted"}}, []string{"b", "e"}},
+		{"min duration", Query{MinDuration: 20 * time.Minute, Presets: []string{"work"}}, []string{"a", "d"}},
+		{"all tags required", Query{Tags: []string{"bugfix", "backend"}}, []string{"a"}},
+		{"single tag", Query{Tags: []string{"bugfix"}}, []string{"a", "d"}},
+		{"newest first", Query{Order: NewestFirst, Presets: []string{"work"}}, []string{"d", "b", "a"}},
+	}
+
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			require.NoError(t, err)
+			defer store.Close()
+			for _, s := range queryFixture() {
+				require.NoError(t, store.Append(s))
+			}
+
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					page, err := store.Query(tt.query)
+					require.NoError(t, err)
+					assert.Equal(t, tt.expected, ids(page.Sessions))
+					assert.Empty(t, page.NextCursor)
+				})
+			}
+		})
+	}
+}
+
+func TestQuery_CursorPagination(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		for _, order := range []SortOrder{OldestFirst, NewestFirst} {
+			t.Run(fmt.Sprintf("%s/order%d", backend, order), func(t *testing.T) {
+				store, err := Open(backend, t.TempDir())
+				require.NoError(t, err)
+				defer store.Close()
+
+				var expected []string
+				for i := 0; i < 7; i++ {
+					s := sessionAt(fmt.Sprintf("s%d", i), 8+i, "Work", "work", "completed")
+					require.NoError(t, store.Append(s))
+					expected = append(expected, s.ID)
+				}
+				if order == NewestFirst {
+					for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+						expected[i], expected[j] = expected[j], expected[i]
+					}
+				}
+
+				var got []string
+				q := Query{Order: order, Limit: 3}
+				pages := 0
+				for {
+					page, err := store.Query(q)
+					require.NoError(t, err)
+					pages++
+					got = append(got, ids(page.Sessions)...)
+					if page.NextCursor == "" {
+						break
+					}
+					q.Cursor = page.NextCursor
+				}
+				assert.Equal(t, 3, pages)
+				assert.Equal(t, expected, got)
+			})
+		}
+	}
+}
+
+func TestQuery_PaginationWithDuplicateIDs(t *testing.T) {
+	// A resumed session is recorded twice with the same ID and start time
+	interrupted := sessionAt("same", 9, "Work", "work", "interrupted")
+	completed := sessionAt("same", 9, "Work", "work", "completed")
+	h := &History{Version: "1.0", Sessions: []Session{
+		sessionAt("before", 8, "Work", "work", "completed"),
+		interrupted,
+		completed,
+		sessionAt("after", 10, "Work", "work", "completed"),
+	}}
+
+	var statuses []string
+	q := Query{Limit: 2}
+	for {
+		page, err := h.Query(q)
+		require.NoError(t, err)
+		for _, s := range page.Sessions {
+			statuses = append(statuses, s.ID+"/"+s.EndStatus)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		q.Cursor = page.NextCursor
+		q.Limit = 1
+	}
+	assert.Equal(t, []string{"before/completed", "same/interrupted", "same/completed", "after/completed"}, statuses)
+}
+
+func TestQuery_InvalidInput(t *testing.T) {
+	h := &History{Version: "1.0", Sessions: queryFixture()}
+
+	_, err := h.Query(Query{Cursor: "not a cursor!"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = h.Query(Query{Limit: -1})
+	assert.Error(t, err)
+}
+
+func TestDayRange(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	from, to := DayRange(time.Date(2025, 1, 15, 23, 30, 0, 0, loc))
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, loc), from)
+	assert.Equal(t, time.Date(2025, 1, 16, 0, 0, 0, 0, loc), to)
+
+	// A session at 20:00 UTC on the 15th falls on the 16th in UTC+5
+	s := sessionAt("late", 20, "Work", "work", "completed")
+	h := &History{Version: "1.0", Sessions: []Session{s}}
+	page, err := h.Query(Query{From: from, To: to})
+	require.NoError(t, err)
+	assert.Empty(t, page.Sessions)
+
+	from, to = DayRange(time.Date(2025, 1, 16, 12, 0, 0, 0, loc))
+	page, err = h.Query(Query{From: from, To: to})
+	require.NoError(t, err)
+	assert.Len(t, page.Sessions, 1)
+}
+
+func TestDayRange_DST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+	from, to := DayRange(time.Date(2025, 3, 9, 12, 0, 0, 0, loc))
+	assert.Equal(t, 23*time.Hour, to.Sub(from))
+}
+
+func TestParseDay(t *testing.T) {
+	day, err := ParseDay("2025-01-15", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), day)
+
+	_, err = ParseDay("15/01/2025", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	day := time.Date(2025, 1, 15, 18, 0, 0, 0, loc)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"10:30", time.Date(2025, 1, 15, 10, 30, 0, 0, loc)},
+		{"2025-01-14 09:00", time.Date(2025, 1, 14, 9, 0, 0, 0, loc)},
+		{"2025-01-14T09:00", time.Date(2025, 1, 14, 9, 0, 0, 0, loc)},
+		{"2025-01-14T09:00:00Z", time.Date(2025, 1, 14, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTime(tt.value, day)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(got), "got %v", got)
+		})
+	}
+
+	_, err := ParseTime("half past ten", day)
+	assert.Error(t, err)
+}
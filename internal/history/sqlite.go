@@ -0,0 +1,552 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/privacy"
+	_ "modernc.org/sqlite" // pure-Go SQLite driver
+)
+
+// sqliteColumns are the columns of the sessions table. Each record has its
+// own row, so the records of a resumed session share an id. Frequently
+// filtered fields get their own indexed columns; the complete session is kept
+// as JSON in data so fields added later round-trip without a schema
+// migration.
+const sqliteColumns = `
+	seq           INTEGER PRIMARY KEY AUTOINCREMENT,
+	id            TEXT NOT NULL,
+	started_at    INTEGER NOT NULL,
+	ended_at      INTEGER NOT NULL,
+	label         TEXT NOT NULL DEFAULT '',
+	preset        TEXT NOT NULL DEFAULT '',
+	end_status    TEXT NOT NULL DEFAULT '',
+	focus_seconds INTEGER NOT NULL DEFAULT 0,
+	data          TEXT NOT NULL
+`
+
+// sqliteSchema creates the sessions table and its indexes
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS sessions (` + sqliteColumns + `);
+CREATE INDEX IF NOT EXISTS idx_sessions_id ON sessions(id);
+CREATE INDEX IF NOT EXISTS idx_sessions_started_at ON sessions(started_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_label ON sessions(label);
+CREATE INDEX IF NOT EXISTS idx_sessions_preset ON sessions(preset);
+CREATE INDEX IF NOT EXISTS idx_sessions_end_status ON sessions(end_status);
+`
+
+// sqliteWalkPageSize is the number of sessions Walk reads per query
+const sqliteWalkPageSize = 500
+
+// groupExprs maps groupable session fields to the SQL expression yielding the
+// group key. Tags come from the tag join added by Totals; private sessions
+// share one label key and have no project or tags.
+var groupExprs = map[string]string{
+	GroupByLabel:     "CASE WHEN json_extract(data, '$.private') THEN '" + privacy.PrivateLabel + "' ELSE label END",
+	GroupByPreset:    "preset",
+	GroupByEndStatus: "end_status",
+	GroupByProject:   "CASE WHEN json_extract(data, '$.private') THEN '' ELSE COALESCE(json_extract(data, '$.project'), '') END",
+	GroupByTag:       "COALESCE(tag.value, '')",
+}
+
+// SQLiteStore keeps history in an indexed SQLite database, one row per
+// session record like the other backends
+type SQLiteStore struct {
+	path string
+	db   *sql.DB
+}
+
+// OpenSQLiteStore opens (creating if needed) the SQLite database at path
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// Immediate transactions take the write lock up front so concurrent
+	// read-modify-write updates serialize instead of failing
+	dsn := "file:" + path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(FULL)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	if err := migrateSQLiteRecords(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStore{path: path, db: db}, nil
+}
+
+// migrateSQLiteRecords rebuilds a sessions table keyed by id, from before
+// each record had its own row, with the new columns
+func migrateSQLiteRecords(db *sql.DB) error {
+	var hasSeq int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('sessions') WHERE name = 'seq'`).Scan(&hasSeq); err != nil {
+		return fmt.Errorf("failed to inspect history database: %w", err)
+	}
+	if hasSeq > 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin history database migration: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		`CREATE TABLE sessions_records (` + sqliteColumns + `)`,
+		`INSERT INTO sessions_records (id, started_at, ended_at, label, preset, end_status, focus_seconds, data)
+		 SELECT id, started_at, ended_at, label, preset, end_status, focus_seconds, data FROM sessions ORDER BY started_at, rowid`,
+		`DROP TABLE sessions`,
+		`ALTER TABLE sessions_records RENAME TO sessions`,
+		sqliteSchema,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to migrate history database: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to migrate history database: %w", err)
+	}
+	return nil
+}
+
+// Path returns the database file backing the store
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// Append inserts session as a new record
+func (s *SQLiteStore) Append(session Session) error {
+	if err := writeSession(s.db, 0, session); err != nil {
+		return fmt.Errorf("failed to append session: %w", err)
+	}
+	return nil
+}
+
+// Load returns every stored session ordered by start time
+func (s *SQLiteStore) Load() (*History, error) {
+	sessions, err := s.querySessions(`SELECT data FROM sessions ORDER BY started_at, seq`)
+	if err != nil {
+		return nil, err
+	}
+	return &History{Version: "1.0", Sessions: sessions}, nil
+}
+
+// sqliteRow is a stored record as read by Update
+type sqliteRow struct {
+	seq  int64
+	id   string
+	data string
+}
+
+// Update loads all sessions inside a transaction and writes back only the
+// records fn added, changed or removed. Changed records keep the row of a
+// record with their ID, so the records of a resumed session keep their order.
+func (s *SQLiteStore) Update(fn func(h *History) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin history update: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT seq, id, data FROM sessions ORDER BY started_at, seq`)
+	if err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+	var before []sqliteRow
+	h := &History{Version: "1.0", Sessions: []Session{}}
+	for rows.Next() {
+		var row sqliteRow
+		if err := rows.Scan(&row.seq, &row.id, &row.data); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read history row: %w", err)
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(row.data), &session); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to parse stored session: %w", err)
+		}
+		before = append(before, row)
+		h.Sessions = append(h.Sessions, session)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to query history: %w", err)
+	}
+
+	if err := fn(h); err != nil {
+		return err
+	}
+
+	// Unchanged records keep their rows
+	unchanged := map[string][]int{}
+	for i, row := range before {
+		unchanged[row.data] = append(unchanged[row.data], i)
+	}
+	kept := make([]bool, len(before))
+	var changed []Session
+	for _, session := range h.Sessions {
+		data, err := json.Marshal(session)
+		if err != nil {
+			return fmt.Errorf("failed to marshal session %s: %w", session.ID, err)
+		}
+		if rows := unchanged[string(data)]; len(rows) > 0 {
+			kept[rows[0]] = true
+			unchanged[string(data)] = rows[1:]
+			continue
+		}
+		changed = append(changed, session)
+	}
+
+	free := map[string][]int{}
+	for i, row := range before {
+		if !kept[i] {
+			free[row.id] = append(free[row.id], i)
+		}
+	}
+	for _, session := range changed {
+		var seq int64
+		if rows := free[session.ID]; len(rows) > 0 {
+			kept[rows[0]] = true
+			free[session.ID] = rows[1:]
+			seq = before[rows[0]].seq
+		}
+		if err := writeSession(tx, seq, session); err != nil {
+			return fmt.Errorf("failed to write session %s: %w", session.ID, err)
+		}
+	}
+	for i, row := range before {
+		if kept[i] {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM sessions WHERE seq = ?`, row.seq); err != nil {
+			return fmt.Errorf("failed to delete session %s: %w", row.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit history update: %w", err)
+	}
+	return nil
+}
+
+// Between returns sessions started in [from, to) using the started_at index.
+// A zero from or to leaves that side of the range open.
+func (s *SQLiteStore) Between(from, to time.Time) ([]Session, error) {
+	lo, hi := rangeBounds(from, to)
+	return s.querySessions(
+		`SELECT data FROM sessions WHERE started_at >= ? AND started_at < ? ORDER BY started_at, seq`,
+		lo, hi,
+	)
+}
+
+// Query translates the filters of q to SQL so only matching rows are read.
+// Filters SQL cannot express exactly (regular expressions, non-ASCII case
+// folding) are applied while streaming rows.
+func (s *SQLiteStore) Query(q Query) (*Page, error) {
+	pager, err := q.newPager()
+	if err != nil {
+		return nil, err
+	}
+
+	var where []string
+	var args []any
+
+	lo, hi := rangeBounds(q.From, q.To)
+	where = append(where, "started_at >= ? AND started_at < ?")
+	args = append(args, lo, hi)
+
+	if q.Label != "" && isASCII(q.Label) {
+		where = append(where, "instr(lower(label), lower(?)) > 0")
+		args = append(args, q.Label)
+	}
+	if len(q.Presets) > 0 {
+		where = append(where, "preset IN ("+placeholders(len(q.Presets))+")")
+		args = appendStrings(args, q.Presets)
+	}
+	if len(q.EndStatuses) > 0 {
+		where = append(where, "end_status IN ("+placeholders(len(q.EndStatuses))+")")
+		args = appendStrings(args, q.EndStatuses)
+	}
+	if len(q.Projects) > 0 {
+		where = append(where, "COALESCE(json_extract(data, '$.project'), '') IN ("+placeholders(len(q.Projects))+")")
+		args = appendStrings(args, q.Projects)
+	}
+	if q.ExcludePrivate {
+		where = append(where, "NOT COALESCE(json_extract(data, '$.private'), 0)")
+	}
+	if q.MinDuration > 0 {
+		where = append(where, "focus_seconds >= ?")
+		args = append(args, int64(q.MinDuration/time.Second))
+	}
+	for _, tag := range q.Tags {
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(sessions.data, '$.tags') WHERE json_each.value = ?)")
+		args = append(args, tag)
+	}
+
+	direction := "ASC"
+	if q.Order == NewestFirst {
+		direction = "DESC"
+	}
+	if pager.after != nil {
+		cmp := ">"
+		if q.Order == NewestFirst {
+			cmp = "<"
+		}
+		where = append(where, "(started_at "+cmp+" ? OR (started_at = ? AND id "+cmp+"= ?))")
+		args = append(args, pager.after.startedAt, pager.after.startedAt, pager.after.id)
+	}
+
+	rows, err := s.db.Query(
+		`SELECT data FROM sessions WHERE `+strings.Join(where, " AND ")+
+			` ORDER BY started_at `+direction+`, id `+direction+`, seq`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read history row: %w", err)
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, fmt.Errorf("failed to parse stored session: %w", err)
+		}
+		if !q.Matches(session) {
+			continue
+		}
+		if pager.add(session) {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+
+	return pager.page(), nil
+}
+
+// Walk streams the sessions matching q one index-backed page at a time
+func (s *SQLiteStore) Walk(q Query, fn func(Session) error) error {
+	remaining := q.Limit
+	for {
+		q.Limit = sqliteWalkPageSize
+		if remaining > 0 && remaining < q.Limit {
+			q.Limit = remaining
+		}
+
+		page, err := s.Query(q)
+		if err != nil {
+			return err
+		}
+		for _, session := range page.Sessions {
+			if err := fn(session); err != nil {
+				return err
+			}
+		}
+
+		if remaining > 0 {
+			remaining -= len(page.Sessions)
+			if remaining == 0 {
+				return nil
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// Totals aggregates sessions started in [from, to) by field inside the database
+func (s *SQLiteStore) Totals(from, to time.Time, field string) ([]Total, error) {
+	expr, ok := groupExprs[field]
+	if !ok {
+		return nil, fmt.Errorf("cannot group sessions by %q", field)
+	}
+
+	join := ""
+	if field == GroupByTag {
+		join = ` LEFT JOIN json_each(sessions.data, '$.tags') AS tag ON NOT COALESCE(json_extract(sessions.data, '$.private'), 0)`
+	}
+
+	lo, hi := rangeBounds(from, to)
+	rows, err := s.db.Query(
+		`SELECT `+expr+` AS group_key, COUNT(*), SUM(focus_seconds) FROM sessions`+join+`
+		 WHERE started_at >= ? AND started_at < ?
+		 GROUP BY group_key ORDER BY SUM(focus_seconds) DESC, group_key`,
+		lo, hi,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []Total
+	for rows.Next() {
+		var t Total
+		var seconds int64
+		if err := rows.Scan(&t.Key, &t.Sessions, &seconds); err != nil {
+			return nil, fmt.Errorf("failed to read history totals: %w", err)
+		}
+		t.Focus = time.Duration(seconds) * time.Second
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// ImportJSON copies all session records from the history.json document at
+// path into the database in one transaction. Records already present are
+// left alone, so importing the same file twice is harmless. Returns the
+// number imported.
+func (s *SQLiteStore) ImportJSON(path string) (int, error) {
+	h, err := Load(path)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	for _, session := range h.Sessions {
+		var exists int
+		err := tx.QueryRow(
+			`SELECT COUNT(*) FROM sessions WHERE id = ? AND started_at = ? AND ended_at = ? AND end_status = ?`,
+			session.ID, session.StartedAt.UnixNano(), session.EndedAt.UnixNano(), session.EndStatus,
+		).Scan(&exists)
+		if err != nil {
+			return 0, fmt.Errorf("failed to import session %s: %w", session.ID, err)
+		}
+		if exists > 0 {
+			continue
+		}
+		if err := writeSession(tx, 0, session); err != nil {
+			return 0, fmt.Errorf("failed to import session %s: %w", session.ID, err)
+		}
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return imported, nil
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// writeSession stores session in the row seq, or in a new row when seq is 0
+func writeSession(db execer, seq int64, session Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	args := []any{
+		session.ID,
+		session.StartedAt.UnixNano(),
+		session.EndedAt.UnixNano(),
+		session.Label,
+		session.Preset,
+		session.EndStatus,
+		int64(session.FocusDuration() / time.Second),
+		string(data),
+	}
+	if seq == 0 {
+		_, err = db.Exec(
+			`INSERT INTO sessions
+			 (id, started_at, ended_at, label, preset, end_status, focus_seconds, data)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			args...,
+		)
+		return err
+	}
+	_, err = db.Exec(
+		`UPDATE sessions SET id = ?, started_at = ?, ended_at = ?, label = ?, preset = ?,
+		 end_status = ?, focus_seconds = ?, data = ? WHERE seq = ?`,
+		append(args, seq)...,
+	)
+	return err
+}
+
+func (s *SQLiteStore) querySessions(query string, args ...any) ([]Session, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read history row: %w", err)
+		}
+		var session Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil {
+			return nil, fmt.Errorf("failed to parse stored session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func appendStrings(args []any, values []string) []any {
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return args
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// rangeBounds converts a half-open time range to started_at bounds, treating
+// zero times as unbounded
+func rangeBounds(from, to time.Time) (int64, int64) {
+	lo := int64(-1 << 63)
+	hi := int64(1<<63 - 1)
+	if !from.IsZero() {
+		lo = from.UnixNano()
+	}
+	if !to.IsZero() {
+		hi = to.UnixNano()
+	}
+	return lo, hi
+}
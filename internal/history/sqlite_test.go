@@ -0,0 +1,245 @@
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestSQLite(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// sessionAt returns a 25m session starting at the given hour on 2025-01-15 UTC
+func sessionAt(id string, hour int, label, preset, status string) Session {
+	start := time.Date(2025, 1, 15, hour, 0, 0, 0, time.UTC)
+	return Session{
+		ID:             id,
+		StartedAt:      start,
+		EndedAt:        start.Add(25 * time.Minute),
+		Duration:       "25m",
+		Preset:         preset,
+		Label:          label,
+		EndStatus:      status,
+		PausedDuration: "0s",
+	}
+}
+
+func TestSQLiteStore_AppendAndLoad(t *testing.T) {
+	store := openTestSQLite(t)
+
+	require.NoError(t, store.Append(sessionAt("b", 10, "Second", "work", "completed")))
+	require.NoError(t, store.Append(sessionAt("a", 9, "First", "work", "stopped")))
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+	// Ordered by start time, not insertion
+	assert.Equal(t, "a", h.Sessions[0].ID)
+	assert.Equal(t, "b", h.Sessions[1].ID)
+	assert.Equal(t, "stopped", h.Sessions[0].EndStatus)
+	assert.True(t, h.Sessions[0].StartedAt.Equal(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestSQLiteStore_AppendKeepsEveryRecord(t *testing.T) {
+	store := openTestSQLite(t)
+
+	// The records of a resumed session share an ID and start
+	require.NoError(t, store.Append(sessionAt("x", 9, "Work", "work", "interrupted")))
+	require.NoError(t, store.Append(sessionAt("x", 9, "Work", "work", "completed")))
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+	assert.Equal(t, "interrupted", h.Sessions[0].EndStatus)
+	assert.Equal(t, "completed", h.Sessions[1].EndStatus)
+
+	totals, err := store.Totals(time.Time{}, time.Time{}, GroupByLabel)
+	require.NoError(t, err)
+	assert.Equal(t, []Total{{Key: "Work", Sessions: 2, Focus: 50 * time.Minute}}, totals)
+}
+
+func TestOpenSQLiteStore_MigratesRowsKeyedByID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE sessions (
+		id TEXT PRIMARY KEY, started_at INTEGER NOT NULL, ended_at INTEGER NOT NULL,
+		label TEXT NOT NULL DEFAULT '', preset TEXT NOT NULL DEFAULT '', end_status TEXT NOT NULL DEFAULT '',
+		focus_seconds INTEGER NOT NULL DEFAULT 0, data TEXT NOT NULL
+	)`)
+	require.NoError(t, err)
+	old := sessionAt("x", 9, "Work", "work", "interrupted")
+	data, err := json.Marshal(old)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO sessions VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		old.ID, old.StartedAt.UnixNano(), old.EndedAt.UnixNano(), old.Label, old.Preset, old.EndStatus, 1500, string(data))
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := OpenSQLiteStore(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	require.NoError(t, store.Append(sessionAt("x", 9, "Work", "work", "completed")))
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 2)
+	assert.Equal(t, old, h.Sessions[0])
+}
+
+func TestSQLiteStore_Between(t *testing.T) {
+	store := openTestSQLite(t)
+	for hour := 8; hour < 12; hour++ {
+		require.NoError(t, store.Append(sessionAt(fmt.Sprint(hour), hour, "Work", "work", "completed")))
+	}
+
+	from := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)
+	sessions, err := store.Between(from, to)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "9", sessions[0].ID)
+	assert.Equal(t, "10", sessions[1].ID)
+
+	// Open-ended range
+	sessions, err = store.Between(from, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, sessions, 3)
+}
+
+func TestSQLiteStore_Totals(t *testing.T) {
+	store := openTestSQLite(t)
+	require.NoError(t, store.Append(sessionAt("1", 9, "Auth", "work", "completed")))
+	require.NoError(t, store.Append(sessionAt("2", 10, "Auth", "work", "completed")))
+	require.NoError(t, store.Append(sessionAt("3", 11, "Docs", "work", "stopped")))
+
+	totals, err := Totals(store, time.Time{}, time.Time{}, GroupByLabel)
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	assert.Equal(t, Total{Key: "Auth", Sessions: 2, Focus: 50 * time.Minute}, totals[0])
+	assert.Equal(t, Total{Key: "Docs", Sessions: 1, Focus: 25 * time.Minute}, totals[1])
+
+	totals, err = store.Totals(time.Time{}, time.Time{}, GroupByEndStatus)
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	assert.Equal(t, "completed", totals[0].Key)
+
+	_, err = store.Totals(time.Time{}, time.Time{}, "data")
+	require.Error(t, err)
+}
+
+func TestSQLiteStore_QueriesUseIndices(t *testing.T) {
+	store := openTestSQLite(t)
+
+	tests := []struct {
+		column string
+		index  string
+	}{
+		{"started_at", "idx_sessions_started_at"},
+		{"label", "idx_sessions_label"},
+		{"preset", "idx_sessions_preset"},
+		{"end_status", "idx_sessions_end_status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column, func(t *testing.T) {
+			rows, err := store.db.Query(`EXPLAIN QUERY PLAN SELECT data FROM sessions WHERE `+tt.column+` = ?`, "x")
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var plan []string
+			for rows.Next() {
+				var id, parent, notUsed int
+				var detail string
+				require.NoError(t, rows.Scan(&id, &parent, &notUsed, &detail))
+				plan = append(plan, detail)
+			}
+			assert.Contains(t, strings.Join(plan, "\n"), tt.index)
+		})
+	}
+}
+
+func TestSQLiteStore_ImportJSON(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "history.json")
+	require.NoError(t, Save(&History{Version: "1.0", Sessions: []Session{
+		sessionAt("1", 9, "A", "work", "completed"),
+		sessionAt("2", 10, "B", "break", "interrupted"),
+		sessionAt("2", 10, "B", "break", "completed"),
+	}}, jsonPath))
+
+	store := openTestSQLite(t)
+	n, err := store.ImportJSON(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n, "both records of the resumed session are imported")
+
+	// Re-import is idempotent
+	n, err = store.ImportJSON(jsonPath)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, h.Sessions, 3)
+}
+
+func TestOpen_SQLiteImportsLegacyHistory(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "history.json")
+	require.NoError(t, Save(&History{Version: "1.0", Sessions: []Session{sessionAt("legacy", 9, "A", "work", "completed")}}, jsonPath))
+
+	store, err := Open(BackendSQLite, dir)
+	require.NoError(t, err)
+	defer store.Close()
+
+	h, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, h.Sessions, 1)
+	assert.Equal(t, "legacy", h.Sessions[0].ID)
+
+	// history.json is left in place
+	_, err = Load(jsonPath)
+	assert.NoError(t, err)
+}
+
+func TestSQLiteStore_WalkAcrossPages(t *testing.T) {
+	store := openTestSQLite(t)
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	total := sqliteWalkPageSize*2 + 100
+	require.NoError(t, store.Update(func(h *History) error {
+		for i := 0; i < total; i++ {
+			s := sessionAt(fmt.Sprintf("%04d", i), 0, "Work", "work", "completed")
+			s.StartedAt = start.Add(time.Duration(i) * time.Minute)
+			h.Sessions = append(h.Sessions, s)
+		}
+		return nil
+	}))
+
+	var visited []string
+	require.NoError(t, Walk(store, Query{}, func(s Session) error {
+		visited = append(visited, s.ID)
+		return nil
+	}))
+	require.Len(t, visited, total)
+	assert.Equal(t, "0000", visited[0])
+	assert.Equal(t, fmt.Sprintf("%04d", total-1), visited[total-1])
+
+	// Limit caps the walk even when it spans pages
+	visited = nil
+	require.NoError(t, Walk(store, Query{Order: NewestFirst, Limit: sqliteWalkPageSize + 1}, func(s Session) error {
+		visited = append(visited, s.ID)
+		return nil
+	}))
+	require.Len(t, visited, sqliteWalkPageSize+1)
+	assert.Equal(t, fmt.Sprintf("%04d", total-1), visited[0])
+}
@@ -0,0 +1,286 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/encryption"
+	"github.com/pomodux/pomodux/internal/privacy"
+)
+
+// Supported history storage backends
+const (
+	BackendJSON   = "json"
+	BackendJSONL  = "jsonl"
+	BackendSQLite = "sqlite"
+)
+
+// Session fields that totals can be grouped by
+const (
+	GroupByLabel     = "label"
+	GroupByPreset    = "preset"
+	GroupByEndStatus = "end_status"
+	GroupByProject   = "project"
+	GroupByTag       = "tag"
+)
+
+// Store is a persistent collection of sessions
+type Store interface {
+	// Append adds a session to the store
+	Append(session Session) error
+	// Load returns every stored session, oldest first
+	Load() (*History, error)
+	// Between returns sessions started in [from, to), oldest first.
+	// A zero from or to leaves that side of the range open.
+	Between(from, to time.Time) ([]Session, error)
+	// Query returns one page of sessions selected by q
+	Query(q Query) (*Page, error)
+	// Update performs an exclusive read-modify-write of the whole history.
+	// If fn returns an error nothing is written.
+	Update(fn func(h *History) error) error
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// Aggregator is implemented by stores that can compute grouped totals
+// without loading every session
+type Aggregator interface {
+	Totals(from, to time.Time, field string) ([]Total, error)
+}
+
+// Walker is implemented by stores that can stream query results without
+// materialising every matching session at once
+type Walker interface {
+	Walk(q Query, fn func(Session) error) error
+}
+
+// Total is the aggregate of the sessions sharing one group key
+type Total struct {
+	Key      string
+	Sessions int
+	Focus    time.Duration
+}
+
+// Totals groups sessions started in [from, to) by field (label, preset,
+// end_status, project or tag), ordered by focus time descending. When grouping
+// by tag a session counts towards each of its tags; untagged sessions share
+// the empty key. Stores implementing
+// Aggregator compute this natively; others are summed in memory.
+func Totals(store Store, from, to time.Time, field string) ([]Total, error) {
+	if agg, ok := store.(Aggregator); ok {
+		return agg.Totals(from, to, field)
+	}
+
+	sessions, err := store.Between(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return SumBy(sessions, field)
+}
+
+// Walk calls fn for each session matching q in query order, stopping at the
+// first error. q.Limit caps the number of sessions visited (0 for all).
+// Stores implementing Walker stream the results; others run q in one go.
+func Walk(store Store, q Query, fn func(Session) error) error {
+	if w, ok := store.(Walker); ok {
+		return w.Walk(q, fn)
+	}
+
+	page, err := store.Query(q)
+	if err != nil {
+		return err
+	}
+	for _, session := range page.Sessions {
+		if err := fn(session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SumBy groups sessions by field in memory, ordered by focus time descending
+func SumBy(sessions []Session, field string) ([]Total, error) {
+	if _, ok := groupExprs[field]; !ok {
+		return nil, fmt.Errorf("cannot group sessions by %q", field)
+	}
+
+	index := map[string]int{}
+	var totals []Total
+	for _, s := range sessions {
+		for _, key := range s.GroupKeys(field) {
+			i, ok := index[key]
+			if !ok {
+				i = len(totals)
+				index[key] = i
+				totals = append(totals, Total{Key: key})
+			}
+			totals[i].Sessions++
+			totals[i].Focus += s.FocusDuration().Truncate(time.Second)
+		}
+	}
+
+	SortTotals(totals)
+	return totals, nil
+}
+
+// SortTotals orders totals by focus time descending, then by key
+func SortTotals(totals []Total) {
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].Focus != totals[j].Focus {
+			return totals[i].Focus > totals[j].Focus
+		}
+		return totals[i].Key < totals[j].Key
+	})
+}
+
+// GroupKeys returns the keys a session is counted under when grouping by
+// field, or nil if sessions cannot be grouped by field. Private sessions
+// share one label and have no project or tags.
+func (s Session) GroupKeys(field string) []string {
+	switch field {
+	case GroupByLabel:
+		if s.Private {
+			return []string{privacy.PrivateLabel}
+		}
+		return []string{s.Label}
+	case GroupByPreset:
+		return []string{s.Preset}
+	case GroupByEndStatus:
+		return []string{s.EndStatus}
+	case GroupByProject:
+		if s.Private {
+			return []string{""}
+		}
+		return []string{s.Project}
+	case GroupByTag:
+		if s.Private || len(s.Tags) == 0 {
+			return []string{""}
+		}
+		return s.Tags
+	}
+	return nil
+}
+
+// filterBetween returns the sessions of h started in [from, to)
+func filterBetween(h *History, from, to time.Time) []Session {
+	result := []Session{}
+	for _, s := range h.Sessions {
+		if !from.IsZero() && s.StartedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !s.StartedAt.Before(to) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// FileName returns the file name used by the given backend inside the state directory
+func FileName(backend string) string {
+	switch backend {
+	case BackendJSONL:
+		return "history.jsonl"
+	case BackendSQLite:
+		return "history.db"
+	default:
+		return "history.json"
+	}
+}
+
+// Open opens the history store for backend in dir. Encrypted histories
+// (see package encryption) must use the JSON backend.
+// When opening the JSONL backend for the first time, sessions from an existing
+// history.json are migrated once; a new SQLite database imports them instead,
+// leaving history.json in place.
+func Open(backend string, dir string) (Store, error) {
+	if encryption.Active() != nil && backend != BackendJSON && backend != "" {
+		return nil, fmt.Errorf("history encryption requires the json backend, not %s", backend)
+	}
+	switch backend {
+	case BackendJSON, "":
+		return NewJSONStore(filepath.Join(dir, FileName(BackendJSON))), nil
+	case BackendJSONL:
+		path := filepath.Join(dir, FileName(BackendJSONL))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			legacyPath := filepath.Join(dir, FileName(BackendJSON))
+			if _, err := MigrateToJSONL(legacyPath, path); err != nil {
+				return nil, err
+			}
+		}
+		return NewJSONLStore(path), nil
+	case BackendSQLite:
+		path := filepath.Join(dir, FileName(BackendSQLite))
+		_, statErr := os.Stat(path)
+		store, err := OpenSQLiteStore(path)
+		if err != nil {
+			return nil, err
+		}
+		legacyPath := filepath.Join(dir, FileName(BackendJSON))
+		if _, err := os.Stat(legacyPath); os.IsNotExist(statErr) && err == nil {
+			if _, err := store.ImportJSON(legacyPath); err != nil {
+				store.Close()
+				return nil, fmt.Errorf("failed to import %s: %w", legacyPath, err)
+			}
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown history backend %q", backend)
+	}
+}
+
+// JSONStore keeps history in a single JSON document (history.json)
+type JSONStore struct {
+	path string
+}
+
+// NewJSONStore creates a store backed by the JSON document at path
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{path: path}
+}
+
+// Path returns the file backing the store
+func (s *JSONStore) Path() string {
+	return s.path
+}
+
+// Append adds a session under an exclusive lock
+func (s *JSONStore) Append(session Session) error {
+	return Append(s.path, session)
+}
+
+// Load reads the whole history document
+func (s *JSONStore) Load() (*History, error) {
+	return Load(s.path)
+}
+
+// Update rewrites the document under an exclusive lock
+func (s *JSONStore) Update(fn func(h *History) error) error {
+	return Update(s.path, fn)
+}
+
+// Between loads the document and filters it by start time
+func (s *JSONStore) Between(from, to time.Time) ([]Session, error) {
+	h, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return filterBetween(h, from, to), nil
+}
+
+// Query loads the document and runs q in memory
+func (s *JSONStore) Query(q Query) (*Page, error) {
+	h, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return h.Query(q)
+}
+
+// Close is a no-op for the JSON store
+func (s *JSONStore) Close() error {
+	return nil
+}
@@ -0,0 +1,144 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_FocusDuration(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		session  Session
+		expected time.Duration
+	}{
+		{"completed", Session{StartedAt: start, EndedAt: start.Add(25 * time.Minute), Duration: "25m", PausedDuration: "0s"}, 25 * time.Minute},
+		{"pauses subtracted", Session{StartedAt: start, EndedAt: start.Add(30 * time.Minute), Duration: "25m", PausedDuration: "5m"}, 25 * time.Minute},
+		{"stopped early", Session{StartedAt: start, EndedAt: start.Add(10 * time.Minute), Duration: "25m", PausedDuration: "2m"}, 8 * time.Minute},
+		{"capped at planned", Session{StartedAt: start, EndedAt: start.Add(2 * time.Hour), Duration: "25m", PausedDuration: "0s"}, 25 * time.Minute},
+		{"missing end", Session{StartedAt: start, Duration: "25m"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.session.FocusDuration())
+		})
+	}
+}
+
+func TestStores_BetweenAndTotals(t *testing.T) {
+	backends := []string{BackendJSON, BackendJSONL, BackendSQLite}
+
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			require.NoError(t, err)
+			defer store.Close()
+
+			require.NoError(t, store.Append(sessionAt("1", 9, "Auth", "work", "completed")))
+			require.NoError(t, store.Append(sessionAt("2", 10, "Docs", "work", "stopped")))
+			require.NoError(t, store.Append(sessionAt("3", 11, "Auth", "break", "completed")))
+
+			sessions, err := store.Between(
+				time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC),
+			)
+			require.NoError(t, err)
+			require.Len(t, sessions, 2)
+			assert.Equal(t, "2", sessions[0].ID)
+
+			totals, err := Totals(store, time.Time{}, time.Time{}, GroupByPreset)
+			require.NoError(t, err)
+			assert.Equal(t, []Total{
+				{Key: "work", Sessions: 2, Focus: 50 * time.Minute},
+				{Key: "break", Sessions: 1, Focus: 25 * time.Minute},
+			}, totals)
+		})
+	}
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "history.json", FileName(BackendJSON))
+	assert.Equal(t, "history.jsonl", FileName(BackendJSONL))
+	assert.Equal(t, "history.db", FileName(BackendSQLite))
+	assert.Equal(t, "history.json", filepath.Base(FileName("")))
+}
+
+func TestStores_TotalsByProjectAndTag(t *testing.T) {
+	for _, backend := range []string{BackendJSON, BackendJSONL, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			require.NoError(t, err)
+			defer store.Close()
+
+			login := sessionAt("1", 9, "Fix login", "work", "completed")
+			login.Project = "auth"
+			login.Tags = []string{"bugfix", "backend"}
+			signup := sessionAt("2", 10, "Signup", "work", "completed")
+			signup.Project = "auth"
+			signup.Tags = []string{"backend"}
+			untagged := sessionAt("3", 11, "Email", "work", "stopped")
+			untagged.EndedAt = untagged.StartedAt.Add(10 * time.Minute)
+			for _, s := range []Session{login, signup, untagged} {
+				require.NoError(t, store.Append(s))
+			}
+
+			totals, err := Totals(store, time.Time{}, time.Time{}, GroupByProject)
+			require.NoError(t, err)
+			assert.Equal(t, []Total{
+				{Key: "auth", Sessions: 2, Focus: 50 * time.Minute},
+				{Key: "", Sessions: 1, Focus: 10 * time.Minute},
+			}, totals)
+
+			totals, err = Totals(store, time.Time{}, time.Time{}, GroupByTag)
+			require.NoError(t, err)
+			assert.Equal(t, []Total{
+				{Key: "backend", Sessions: 2, Focus: 50 * time.Minute},
+				{Key: "bugfix", Sessions: 1, Focus: 25 * time.Minute},
+				{Key: "", Sessions: 1, Focus: 10 * time.Minute},
+			}, totals)
+
+			page, err := store.Query(Query{Projects: []string{"auth"}})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1", "2"}, ids(page.Sessions))
+		})
+	}
+}
+
+func TestStores_Walk(t *testing.T) {
+	backends := []string{BackendJSON, BackendJSONL, BackendSQLite}
+
+	for _, backend := range backends {
+		t.Run(backend, func(t *testing.T) {
+			store, err := Open(backend, t.TempDir())
+			require.NoError(t, err)
+			defer store.Close()
+
+			require.NoError(t, store.Append(sessionAt("1", 9, "Auth", "work", "completed")))
+			require.NoError(t, store.Append(sessionAt("2", 10, "Docs", "work", "stopped")))
+			require.NoError(t, store.Append(sessionAt("3", 11, "Auth", "break", "completed")))
+
+			var visited []string
+			err = Walk(store, Query{Label: "auth", Order: NewestFirst}, func(s Session) error {
+				visited = append(visited, s.ID)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"3", "1"}, visited)
+
+			stop := errors.New("stop")
+			visited = nil
+			err = Walk(store, Query{}, func(s Session) error {
+				visited = append(visited, s.ID)
+				return stop
+			})
+			assert.ErrorIs(t, err, stop)
+			assert.Equal(t, []string{"1"}, visited)
+		})
+	}
+}
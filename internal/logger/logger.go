@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/pomodux/pomodux/internal/privacy"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,6 +18,61 @@ var (
 type Config struct {
 	Level string // debug, info, warn, error
 	File  string // Empty = stderr only, or path to log file
+	// Labels is the privacy mode for label fields: plain, hash or redact
+	Labels string
+}
+
+// labelFields are the fields that may carry session labels
+var labelFields = []string{"label", "notes"}
+
+// labelPartFields are the fields that may carry the project and tags parsed
+// from a label
+var labelPartFields = []string{"project", "tags"}
+
+// privacyFormatter masks label fields before formatting. Entries with a true
+// "private" field belong to private sessions and never show them: labels
+// become PrivateLabel and projects and tags are left out.
+type privacyFormatter struct {
+	logrus.Formatter
+	mode string
+}
+
+// Format masks the entry's label fields and formats it
+func (f *privacyFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	private, _ := entry.Data["private"].(bool)
+	if f.mode == privacy.ModePlain && !private {
+		return f.Formatter.Format(entry)
+	}
+
+	masked := *entry
+	masked.Data = make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		masked.Data[key] = value
+	}
+	for _, key := range labelFields {
+		value, ok := masked.Data[key].(string)
+		if !ok || value == "" {
+			continue
+		}
+		if private {
+			masked.Data[key] = privacy.PrivateLabel
+		} else {
+			masked.Data[key] = privacy.Mask(value, f.mode)
+		}
+	}
+	for _, key := range labelPartFields {
+		if private {
+			delete(masked.Data, key)
+			continue
+		}
+		switch value := masked.Data[key].(type) {
+		case string:
+			masked.Data[key] = privacy.Mask(value, f.mode)
+		case []string:
+			masked.Data[key] = privacy.MaskAll(value, f.mode)
+		}
+	}
+	return f.Formatter.Format(&masked)
 }
 
 // Init initializes the global logger with the given configuration
@@ -45,8 +101,16 @@ func Init(config Config) error {
 	Logger.SetOutput(output)
 
 	// Set formatter
-	Logger.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
+	mode, err := privacy.ParseMode(config.Labels)
+	if err != nil {
+		mode = privacy.ModeRedact
+		Logger.WithError(err).Warn("Invalid label privacy mode, redacting labels")
+	}
+	Logger.SetFormatter(&privacyFormatter{
+		Formatter: &logrus.TextFormatter{
+			FullTimestamp: true,
+		},
+		mode: mode,
 	})
 
 	return nil
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLine initializes the logger with labels mode and returns what one entry writes
+func logLine(t *testing.T, labels string, fields map[string]interface{}) string {
+	path := filepath.Join(t.TempDir(), "pomodux.log")
+	require.NoError(t, Init(Config{Level: "info", File: path, Labels: labels}))
+	t.Cleanup(func() { Logger = nil })
+
+	WithFields(fields).Info("Timer started")
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestInit_LabelPrivacy(t *testing.T) {
+	fields := map[string]interface{}{"label": "Write report", "preset": "work"}
+
+	assert.Contains(t, logLine(t, "plain", fields), `label="Write report"`)
+
+	line := logLine(t, "hash", fields)
+	assert.NotContains(t, line, "Write report")
+	assert.Regexp(t, `label="h:[0-9a-f]{8}"`, line)
+	assert.Contains(t, line, "preset=work")
+
+	assert.Contains(t, logLine(t, "redact", fields), `label="[redacted]"`)
+
+	parts := map[string]interface{}{"label": "Call +acme @billable", "project": "acme", "tags": []string{"billable"}}
+	line = logLine(t, "redact", parts)
+	assert.NotContains(t, line, "acme")
+	assert.NotContains(t, line, "billable")
+	assert.Contains(t, line, `project="[redacted]"`)
+
+	parts["private"] = true
+	private := logLine(t, "plain", parts)
+	assert.NotContains(t, private, "acme")
+	assert.NotContains(t, private, "billable")
+	assert.Contains(t, private, `label="[private]"`)
+}
@@ -0,0 +1,75 @@
+// Package privacy masks session labels before they leave the history, in
+// log fields and exports.
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Ways of showing a label outside the history
+const (
+	// ModePlain shows labels unchanged
+	ModePlain = "plain"
+	// ModeHash replaces labels with a short hash, so equal labels still match
+	ModeHash = "hash"
+	// ModeRedact replaces labels with Redacted
+	ModeRedact = "redact"
+)
+
+// Modes lists the valid modes
+var Modes = []string{ModePlain, ModeHash, ModeRedact}
+
+// Redacted replaces labels in redact mode
+const Redacted = "[redacted]"
+
+// PrivateLabel replaces the label of private sessions outside the history
+const PrivateLabel = "[private]"
+
+// ParseMode validates a mode; empty means plain
+func ParseMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return ModePlain, nil
+	case ModePlain, ModeHash, ModeRedact:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid privacy mode %q (expected plain, hash or redact)", mode)
+	}
+}
+
+// Mask returns value as shown in mode. Empty values stay empty. Hashes are
+// unsalted, so short or guessable labels can be recovered from them; use
+// redact to hide labels completely.
+func Mask(value, mode string) string {
+	if value == "" {
+		return value
+	}
+	switch mode {
+	case ModeHash:
+		sum := sha256.Sum256([]byte(value))
+		return "h:" + hex.EncodeToString(sum[:4])
+	case ModeRedact:
+		return Redacted
+	default:
+		return value
+	}
+}
+
+// MaskAll masks each value, dropping the duplicates masking creates
+func MaskAll(values []string, mode string) []string {
+	if mode == ModePlain || len(values) == 0 {
+		return values
+	}
+	seen := map[string]bool{}
+	masked := make([]string, 0, len(values))
+	for _, value := range values {
+		value = Mask(value, mode)
+		if !seen[value] {
+			seen[value] = true
+			masked = append(masked, value)
+		}
+	}
+	return masked
+}
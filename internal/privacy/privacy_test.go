@@ -0,0 +1,40 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "Write report", Mask("Write report", ModePlain))
+	assert.Equal(t, Redacted, Mask("Write report", ModeRedact))
+	assert.Equal(t, "", Mask("", ModeRedact), "empty values stay empty")
+
+	hashed := Mask("Write report", ModeHash)
+	assert.Regexp(t, `^h:[0-9a-f]{8}$`, hashed)
+	assert.Equal(t, hashed, Mask("Write report", ModeHash), "hashes are stable")
+	assert.NotEqual(t, hashed, Mask("Write reports", ModeHash))
+}
+
+func TestMaskAll(t *testing.T) {
+	tags := []string{"billable", "call"}
+	assert.Equal(t, tags, MaskAll(tags, ModePlain))
+	assert.Equal(t, []string{Redacted}, MaskAll(tags, ModeRedact), "masking duplicates are dropped")
+	assert.Len(t, MaskAll(tags, ModeHash), 2)
+	assert.Empty(t, MaskAll(nil, ModeRedact))
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModePlain, mode)
+
+	mode, err = ParseMode(ModeHash)
+	require.NoError(t, err)
+	assert.Equal(t, ModeHash, mode)
+
+	_, err = ParseMode("scramble")
+	assert.Error(t, err)
+}
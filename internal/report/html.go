@@ -0,0 +1,126 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+// htmlTemplate is a standalone page with inline styles and chart
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Focus report {{.Week}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #2e3440; max-width: 720px; margin: 2rem auto; padding: 0 1rem; }
+h1 { margin-bottom: 0.25rem; }
+.range { color: #4c566a; margin-top: 0; }
+table { border-collapse: collapse; margin: 1rem 0; }
+th, td { padding: 0.3rem 0.8rem; border-bottom: 1px solid #d8dee9; text-align: left; }
+td.num, th.num { text-align: right; }
+ul.notes li { margin-bottom: 0.4rem; white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>Focus report {{.Week}}</h1>
+<p class="range">{{.Range}}</p>
+
+<h2>Summary</h2>
+<table>
+{{- range .Summary}}
+<tr><td>{{index . 0}}</td><td class="num">{{index . 1}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Focus per day</h2>
+{{.Chart}}
+<table>
+<tr><th>Day</th><th class="num">Sessions</th><th class="num">Completed</th><th class="num">Focus</th><th class="num">Completion rate</th></tr>
+{{- range .Days}}
+<tr><td>{{.Date}}</td><td class="num">{{.Sessions}}</td><td class="num">{{.Completed}}</td><td class="num">{{.Focus}}</td><td class="num">{{.Rate}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Focus per label</h2>
+{{- if .Labels}}
+<table>
+<tr><th>Label</th><th class="num">Sessions</th><th class="num">Focus</th><th class="num">Share</th></tr>
+{{- range .Labels}}
+<tr><td>{{.Label}}</td><td class="num">{{.Sessions}}</td><td class="num">{{.Focus}}</td><td class="num">{{.Share}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No sessions recorded.</p>
+{{- end}}
+
+<h2>Notes</h2>
+{{- if .Notes}}
+<ul class="notes">
+{{- range .Notes}}
+<li><strong>{{.When}} · {{.Label}}</strong>: {{.Text}}</li>
+{{- end}}
+</ul>
+{{- else}}
+<p>No notes captured.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// writeHTML renders r as a standalone HTML page with an inline SVG chart
+func writeHTML(out io.Writer, r *Weekly) error {
+	type dayRow struct {
+		Date, Focus, Rate   string
+		Sessions, Completed int
+	}
+	type labelRow struct {
+		Label, Focus, Share string
+		Sessions            int
+	}
+	type noteRow struct{ When, Label, Text string }
+
+	data := struct {
+		Week, Range string
+		Summary     [][2]string
+		// The chart is generated by focusChart, which escapes its text
+		Chart  template.HTML
+		Days   []dayRow
+		Labels []labelRow
+		Notes  []noteRow
+	}{
+		Week:    r.Week,
+		Range:   dateRange(r),
+		Summary: summaryRows(r),
+		Chart:   template.HTML(focusChart(r)),
+	}
+	for _, day := range r.Days {
+		data.Days = append(data.Days, dayRow{
+			Date:      day.Date.Format("Mon Jan 2"),
+			Sessions:  day.Summary.Sessions,
+			Completed: day.Summary.Completed,
+			Focus:     focus(day.Summary.TotalFocus),
+			Rate:      rate(day.Summary),
+		})
+	}
+	for _, total := range r.Labels {
+		data.Labels = append(data.Labels, labelRow{
+			Label:    labelName(total.Key),
+			Sessions: total.Sessions,
+			Focus:    focus(total.Focus),
+			Share:    share(total.Focus, r.Summary.TotalFocus),
+		})
+	}
+	for _, note := range r.Notes {
+		data.Notes = append(data.Notes, noteRow{
+			When:  note.StartedAt.Format("Mon 15:04"),
+			Label: labelName(note.Label),
+			Text:  note.Text,
+		})
+	}
+
+	if err := htmlTemplate.Execute(out, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
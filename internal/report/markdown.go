@@ -0,0 +1,108 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// textChartWidth is the length of the longest bar of the Markdown chart
+const textChartWidth = 30
+
+// barEighths draws the last, partial cell of a bar in eighths
+var barEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// writeMarkdown renders r as Markdown. The chart is drawn as text in a code
+// block, since Markdown renderers strip images embedded as data URIs.
+func writeMarkdown(out io.Writer, r *Weekly) error {
+	w := bufio.NewWriter(out)
+
+	fmt.Fprintf(w, "# Focus report %s\n\n", r.Week)
+	fmt.Fprintf(w, "%s\n\n", dateRange(r))
+
+	w.WriteString("## Summary\n\n")
+	w.WriteString("| Metric | Value |\n|---|---:|\n")
+	for _, row := range summaryRows(r) {
+		fmt.Fprintf(w, "| %s | %s |\n", row[0], row[1])
+	}
+
+	w.WriteString("\n## Focus per day\n\n")
+	fmt.Fprintf(w, "```text\n%s```\n\n", textChart(r))
+	w.WriteString("| Day | Sessions | Completed | Focus | Completion rate |\n|---|---:|---:|---:|---:|\n")
+	for _, day := range r.Days {
+		fmt.Fprintf(w, "| %s | %d | %d | %s | %s |\n", day.Date.Format("Mon Jan 2"),
+			day.Summary.Sessions, day.Summary.Completed, focus(day.Summary.TotalFocus), rate(day.Summary))
+	}
+
+	w.WriteString("\n## Focus per label\n\n")
+	if len(r.Labels) == 0 {
+		w.WriteString("No sessions recorded.\n")
+	} else {
+		w.WriteString("| Label | Sessions | Focus | Share |\n|---|---:|---:|---:|\n")
+		for _, total := range r.Labels {
+			fmt.Fprintf(w, "| %s | %d | %s | %s |\n", markdownCell(labelName(total.Key)),
+				total.Sessions, focus(total.Focus), share(total.Focus, r.Summary.TotalFocus))
+		}
+	}
+
+	w.WriteString("\n## Notes\n\n")
+	if len(r.Notes) == 0 {
+		w.WriteString("No notes captured.\n")
+	}
+	for _, note := range r.Notes {
+		fmt.Fprintf(w, "- **%s · %s**: %s\n", note.StartedAt.Format("Mon 15:04"),
+			markdownText(labelName(note.Label)), markdownText(note.Text))
+	}
+
+	return w.Flush()
+}
+
+// textChart renders the focus time of each day of r as a bar per line
+func textChart(r *Weekly) string {
+	var maxFocus time.Duration
+	for _, day := range r.Days {
+		maxFocus = max(maxFocus, day.Summary.TotalFocus)
+	}
+
+	var b strings.Builder
+	for _, day := range r.Days {
+		bar := ""
+		if maxFocus > 0 {
+			eighths := int(float64(textChartWidth*8) * float64(day.Summary.TotalFocus) / float64(maxFocus))
+			bar = strings.Repeat("█", eighths/8) + barEighths[eighths%8]
+		}
+		cells := len([]rune(bar))
+		fmt.Fprintf(&b, "%s  %s%s  %s\n", day.Date.Format("Mon"), bar,
+			strings.Repeat(" ", textChartWidth-cells), focus(day.Summary.TotalFocus))
+	}
+	return b.String()
+}
+
+// summaryRows returns the name and value of each summary metric
+func summaryRows(r *Weekly) [][2]string {
+	s := r.Summary
+	return [][2]string{
+		{"Total focus", focus(s.TotalFocus)},
+		{"Sessions", fmt.Sprintf("%d", s.Sessions)},
+		{"Completed", fmt.Sprintf("%d", s.Completed)},
+		{"Stopped", fmt.Sprintf("%d", s.Stopped)},
+		{"Cancelled", fmt.Sprintf("%d", s.Cancelled)},
+		{"Interrupted", fmt.Sprintf("%d", s.Interrupted)},
+		{"Manual", fmt.Sprintf("%d", s.Manual)},
+		{"Completion rate", rate(s)},
+		{"Average pause", fmt.Sprintf("%s (%d pauses)", focus(s.AveragePause), s.Pauses)},
+	}
+}
+
+// markdownText keeps free text on one line and stops it from being read
+// as emphasis or HTML
+var markdownText = strings.NewReplacer(
+	"\r\n", " ", "\n", " ", "\\", "\\\\", "*", "\\*", "_", "\\_", "`", "\\`", "<", "&lt;", ">", "&gt;",
+).Replace
+
+// markdownCell escapes free text for a table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownText(s), "|", "\\|")
+}
@@ -0,0 +1,151 @@
+// Package report generates self-contained weekly focus reports
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+)
+
+// Supported report formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Weekly is the report of one ISO week (Monday to Sunday)
+type Weekly struct {
+	Week    string // ISO week, e.g. 2026-W41
+	From    time.Time
+	To      time.Time
+	Summary stats.Summary
+	Days    [7]Day          // Monday first
+	Labels  []history.Total // Ordered by focus time descending
+	Notes   []Note          // Oldest first
+}
+
+// Day summarizes the sessions started on one day of the week
+type Day struct {
+	Date    time.Time
+	Summary stats.Summary
+}
+
+// Note is the note captured during a session
+type Note struct {
+	StartedAt time.Time
+	Label     string
+	Text      string
+}
+
+// ParseWeek parses an ISO week such as 2026-W41 and returns its Monday at
+// midnight in loc
+func ParseWeek(value string, loc *time.Location) (time.Time, error) {
+	var year, week int
+	if n, err := fmt.Sscanf(value, "%4d-W%2d", &year, &week); err != nil || n != 2 || len(value) != 8 {
+		return time.Time{}, fmt.Errorf("invalid week %q (expected YYYY-Www, e.g. 2026-W41)", value)
+	}
+
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday())+6)%7)+7*(week-1))
+	if y, w := monday.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, fmt.Errorf("invalid week %q: %d has no week %d", value, year, week)
+	}
+	return monday, nil
+}
+
+// WeekOf returns the ISO week containing t, e.g. 2026-W41
+func WeekOf(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// Build streams the sessions started in the week beginning on monday into
+// a report
+func Build(store history.Store, monday time.Time) (*Weekly, error) {
+	r := &Weekly{
+		Week: WeekOf(monday),
+		From: monday,
+		To:   monday.AddDate(0, 0, 7),
+	}
+	for i := range r.Days {
+		r.Days[i].Date = monday.AddDate(0, 0, i)
+	}
+
+	labels := map[string]*history.Total{}
+	err := history.Walk(store, history.Query{From: r.From, To: r.To}, func(s history.Session) error {
+		r.Summary.Add(s)
+
+		start := s.StartedAt.In(monday.Location())
+		day := (int(start.Weekday()) + 6) % 7
+		r.Days[day].Summary.Add(s)
+
+		total, ok := labels[s.Label]
+		if !ok {
+			total = &history.Total{Key: s.Label}
+			labels[s.Label] = total
+		}
+		total.Sessions++
+		total.Focus += s.FocusDuration()
+
+		if s.Notes != "" {
+			r.Notes = append(r.Notes, Note{StartedAt: start, Label: s.Label, Text: s.Notes})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build report: %w", err)
+	}
+
+	r.Labels = make([]history.Total, 0, len(labels))
+	for _, total := range labels {
+		r.Labels = append(r.Labels, *total)
+	}
+	history.SortTotals(r.Labels)
+	return r, nil
+}
+
+// Write renders r in format (markdown or html) to out
+func Write(out io.Writer, format string, r *Weekly) error {
+	switch format {
+	case FormatMarkdown:
+		return writeMarkdown(out, r)
+	case FormatHTML:
+		return writeHTML(out, r)
+	default:
+		return fmt.Errorf("invalid report format %q (expected markdown or html)", format)
+	}
+}
+
+// dateRange formats the days covered by r
+func dateRange(r *Weekly) string {
+	last := r.To.AddDate(0, 0, -1)
+	return r.From.Format("Mon Jan 2") + " – " + last.Format("Mon Jan 2, 2006")
+}
+
+// labelName names the sessions without a label
+func labelName(label string) string {
+	if label == "" {
+		return "(none)"
+	}
+	return label
+}
+
+// rate formats the completion rate of s, "n/a" without timed sessions
+func rate(s stats.Summary) string {
+	if s.Timed() == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", s.CompletionRate*100)
+}
+
+// share formats part as a percentage of whole
+func share(part, whole time.Duration) string {
+	if whole <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", 100*float64(part)/float64(whole))
+}
@@ -0,0 +1,132 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Run `go test ./internal/report -update` after an intentional layout change
+var update = flag.Bool("update", false, "update golden files")
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func testReport(t *testing.T) *Weekly {
+	t.Helper()
+	store := history.NewJSONStore(filepath.Join(t.TempDir(), "history.json"))
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+	add := func(day, hour int, focus time.Duration, label, status, notes string) {
+		start := monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+		require.NoError(t, store.Append(history.Session{
+			ID:             start.Format(time.RFC3339),
+			StartedAt:      start,
+			EndedAt:        start.Add(focus),
+			Duration:       "25m",
+			Preset:         "work",
+			Label:          label,
+			EndStatus:      status,
+			PausedDuration: "0s",
+			Notes:          notes,
+		}))
+	}
+	add(0, 9, 25*time.Minute, "Fix login", history.StatusCompleted, "Cookie path <was> wrong")
+	add(0, 10, 25*time.Minute, "Fix login", history.StatusCompleted, "")
+	add(2, 14, 10*time.Minute, "Review | triage", history.StatusStopped, "Ran out of *time*")
+	add(4, 9, 25*time.Minute, "", history.StatusCompleted, "")
+	// Outside the week
+	add(7, 9, 25*time.Minute, "Next week", history.StatusCompleted, "")
+
+	r, err := Build(store, monday)
+	require.NoError(t, err)
+	return r
+}
+
+func TestBuild(t *testing.T) {
+	r := testReport(t)
+	assert.Equal(t, "2026-W41", r.Week)
+	assert.Equal(t, 4, r.Summary.Sessions)
+	assert.Equal(t, 85*time.Minute, r.Summary.TotalFocus)
+	assert.Equal(t, 2, r.Days[0].Summary.Sessions)
+	assert.Equal(t, 0, r.Days[1].Summary.Sessions)
+	assert.Equal(t, 1, r.Days[2].Summary.Stopped)
+	assert.Equal(t, []history.Total{
+		{Key: "Fix login", Sessions: 2, Focus: 50 * time.Minute},
+		{Key: "", Sessions: 1, Focus: 25 * time.Minute},
+		{Key: "Review | triage", Sessions: 1, Focus: 10 * time.Minute},
+	}, r.Labels)
+	require.Len(t, r.Notes, 2)
+	assert.Equal(t, "Cookie path <was> wrong", r.Notes[0].Text)
+}
+
+func TestWrite_Golden(t *testing.T) {
+	r := testReport(t)
+	for _, format := range []string{FormatMarkdown, FormatHTML} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, format, r))
+			ext := map[string]string{FormatMarkdown: "md", FormatHTML: "html"}[format]
+			assertGolden(t, "weekly."+ext, buf.Bytes())
+		})
+	}
+
+	assert.Error(t, Write(&bytes.Buffer{}, "pdf", r))
+}
+
+func TestParseWeek(t *testing.T) {
+	monday, err := ParseWeek("2026-W41", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC), monday)
+
+	// Week 1 of 2026 starts in 2025
+	monday, err = ParseWeek("2026-W01", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC), monday)
+
+	_, err = ParseWeek("2025-W53", time.UTC)
+	assert.Error(t, err)
+	_, err = ParseWeek("2020-W53", time.UTC)
+	assert.NoError(t, err)
+	for _, invalid := range []string{"2026-41", "2026-W00", "2026-W4", "W41"} {
+		_, err := ParseWeek(invalid, time.UTC)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestWeekOf(t *testing.T) {
+	assert.Equal(t, "2026-W41", WeekOf(time.Date(2026, 10, 11, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2026-W01", WeekOf(time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestTextChart(t *testing.T) {
+	r := &Weekly{}
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	for i := range r.Days {
+		r.Days[i].Date = monday.AddDate(0, 0, i)
+	}
+	r.Days[0].Summary.TotalFocus = 80 * time.Minute
+	r.Days[1].Summary.TotalFocus = 25 * time.Minute
+
+	lines := strings.Split(textChart(r), "\n")
+	assert.Equal(t, "Mon  "+strings.Repeat("█", 30)+"  1h20m", lines[0])
+	// 25/80 of 30 cells is 9 cells and 3 eighths
+	assert.Equal(t, "Tue  "+strings.Repeat("█", 9)+"▍"+strings.Repeat(" ", 20)+"  25m", lines[1])
+	assert.Equal(t, "Wed  "+strings.Repeat(" ", 30)+"  0s", lines[2])
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/timer"
+)
+
+// Dimensions of the focus chart in pixels
+const (
+	chartWidth  = 560
+	chartHeight = 220
+	chartTop    = 24 // Room for the value above the tallest bar
+	chartBottom = 24 // Room for the day names
+	barGap      = 16
+)
+
+// focusChart renders the focus time of each day of r as an SVG bar chart
+func focusChart(r *Weekly) string {
+	var maxFocus time.Duration
+	for _, day := range r.Days {
+		maxFocus = max(maxFocus, day.Summary.TotalFocus)
+	}
+
+	plotHeight := float64(chartHeight - chartTop - chartBottom)
+	slot := float64(chartWidth) / float64(len(r.Days))
+	barWidth := slot - barGap
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="Focus per day">`,
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	b.WriteString("\n")
+	fmt.Fprintf(&b, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="#d0d0d0"/>`, chartHeight-chartBottom, chartWidth, chartHeight-chartBottom)
+	b.WriteString("\n")
+
+	for i, day := range r.Days {
+		x := float64(i)*slot + barGap/2
+		center := x + barWidth/2
+		height := 0.0
+		if maxFocus > 0 {
+			height = plotHeight * float64(day.Summary.TotalFocus) / float64(maxFocus)
+		}
+		y := float64(chartHeight-chartBottom) - height
+
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" rx="3" fill="#5e81ac"/>`, x, y, barWidth, height)
+		b.WriteString("\n")
+		if day.Summary.TotalFocus > 0 {
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle" font-family="sans-serif" font-size="12" fill="#4c566a">%s</text>`,
+				center, y-6, html.EscapeString(focus(day.Summary.TotalFocus)))
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle" font-family="sans-serif" font-size="12" fill="#4c566a">%s</text>`,
+			center, chartHeight-6, day.Date.Format("Mon"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// focus formats a focus duration rounded down to whole seconds
+func focus(d time.Duration) string {
+	return timer.FormatDuration(d.Truncate(time.Second))
+}
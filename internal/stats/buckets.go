@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+)
+
+// Calendar periods sessions can be bucketed by
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// Bucket holds the grouped totals of one calendar period
+type Bucket struct {
+	Start  time.Time
+	End    time.Time
+	Totals []history.Total // Ordered by focus time descending
+}
+
+// Bucketer accumulates sessions into calendar periods. A session counts
+// towards the period it started in; its focus time is split across the
+// periods it spans in proportion to the wall-clock time spent in each.
+type Bucketer struct {
+	period    string
+	weekStart time.Weekday
+	field     string
+	loc       *time.Location
+	starts    map[int64]time.Time
+	totals    map[int64]map[string]*history.Total
+}
+
+// NewBucketer creates a Bucketer for period (day, week or month) in loc.
+// Weeks begin on weekStart. field groups each period's totals like
+// history.Totals; an empty field yields one total per period.
+func NewBucketer(period string, weekStart time.Weekday, field string, loc *time.Location) (*Bucketer, error) {
+	switch period {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+	default:
+		return nil, fmt.Errorf("invalid period %q (expected day, week or month)", period)
+	}
+	if field != "" && (history.Session{}).GroupKeys(field) == nil {
+		return nil, fmt.Errorf("cannot group sessions by %q", field)
+	}
+
+	return &Bucketer{
+		period:    period,
+		weekStart: weekStart,
+		field:     field,
+		loc:       loc,
+		starts:    map[int64]time.Time{},
+		totals:    map[int64]map[string]*history.Total{},
+	}, nil
+}
+
+// Add folds one session into its periods
+func (b *Bucketer) Add(session history.Session) {
+	keys := []string{""}
+	if b.field != "" {
+		keys = session.GroupKeys(b.field)
+	}
+
+	focus := session.FocusDuration()
+	start := session.StartedAt.In(b.loc)
+	end := session.EndedAt.In(b.loc)
+	first := PeriodStart(start, b.period, b.weekStart)
+	if !end.After(start) {
+		b.add(first, keys, 1, focus)
+		return
+	}
+
+	span := end.Sub(start)
+	remaining := focus
+	sessions := 1
+	for periodStart := first; periodStart.Before(end); periodStart = NextPeriod(periodStart, b.period) {
+		next := NextPeriod(periodStart, b.period)
+		share := remaining
+		if next.Before(end) {
+			from := periodStart
+			if from.Before(start) {
+				from = start
+			}
+			share = time.Duration(float64(focus) * float64(next.Sub(from)) / float64(span))
+			remaining -= share
+		}
+		b.add(periodStart, keys, sessions, share)
+		sessions = 0
+	}
+}
+
+func (b *Bucketer) add(periodStart time.Time, keys []string, sessions int, focus time.Duration) {
+	if sessions == 0 && focus <= 0 {
+		return
+	}
+
+	id := periodStart.UnixNano()
+	if _, ok := b.starts[id]; !ok {
+		b.starts[id] = periodStart
+		b.totals[id] = map[string]*history.Total{}
+	}
+	for _, key := range keys {
+		total, ok := b.totals[id][key]
+		if !ok {
+			total = &history.Total{Key: key}
+			b.totals[id][key] = total
+		}
+		total.Sessions += sessions
+		total.Focus += focus
+	}
+}
+
+// Buckets returns every period from the first to the last one holding
+// sessions, oldest first, including empty periods in between
+func (b *Bucketer) Buckets() []Bucket {
+	if len(b.starts) == 0 {
+		return []Bucket{}
+	}
+
+	ids := make([]int64, 0, len(b.starts))
+	for id := range b.starts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	last := b.starts[ids[len(ids)-1]]
+
+	var buckets []Bucket
+	for start := b.starts[ids[0]]; !start.After(last); start = NextPeriod(start, b.period) {
+		bucket := Bucket{Start: start, End: NextPeriod(start, b.period), Totals: []history.Total{}}
+		for _, total := range b.totals[start.UnixNano()] {
+			bucket.Totals = append(bucket.Totals, *total)
+		}
+		history.SortTotals(bucket.Totals)
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// PeriodStart returns the start of the day, week or month containing t, in
+// t's location. Weeks begin on weekStart.
+func PeriodStart(t time.Time, period string, weekStart time.Weekday) time.Time {
+	y, m, d := t.Date()
+	switch period {
+	case PeriodWeek:
+		offset := (int(t.Weekday()) - int(weekStart) + 7) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case PeriodMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// NextPeriod returns the start of the period following the one beginning at
+// start. Calendar arithmetic keeps DST days at 23 or 25 hours.
+func NextPeriod(start time.Time, period string) time.Time {
+	switch period {
+	case PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,121 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func bucketize(t *testing.T, period string, weekStart time.Weekday, field string, sessions ...history.Session) []Bucket {
+	t.Helper()
+	b, err := NewBucketer(period, weekStart, field, time.UTC)
+	require.NoError(t, err)
+	for _, s := range sessions {
+		b.Add(s)
+	}
+	return b.Buckets()
+}
+
+func TestBucketer_SplitsAcrossMidnight(t *testing.T) {
+	// 23:30 to 00:30 with 20m paused: 40m focus split evenly across both days
+	start := time.Date(2025, 1, 15, 23, 30, 0, 0, time.UTC)
+	s := session("1", start, 40*time.Minute, history.StatusCompleted, 1, 20*time.Minute)
+	s.Duration = "1h"
+
+	buckets := bucketize(t, PeriodDay, time.Monday, "", s)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), buckets[0].Start)
+	assert.Equal(t, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), buckets[0].End)
+	assert.Equal(t, []history.Total{{Key: "", Sessions: 1, Focus: 20 * time.Minute}}, buckets[0].Totals)
+	// The session is counted once, in the period it started
+	assert.Equal(t, []history.Total{{Key: "", Sessions: 0, Focus: 20 * time.Minute}}, buckets[1].Totals)
+}
+
+func TestBucketer_SplitPreservesTotalFocus(t *testing.T) {
+	start := time.Date(2025, 1, 15, 23, 47, 13, 0, time.UTC)
+	s := session("1", start, 25*time.Minute, history.StatusCompleted, 0, 0)
+
+	buckets := bucketize(t, PeriodDay, time.Monday, "", s)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, 25*time.Minute, buckets[0].Totals[0].Focus+buckets[1].Totals[0].Focus)
+	assert.Equal(t, 12*time.Minute+47*time.Second, buckets[0].Totals[0].Focus)
+}
+
+func TestBucketer_FillsEmptyPeriods(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	buckets := bucketize(t, PeriodDay, time.Monday, "",
+		session("1", start, 25*time.Minute, history.StatusCompleted, 0, 0),
+		session("2", start.AddDate(0, 0, 2), 10*time.Minute, history.StatusStopped, 0, 0),
+	)
+
+	require.Len(t, buckets, 3)
+	assert.Empty(t, buckets[1].Totals)
+	assert.Equal(t, time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC), buckets[2].Start)
+	assert.Equal(t, 10*time.Minute, buckets[2].Totals[0].Focus)
+}
+
+func TestBucketer_GroupsWithinPeriods(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	a := session("1", start, 25*time.Minute, history.StatusCompleted, 0, 0)
+	a.Project = "auth"
+	b := session("2", start.Add(time.Hour), 10*time.Minute, history.StatusStopped, 0, 0)
+	c := session("3", start.Add(2*time.Hour), 20*time.Minute, history.StatusCompleted, 0, 0)
+	c.Project = "auth"
+
+	buckets := bucketize(t, PeriodMonth, time.Monday, history.GroupByProject, a, b, c)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), buckets[0].Start)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), buckets[0].End)
+	assert.Equal(t, []history.Total{
+		{Key: "auth", Sessions: 2, Focus: 45 * time.Minute},
+		{Key: "", Sessions: 1, Focus: 10 * time.Minute},
+	}, buckets[0].Totals)
+}
+
+func TestBucketer_InvalidArguments(t *testing.T) {
+	_, err := NewBucketer("year", time.Monday, "", time.UTC)
+	assert.Error(t, err)
+	_, err = NewBucketer(PeriodDay, time.Monday, "colour", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestPeriodStart(t *testing.T) {
+	// Wednesday 2025-01-15
+	wed := time.Date(2025, 1, 15, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		period    string
+		weekStart time.Weekday
+		expected  time.Time
+	}{
+		{"day", PeriodDay, time.Monday, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"week from monday", PeriodWeek, time.Monday, time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)},
+		{"week from sunday", PeriodWeek, time.Sunday, time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC)},
+		{"week from wednesday", PeriodWeek, time.Wednesday, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"week from thursday", PeriodWeek, time.Thursday, time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{"month", PeriodMonth, time.Monday, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PeriodStart(wed, tt.period, tt.weekStart))
+		})
+	}
+}
+
+func TestNextPeriod_AcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	// Clocks go forward on 2025-03-30, making it a 23 hour day
+	day := time.Date(2025, 3, 30, 0, 0, 0, 0, loc)
+	next := NextPeriod(day, PeriodDay)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, loc), next)
+	assert.Equal(t, 23*time.Hour, next.Sub(day))
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+)
+
+// Range is a span of time [From, To)
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// PeriodRanges returns the day, week or month containing now and the one
+// before it, in now's location. Weeks begin on weekStart.
+func PeriodRanges(now time.Time, period string, weekStart time.Weekday) (current, previous Range, err error) {
+	switch period {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+	default:
+		return Range{}, Range{}, fmt.Errorf("invalid period %q (expected day, week or month)", period)
+	}
+
+	start := PeriodStart(now, period, weekStart)
+	current = Range{From: start, To: NextPeriod(start, period)}
+	previous = Range{From: PeriodStart(start.Add(-time.Nanosecond), period, weekStart), To: start}
+	return current, previous, nil
+}
+
+// Comparison holds the statistics of two ranges side by side
+type Comparison struct {
+	Current  Range
+	Previous Range
+	Summary  [2]Summary    // Current, then previous
+	Labels   []LabelChange // Ordered by current focus, then previous focus, descending
+}
+
+// LabelChange is the focus time spent on one label in both ranges
+type LabelChange struct {
+	Label    string
+	Current  time.Duration
+	Previous time.Duration
+}
+
+// Compare computes the summaries and per-label focus of the sessions
+// started in current and previous
+func Compare(store history.Store, current, previous Range) (Comparison, error) {
+	c := Comparison{Current: current, Previous: previous}
+	labels := map[string]*LabelChange{}
+
+	for i, r := range []Range{current, previous} {
+		summary := &c.Summary[i]
+		err := history.Walk(store, history.Query{From: r.From, To: r.To}, func(s history.Session) error {
+			summary.Add(s)
+			return nil
+		})
+		if err != nil {
+			return Comparison{}, fmt.Errorf("failed to summarize history: %w", err)
+		}
+
+		totals, err := history.Totals(store, r.From, r.To, history.GroupByLabel)
+		if err != nil {
+			return Comparison{}, fmt.Errorf("failed to compute label totals: %w", err)
+		}
+		for _, total := range totals {
+			change, ok := labels[total.Key]
+			if !ok {
+				change = &LabelChange{Label: total.Key}
+				labels[total.Key] = change
+			}
+			if i == 0 {
+				change.Current = total.Focus
+			} else {
+				change.Previous = total.Focus
+			}
+		}
+	}
+
+	c.Labels = make([]LabelChange, 0, len(labels))
+	for _, change := range labels {
+		c.Labels = append(c.Labels, *change)
+	}
+	sort.Slice(c.Labels, func(i, j int) bool {
+		a, b := c.Labels[i], c.Labels[j]
+		if a.Current != b.Current {
+			return a.Current > b.Current
+		}
+		if a.Previous != b.Previous {
+			return a.Previous > b.Previous
+		}
+		return a.Label < b.Label
+	})
+	return c, nil
+}
+
+// PercentChange returns the relative change from previous to current, or
+// false when previous is zero and the change has no percentage
+func PercentChange(current, previous float64) (float64, bool) {
+	if previous == 0 {
+		return 0, false
+	}
+	return (current - previous) / previous * 100, true
+}
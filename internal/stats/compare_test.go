@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodRanges(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 3, 5, 15, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2025, m, d, 0, 0, 0, 0, time.UTC) }
+
+	current, previous, err := PeriodRanges(now, PeriodWeek, time.Monday)
+	require.NoError(t, err)
+	assert.Equal(t, Range{From: day(3, 3), To: day(3, 10)}, current)
+	assert.Equal(t, Range{From: day(2, 24), To: day(3, 3)}, previous)
+
+	current, previous, err = PeriodRanges(now, PeriodMonth, time.Monday)
+	require.NoError(t, err)
+	assert.Equal(t, Range{From: day(3, 1), To: day(4, 1)}, current)
+	assert.Equal(t, Range{From: day(2, 1), To: day(3, 1)}, previous)
+
+	_, _, err = PeriodRanges(now, "year", time.Monday)
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	store := history.NewJSONStore(filepath.Join(t.TempDir(), "history.json"))
+	lastWeek := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	thisWeek := lastWeek.AddDate(0, 0, 7)
+
+	add := func(s history.Session, label string) {
+		s.Label = label
+		require.NoError(t, store.Append(s))
+	}
+	add(workOn(lastWeek, 25*time.Minute), "Docs")
+	add(workOn(lastWeek.AddDate(0, 0, 1), 50*time.Minute), "Review")
+	add(workOn(thisWeek, 25*time.Minute), "Review")
+	add(workOn(thisWeek.AddDate(0, 0, 1), time.Hour), "Fix login")
+	stopped := session("s", thisWeek.Add(14*time.Hour), 10*time.Minute, history.StatusStopped, 2, time.Minute)
+	add(stopped, "Fix login")
+
+	c, err := Compare(store, Range{thisWeek, thisWeek.AddDate(0, 0, 7)}, Range{lastWeek, thisWeek})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, c.Summary[0].Sessions)
+	assert.Equal(t, 95*time.Minute, c.Summary[0].TotalFocus)
+	assert.Equal(t, 2, c.Summary[0].Pauses)
+	assert.Equal(t, 2, c.Summary[1].Sessions)
+	assert.Equal(t, 75*time.Minute, c.Summary[1].TotalFocus)
+
+	assert.Equal(t, []LabelChange{
+		{Label: "Fix login", Current: 70 * time.Minute},
+		{Label: "Review", Current: 25 * time.Minute, Previous: 50 * time.Minute},
+		{Label: "Docs", Previous: 25 * time.Minute},
+	}, c.Labels)
+}
+
+func TestPercentChange(t *testing.T) {
+	pct, ok := PercentChange(150, 100)
+	assert.True(t, ok)
+	assert.InDelta(t, 50, pct, 1e-9)
+
+	pct, ok = PercentChange(50, 100)
+	assert.True(t, ok)
+	assert.InDelta(t, -50, pct, 1e-9)
+
+	_, ok = PercentChange(10, 0)
+	assert.False(t, ok)
+}
@@ -0,0 +1,100 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+)
+
+// Slot aggregates the sessions falling into one hour of the day or one weekday
+type Slot struct {
+	Sessions  int           // Sessions started in the slot
+	Completed int           // Completed sessions started in the slot
+	Timed     int           // Sessions started in the slot, excluding manual logs
+	Pauses    int           // Pauses taken by sessions started in the slot
+	Focus     time.Duration // Focus time spent in the slot
+}
+
+// CompletionRate returns the completed share of timed sessions, 0-1
+func (s Slot) CompletionRate() float64 {
+	if s.Timed == 0 {
+		return 0
+	}
+	return float64(s.Completed) / float64(s.Timed)
+}
+
+// AveragePauses returns the mean number of pauses per session
+func (s Slot) AveragePauses() float64 {
+	if s.Sessions == 0 {
+		return 0
+	}
+	return float64(s.Pauses) / float64(s.Sessions)
+}
+
+// Distribution accumulates when focus happens by hour of day and weekday.
+// A session is counted in the hour and weekday it started; its focus time
+// is split across the hours it spans in proportion to the wall-clock time
+// spent in each. Sessions started from a break preset are skipped.
+type Distribution struct {
+	Hours        [24]Slot
+	Weekdays     [7]Slot // Indexed by time.Weekday
+	breakPresets []string
+	loc          *time.Location
+}
+
+// NewDistribution creates a distribution splitting hours in loc, skipping
+// sessions started from breakPresets
+func NewDistribution(breakPresets []string, loc *time.Location) *Distribution {
+	return &Distribution{breakPresets: breakPresets, loc: loc}
+}
+
+// Add folds one session into the distribution
+func (d *Distribution) Add(session history.Session) {
+	for _, preset := range d.breakPresets {
+		if session.Preset == preset {
+			return
+		}
+	}
+
+	start := session.StartedAt.In(d.loc)
+	for _, slot := range []*Slot{&d.Hours[start.Hour()], &d.Weekdays[start.Weekday()]} {
+		slot.Sessions++
+		slot.Pauses += session.PausedCount
+		if session.EndStatus != history.StatusManual {
+			slot.Timed++
+		}
+		if session.EndStatus == history.StatusCompleted {
+			slot.Completed++
+		}
+	}
+
+	focus := session.FocusDuration()
+	end := session.EndedAt.In(d.loc)
+	if !end.After(start) {
+		d.addFocus(start, focus)
+		return
+	}
+
+	span := end.Sub(start)
+	remaining := focus
+	from := start
+	for from.Before(end) {
+		next := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), 0, 0, 0, d.loc).Add(time.Hour)
+		if !next.After(from) {
+			// The repeated hour when clocks go back resolves to its first occurrence
+			next = next.Add(time.Hour)
+		}
+		share := remaining
+		if next.Before(end) {
+			share = time.Duration(float64(focus) * float64(next.Sub(from)) / float64(span))
+			remaining -= share
+		}
+		d.addFocus(from, share)
+		from = next
+	}
+}
+
+func (d *Distribution) addFocus(at time.Time, focus time.Duration) {
+	d.Hours[at.Hour()].Focus += focus
+	d.Weekdays[at.Weekday()].Focus += focus
+}
@@ -0,0 +1,70 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution_CountsByStartHourAndWeekday(t *testing.T) {
+	// Wednesday
+	day := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	d := NewDistribution([]string{"break"}, time.UTC)
+
+	d.Add(session("1", day.Add(9*time.Hour), 25*time.Minute, history.StatusCompleted, 2, 4*time.Minute))
+	d.Add(session("2", day.Add(9*time.Hour+30*time.Minute), 10*time.Minute, history.StatusStopped, 1, time.Minute))
+	d.Add(session("3", day.Add(9*time.Hour), 20*time.Minute, history.StatusManual, 0, 0))
+	brk := session("4", day.Add(9*time.Hour), 5*time.Minute, history.StatusCompleted, 0, 0)
+	brk.Preset = "break"
+	d.Add(brk)
+
+	hour := d.Hours[9]
+	assert.Equal(t, 3, hour.Sessions)
+	assert.Equal(t, 2, hour.Timed)
+	assert.Equal(t, 1, hour.Completed)
+	assert.Equal(t, 3, hour.Pauses)
+	assert.Equal(t, 55*time.Minute, hour.Focus)
+	assert.InDelta(t, 0.5, hour.CompletionRate(), 1e-9)
+	assert.InDelta(t, 1.0, hour.AveragePauses(), 1e-9)
+
+	assert.Equal(t, hour, d.Weekdays[time.Wednesday])
+	assert.Equal(t, Slot{}, d.Hours[10])
+	assert.Equal(t, Slot{}, d.Weekdays[time.Thursday])
+}
+
+func TestDistribution_SplitsFocusAcrossHours(t *testing.T) {
+	// Tuesday 23:40 to Wednesday 00:20 with 10m paused: 30m focus, 15m each side
+	start := time.Date(2025, 1, 14, 23, 40, 0, 0, time.UTC)
+	s := session("1", start, 30*time.Minute, history.StatusCompleted, 1, 10*time.Minute)
+	s.Duration = "30m"
+	d := NewDistribution(nil, time.UTC)
+	d.Add(s)
+
+	assert.Equal(t, 1, d.Hours[23].Sessions)
+	assert.Equal(t, 15*time.Minute, d.Hours[23].Focus)
+	assert.Equal(t, 0, d.Hours[0].Sessions)
+	assert.Equal(t, 15*time.Minute, d.Hours[0].Focus)
+
+	assert.Equal(t, 1, d.Weekdays[time.Tuesday].Sessions)
+	assert.Equal(t, 15*time.Minute, d.Weekdays[time.Tuesday].Focus)
+	assert.Equal(t, 15*time.Minute, d.Weekdays[time.Wednesday].Focus)
+}
+
+func TestDistribution_DSTFallBack(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database unavailable")
+	}
+	// 00:30 EDT to 02:30 EST spans the repeated 01:00 hour: 3h of wall clock
+	start := time.Date(2025, 11, 2, 0, 30, 0, 0, loc)
+	s := session("1", start, 3*time.Hour, history.StatusCompleted, 0, 0)
+	s.Duration = "3h"
+	d := NewDistribution(nil, loc)
+	d.Add(s)
+
+	assert.Equal(t, 30*time.Minute, d.Hours[0].Focus)
+	assert.Equal(t, 2*time.Hour, d.Hours[1].Focus)
+	assert.Equal(t, 30*time.Minute, d.Hours[2].Focus)
+}
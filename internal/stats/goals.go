@@ -0,0 +1,184 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/history"
+)
+
+// Goal is a daily target. Zero fields set no target.
+type Goal struct {
+	Focus    time.Duration
+	Sessions int
+}
+
+// IsZero reports whether the goal sets no target
+func (g Goal) IsZero() bool {
+	return g.Focus <= 0 && g.Sessions <= 0
+}
+
+// Met reports whether p reaches every target of the goal
+func (g Goal) Met(p Progress) bool {
+	if g.IsZero() {
+		return false
+	}
+	return p.Focus >= g.Focus && p.Sessions >= g.Sessions
+}
+
+// Progress is the focus time and number of sessions counted towards a goal
+type Progress struct {
+	Focus    time.Duration
+	Sessions int
+}
+
+// Goals are the daily focus goals. Only completed and manually logged
+// sessions count, excluding sessions started from a break preset.
+type Goals struct {
+	Daily        Goal
+	Weekdays     map[time.Weekday]Goal // Overrides Daily
+	BreakPresets []string
+}
+
+// GoalsFromConfig converts the goals configuration
+func GoalsFromConfig(c config.GoalsConfig) Goals {
+	goals := Goals{
+		Daily:        goalFromConfig(c.Daily),
+		Weekdays:     map[time.Weekday]Goal{},
+		BreakPresets: c.BreakPresets,
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if goal := goalFromConfig(c.ForWeekday(day)); goal != goals.Daily {
+			goals.Weekdays[day] = goal
+		}
+	}
+	return goals
+}
+
+func goalFromConfig(c config.GoalConfig) Goal {
+	return Goal{Focus: time.Duration(c.Minutes) * time.Minute, Sessions: c.Sessions}
+}
+
+// Enabled reports whether any day has a goal
+func (g Goals) Enabled() bool {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if !g.For(day).IsZero() {
+			return true
+		}
+	}
+	return false
+}
+
+// For returns the goal for a weekday
+func (g Goals) For(day time.Weekday) Goal {
+	if goal, ok := g.Weekdays[day]; ok {
+		return goal
+	}
+	return g.Daily
+}
+
+// Counts reports whether a session counts towards goals
+func (g Goals) Counts(s history.Session) bool {
+	if s.EndStatus != history.StatusCompleted && s.EndStatus != history.StatusManual {
+		return false
+	}
+	for _, preset := range g.BreakPresets {
+		if s.Preset == preset {
+			return false
+		}
+	}
+	return true
+}
+
+// GoalReport is today's progress towards the goal and the goal streaks.
+// A streak is a run of days meeting their goal; days without a goal neither
+// extend nor break it, and today only extends it once the goal is met.
+type GoalReport struct {
+	Goal    Goal // Today's goal
+	Today   Progress
+	Current int // Days in the current streak
+	Longest int // Days in the longest streak
+}
+
+// GoalTracker accumulates sessions into per-day goal progress
+type GoalTracker struct {
+	goals Goals
+	days  *Bucketer
+}
+
+// NewGoalTracker creates a tracker for goals, splitting days in loc
+func NewGoalTracker(goals Goals, loc *time.Location) *GoalTracker {
+	days, _ := NewBucketer(PeriodDay, time.Monday, "", loc)
+	return &GoalTracker{goals: goals, days: days}
+}
+
+// Add folds one session into the tracker if it counts towards goals
+func (t *GoalTracker) Add(session history.Session) {
+	if t.goals.Counts(session) {
+		t.days.Add(session)
+	}
+}
+
+// Report returns the goal report as of now
+func (t *GoalTracker) Report(now time.Time) GoalReport {
+	now = now.In(t.days.loc)
+	today := PeriodStart(now, PeriodDay, time.Monday)
+
+	progress := map[string]Progress{}
+	buckets := t.days.Buckets()
+	for _, b := range buckets {
+		for _, total := range b.Totals {
+			progress[b.Start.Format(time.DateOnly)] = Progress{Focus: total.Focus, Sessions: total.Sessions}
+		}
+	}
+
+	report := GoalReport{
+		Goal:  t.goals.For(today.Weekday()),
+		Today: progress[today.Format(time.DateOnly)],
+	}
+	if len(buckets) == 0 {
+		return report
+	}
+
+	run := 0
+	for day := buckets[0].Start; !day.After(today); day = NextPeriod(day, PeriodDay) {
+		goal := t.goals.For(day.Weekday())
+		if goal.IsZero() {
+			continue
+		}
+		if goal.Met(progress[day.Format(time.DateOnly)]) {
+			run++
+			if run > report.Longest {
+				report.Longest = run
+			}
+		} else if day.Before(today) {
+			run = 0
+		}
+	}
+	report.Current = run
+
+	return report
+}
+
+// TrackGoals streams the history into a goal report as of now, in the
+// local time zone. The records of the running session, if any, are left
+// out: a resumed session is already in the history, but the timer counts
+// its whole focus again.
+func TrackGoals(store history.Store, goals Goals, now time.Time, running string) (GoalReport, error) {
+	tracker := NewGoalTracker(goals, time.Local)
+	q := history.Query{
+		To:          now,
+		EndStatuses: []string{history.StatusCompleted, history.StatusManual},
+	}
+	err := history.Walk(store, q, func(s history.Session) error {
+		if running == "" || s.ID != running {
+			tracker.Add(s)
+		}
+		return nil
+	})
+	if err != nil {
+		return GoalReport{}, fmt.Errorf("failed to track goals: %w", err)
+	}
+	return tracker.Report(now), nil
+}
@@ -0,0 +1,131 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/config"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workOn returns a completed work session of focus length at 09:00 on day
+func workOn(day time.Time, focus time.Duration) history.Session {
+	s := session(day.Format(time.DateOnly), day.Add(9*time.Hour), focus, history.StatusCompleted, 0, 0)
+	s.Duration = focus.String()
+	return s
+}
+
+func TestGoal_Met(t *testing.T) {
+	goal := Goal{Focus: time.Hour, Sessions: 2}
+	assert.True(t, goal.Met(Progress{Focus: time.Hour, Sessions: 2}))
+	assert.False(t, goal.Met(Progress{Focus: 2 * time.Hour, Sessions: 1}))
+	assert.False(t, Goal{}.Met(Progress{Focus: time.Hour}))
+}
+
+func TestGoalsFromConfig(t *testing.T) {
+	goals := GoalsFromConfig(config.GoalsConfig{
+		Daily: config.GoalConfig{Minutes: 90},
+		Weekdays: map[string]config.GoalConfig{
+			"saturday": {Sessions: 2},
+			"sunday":   {},
+		},
+		BreakPresets: []string{"break"},
+	})
+
+	assert.True(t, goals.Enabled())
+	assert.Equal(t, Goal{Focus: 90 * time.Minute}, goals.For(time.Monday))
+	assert.Equal(t, Goal{Sessions: 2}, goals.For(time.Saturday))
+	assert.True(t, goals.For(time.Sunday).IsZero())
+	assert.False(t, GoalsFromConfig(config.GoalsConfig{}).Enabled())
+}
+
+func TestGoals_Counts(t *testing.T) {
+	goals := Goals{BreakPresets: []string{"break"}}
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	work := session("1", start, 25*time.Minute, history.StatusCompleted, 0, 0)
+	assert.True(t, goals.Counts(work))
+
+	manual := work
+	manual.EndStatus = history.StatusManual
+	assert.True(t, goals.Counts(manual))
+
+	stopped := work
+	stopped.EndStatus = history.StatusStopped
+	assert.False(t, goals.Counts(stopped))
+
+	rest := work
+	rest.Preset = "break"
+	assert.False(t, goals.Counts(rest))
+}
+
+func TestGoalTracker_Streaks(t *testing.T) {
+	// Wednesday 2025-01-01 .. Wednesday 2025-01-15; weekends have no goal
+	goals := Goals{
+		Daily:    Goal{Focus: time.Hour},
+		Weekdays: map[time.Weekday]Goal{time.Saturday: {}, time.Sunday: {}},
+	}
+	day := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tracker := NewGoalTracker(goals, time.UTC)
+	// Jan 1-3 met: longest streak of 3
+	for d := 1; d <= 3; d++ {
+		tracker.Add(workOn(day(d), time.Hour))
+	}
+	// Jan 6 missed
+	tracker.Add(workOn(day(6), 30*time.Minute))
+	// Jan 7-10 met, weekend skipped, Jan 13-14 met: current streak of 6
+	for _, d := range []int{7, 8, 9, 10, 13, 14} {
+		tracker.Add(workOn(day(d), time.Hour))
+	}
+	// Jan 15 (today) in progress
+	tracker.Add(workOn(day(15), 20*time.Minute))
+
+	report := tracker.Report(day(15).Add(12 * time.Hour))
+	assert.Equal(t, Goal{Focus: time.Hour}, report.Goal)
+	assert.Equal(t, Progress{Focus: 20 * time.Minute, Sessions: 1}, report.Today)
+	// Today not being met yet does not break the streak
+	assert.Equal(t, 6, report.Current)
+	assert.Equal(t, 6, report.Longest)
+
+	// Meeting today's goal extends it
+	tracker.Add(workOn(day(15).Add(2*time.Hour), 40*time.Minute))
+	report = tracker.Report(day(15).Add(12 * time.Hour))
+	assert.Equal(t, 7, report.Current)
+	assert.Equal(t, 7, report.Longest)
+
+	// A missed weekday breaks it
+	report = tracker.Report(day(17).Add(12 * time.Hour))
+	assert.Equal(t, 0, report.Current)
+	assert.Equal(t, 7, report.Longest)
+}
+
+func TestGoalTracker_Empty(t *testing.T) {
+	tracker := NewGoalTracker(Goals{Daily: Goal{Sessions: 4}}, time.UTC)
+	report := tracker.Report(time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, GoalReport{Goal: Goal{Sessions: 4}}, report)
+}
+
+func TestTrackGoals_LeavesOutRunningSession(t *testing.T) {
+	store := history.NewJSONStore(filepath.Join(t.TempDir(), "history.json"))
+	today := time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local)
+	now := today.Add(12 * time.Hour)
+	earlier := workOn(today.AddDate(0, 0, -1), 25*time.Minute)
+	running := workOn(today, 10*time.Minute)
+	running.ID = "running"
+	require.NoError(t, store.Append(earlier))
+	require.NoError(t, store.Append(running))
+	goals := Goals{Daily: Goal{Focus: time.Hour}}
+
+	report, err := TrackGoals(store, goals, now, "")
+	require.NoError(t, err)
+	assert.Equal(t, Progress{Focus: 10 * time.Minute, Sessions: 1}, report.Today)
+
+	// The timer adds the running session's whole focus itself
+	report, err = TrackGoals(store, goals, now, "running")
+	require.NoError(t, err)
+	assert.Equal(t, Progress{}, report.Today)
+}
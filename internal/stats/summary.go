@@ -0,0 +1,72 @@
+// Package stats computes aggregate statistics over session history
+package stats
+
+import (
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+)
+
+// Summary aggregates a set of sessions
+type Summary struct {
+	Sessions       int
+	TotalFocus     time.Duration
+	Completed      int
+	Stopped        int
+	Cancelled      int
+	Interrupted    int
+	Manual         int
+	CompletionRate float64 // Completed share of timed sessions (manual logs excluded), 0-1; 0 without any
+	Pauses         int
+	TotalPaused    time.Duration
+	AveragePause   time.Duration // Mean length of a single pause
+}
+
+// Summarize computes the summary of sessions
+func Summarize(sessions []history.Session) Summary {
+	var s Summary
+	for _, session := range sessions {
+		s.Add(session)
+	}
+	return s
+}
+
+// Add folds one session into the summary, so large histories can be
+// summarized while streaming
+func (s *Summary) Add(session history.Session) {
+	s.Sessions++
+	s.TotalFocus += session.FocusDuration()
+
+	switch session.EndStatus {
+	case history.StatusCompleted:
+		s.Completed++
+	case history.StatusStopped:
+		s.Stopped++
+	case history.StatusCancelled:
+		s.Cancelled++
+	case history.StatusInterrupted:
+		s.Interrupted++
+	case history.StatusManual:
+		s.Manual++
+	}
+
+	s.Pauses += session.PausedCount
+	if paused, err := time.ParseDuration(session.PausedDuration); err == nil && paused > 0 {
+		s.TotalPaused += paused
+	}
+
+	s.CompletionRate = 0
+	if timed := s.Timed(); timed > 0 {
+		s.CompletionRate = float64(s.Completed) / float64(timed)
+	}
+	s.AveragePause = 0
+	if s.Pauses > 0 {
+		s.AveragePause = s.TotalPaused / time.Duration(s.Pauses)
+	}
+}
+
+// Timed returns the number of sessions that ran a timer, those
+// CompletionRate is a share of. Without any the rate is undefined.
+func (s Summary) Timed() int {
+	return s.Sessions - s.Manual
+}
@@ -0,0 +1,58 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/stretchr/testify/assert"
+)
+
+// session returns a session starting at start that ran for focus plus paused
+func session(id string, start time.Time, focus time.Duration, status string, pauses int, paused time.Duration) history.Session {
+	return history.Session{
+		ID:             id,
+		StartedAt:      start,
+		EndedAt:        start.Add(focus + paused),
+		Duration:       "25m",
+		Label:          "Session " + id,
+		Preset:         "work",
+		EndStatus:      status,
+		PausedCount:    pauses,
+		PausedDuration: paused.String(),
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	sessions := []history.Session{
+		session("1", start, 25*time.Minute, history.StatusCompleted, 2, 4*time.Minute),
+		session("2", start.Add(time.Hour), 25*time.Minute, history.StatusCompleted, 0, 0),
+		session("3", start.Add(2*time.Hour), 10*time.Minute, history.StatusStopped, 1, 2*time.Minute),
+		session("4", start.Add(3*time.Hour), 5*time.Minute, history.StatusCancelled, 0, 0),
+		session("5", start.Add(4*time.Hour), 15*time.Minute, history.StatusInterrupted, 0, 0),
+		session("6", start.Add(5*time.Hour), 20*time.Minute, history.StatusManual, 0, 0),
+	}
+
+	s := Summarize(sessions)
+	assert.Equal(t, 6, s.Sessions)
+	assert.Equal(t, 100*time.Minute, s.TotalFocus)
+	assert.Equal(t, 2, s.Completed)
+	assert.Equal(t, 1, s.Stopped)
+	assert.Equal(t, 1, s.Cancelled)
+	assert.Equal(t, 1, s.Interrupted)
+	assert.Equal(t, 1, s.Manual)
+	// Manual logs never ran a timer, so they do not count towards completion
+	assert.InDelta(t, 0.4, s.CompletionRate, 0.0001)
+	assert.Equal(t, 3, s.Pauses)
+	assert.Equal(t, 6*time.Minute, s.TotalPaused)
+	assert.Equal(t, 2*time.Minute, s.AveragePause)
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	s := Summarize(nil)
+	assert.Zero(t, s.Sessions)
+	assert.Zero(t, s.Timed())
+	assert.Zero(t, s.CompletionRate)
+	assert.Zero(t, s.AveragePause)
+}
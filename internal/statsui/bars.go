@@ -0,0 +1,59 @@
+package statsui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pomodux/pomodux/internal/theme"
+)
+
+// Bar is one row of a horizontal bar chart
+type Bar struct {
+	Label string
+	Value float64
+	Text  string // Shown after the bar, e.g. the formatted value
+}
+
+// BarChart renders bars scaled to the largest value, each at most width
+// cells long, using the theme's progress characters and colors
+func BarChart(th *theme.Theme, bars []Bar, width int) string {
+	maxValue := 0.0
+	for _, bar := range bars {
+		maxValue = max(maxValue, bar.Value)
+	}
+	return ScaledBarChart(th, bars, width, maxValue)
+}
+
+// ScaledBarChart renders bars like BarChart, drawing maxValue as a full
+// width bar. Use it for values with a fixed range such as rates.
+func ScaledBarChart(th *theme.Theme, bars []Bar, width int, maxValue float64) string {
+	labelWidth := 0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, lipgloss.Width(bar.Label))
+	}
+	width = max(width, 1)
+
+	filledChar := th.Progress.FilledChar
+	if filledChar == "" {
+		filledChar = "█"
+	}
+	filledStyle := th.ProgressFilledStyle()
+	mutedStyle := lipgloss.NewStyle().Foreground(th.Colors.TextMuted)
+
+	lines := make([]string, 0, len(bars))
+	for _, bar := range bars {
+		cells := 0
+		if maxValue > 0 && bar.Value > 0 {
+			// Any non-zero value gets at least one cell so it stays visible
+			cells = min(width, max(1, int(bar.Value/maxValue*float64(width)+0.5)))
+		}
+		line := bar.Label + strings.Repeat(" ", labelWidth-lipgloss.Width(bar.Label)+1) +
+			filledStyle.Render(strings.Repeat(filledChar, cells)) +
+			strings.Repeat(" ", width-cells)
+		if bar.Text != "" {
+			line += " " + mutedStyle.Render(bar.Text)
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,525 @@
+package statsui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+)
+
+// Browser tabs
+const (
+	tabSessions = iota
+	tabDay
+	tabLabels
+	tabCharts
+)
+
+var tabNames = []string{"Sessions", "Day", "Labels", "Charts"}
+
+// chartDays is the number of days in the daily focus chart
+const chartDays = 14
+
+// Browser is an interactive Bubble Tea app for exploring session history.
+// Tabs list and filter sessions, show a single day, break focus time down
+// by label and chart it; enter drills down into the selected session.
+type Browser struct {
+	theme     *theme.Theme
+	sessions  []history.Session // Newest first
+	weekStart time.Weekday
+	now       time.Time
+	ascii     bool // Draw charts without color
+
+	width  int
+	height int
+	tab    int
+
+	// Sessions tab
+	filtered  []history.Session
+	table     table.Model
+	filter    textinput.Model
+	filtering bool
+
+	// Day tab
+	day         time.Time
+	daySessions []history.Session
+	dayTable    table.Model
+
+	// Drill-down into a single session, nil when not shown
+	detail *history.Session
+}
+
+// NewBrowser creates a browser over sessions as of now. Weeks in charts
+// start on weekStart.
+func NewBrowser(th *theme.Theme, sessions []history.Session, weekStart time.Weekday, now time.Time) Browser {
+	if th == nil {
+		th = theme.GetTheme("default")
+	}
+
+	// A query without a cursor or limit cannot fail
+	page, _ := history.Query{Order: history.NewestFirst}.Apply(sessions)
+	sorted := page.Sessions
+
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "label, +project or @tag"
+	filter.PromptStyle = lipgloss.NewStyle().Foreground(th.Colors.Primary)
+
+	b := Browser{
+		theme:     th,
+		sessions:  sorted,
+		weekStart: weekStart,
+		now:       now,
+		ascii:     lipgloss.ColorProfile() == termenv.Ascii,
+		width:     80,
+		height:    24,
+		filter:    filter,
+		table:     newSessionTable(th),
+		dayTable:  newSessionTable(th),
+		day:       stats.PeriodStart(now.Local(), stats.PeriodDay, weekStart),
+	}
+	if len(sorted) > 0 {
+		b.day = stats.PeriodStart(sorted[0].StartedAt.Local(), stats.PeriodDay, weekStart)
+	}
+	b.resize()
+	b.applyFilter()
+	b.loadDay()
+	return b
+}
+
+func newSessionTable(th *theme.Theme) table.Model {
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.
+		Foreground(th.Colors.Primary).
+		BorderForeground(th.Colors.Border).
+		Bold(true)
+	styles.Selected = styles.Selected.
+		Foreground(th.Colors.Secondary).
+		Bold(true)
+	return table.New(table.WithStyles(styles), table.WithFocused(true))
+}
+
+// Init implements tea.Model
+func (b Browser) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (b Browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width = msg.Width
+		b.height = msg.Height
+		b.resize()
+		return b, nil
+
+	case tea.KeyMsg:
+		key := msg.String()
+		if key == "ctrl+c" {
+			return b, tea.Quit
+		}
+
+		if b.filtering {
+			return b.updateFilter(msg)
+		}
+
+		if b.detail != nil {
+			switch key {
+			case "esc", "backspace", "enter":
+				b.detail = nil
+			case "q":
+				return b, tea.Quit
+			}
+			return b, nil
+		}
+
+		switch key {
+		case "q":
+			return b, tea.Quit
+		case "tab":
+			b.tab = (b.tab + 1) % len(tabNames)
+			return b, nil
+		case "shift+tab":
+			b.tab = (b.tab + len(tabNames) - 1) % len(tabNames)
+			return b, nil
+		case "1", "2", "3", "4":
+			b.tab = int(key[0] - '1')
+			return b, nil
+		}
+
+		switch b.tab {
+		case tabSessions:
+			return b.updateSessions(msg)
+		case tabDay:
+			return b.updateDay(msg)
+		}
+	}
+
+	return b, nil
+}
+
+func (b Browser) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		b.filter.SetValue("")
+		fallthrough
+	case "enter":
+		b.filtering = false
+		b.filter.Blur()
+		b.applyFilter()
+		return b, nil
+	}
+
+	var cmd tea.Cmd
+	b.filter, cmd = b.filter.Update(msg)
+	b.applyFilter()
+	return b, cmd
+}
+
+func (b Browser) updateSessions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "/":
+		b.filtering = true
+		b.resize()
+		return b, b.filter.Focus()
+	case "enter":
+		if len(b.filtered) > 0 {
+			selected := b.filtered[b.table.Cursor()]
+			b.detail = &selected
+		}
+		return b, nil
+	}
+
+	var cmd tea.Cmd
+	b.table, cmd = b.table.Update(msg)
+	return b, cmd
+}
+
+func (b Browser) updateDay(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "left", "h":
+		b.day = b.day.AddDate(0, 0, -1)
+		b.loadDay()
+		return b, nil
+	case "right", "l":
+		b.day = b.day.AddDate(0, 0, 1)
+		b.loadDay()
+		return b, nil
+	case "t":
+		b.day = stats.PeriodStart(b.now.Local(), stats.PeriodDay, b.weekStart)
+		b.loadDay()
+		return b, nil
+	case "enter":
+		if len(b.daySessions) > 0 {
+			selected := b.daySessions[b.dayTable.Cursor()]
+			b.detail = &selected
+		}
+		return b, nil
+	}
+
+	var cmd tea.Cmd
+	b.dayTable, cmd = b.dayTable.Update(msg)
+	return b, cmd
+}
+
+// applyFilter selects the sessions matching every term of the filter: +term
+// matches the project, @term a tag and anything else the label
+func (b *Browser) applyFilter() {
+	terms := strings.Fields(strings.ToLower(b.filter.Value()))
+	b.filtered = b.filtered[:0]
+	for _, s := range b.sessions {
+		if matchesFilter(s, terms) {
+			b.filtered = append(b.filtered, s)
+		}
+	}
+	b.table.SetRows(sessionRows(b.filtered))
+	b.table.SetCursor(min(b.table.Cursor(), max(len(b.filtered)-1, 0)))
+}
+
+func matchesFilter(s history.Session, terms []string) bool {
+	for _, term := range terms {
+		switch {
+		case strings.HasPrefix(term, "+"):
+			if !strings.HasPrefix(strings.ToLower(s.Project), term[1:]) {
+				return false
+			}
+		case strings.HasPrefix(term, "@"):
+			found := false
+			for _, tag := range s.Tags {
+				if strings.HasPrefix(strings.ToLower(tag), term[1:]) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		default:
+			if !strings.Contains(strings.ToLower(s.Label), term) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// loadDay selects the sessions started on the current day, oldest first
+func (b *Browser) loadDay() {
+	next := b.day.AddDate(0, 0, 1)
+	b.daySessions = b.daySessions[:0]
+	for i := len(b.sessions) - 1; i >= 0; i-- {
+		s := b.sessions[i]
+		if !s.StartedAt.Before(b.day) && s.StartedAt.Before(next) {
+			b.daySessions = append(b.daySessions, s)
+		}
+	}
+	b.dayTable.SetRows(sessionRows(b.daySessions))
+	b.dayTable.SetCursor(0)
+}
+
+// resize fits the tables to the window
+func (b *Browser) resize() {
+	fixed := 16 + 7 + 12 + 11
+	labelWidth := max(b.width-fixed-10, 10)
+	columns := []table.Column{
+		{Title: "Started", Width: 16},
+		{Title: "Focus", Width: 7},
+		{Title: "Label", Width: labelWidth},
+		{Title: "Preset", Width: 12},
+		{Title: "Status", Width: 11},
+	}
+	b.table.SetColumns(columns)
+	b.dayTable.SetColumns(columns)
+
+	// Tabs, blank line, table header and the help line
+	height := b.height - 6
+	if b.filtering || b.filter.Value() != "" {
+		height--
+	}
+	b.table.SetHeight(max(height, 3))
+	// The day view adds a title and summary line
+	b.dayTable.SetHeight(max(height-3, 3))
+}
+
+func sessionRows(sessions []history.Session) []table.Row {
+	rows := make([]table.Row, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, table.Row{
+			s.StartedAt.Local().Format("2006-01-02 15:04"),
+			FormatFocus(s.FocusDuration()),
+			sessionLabel(s),
+			s.Preset,
+			s.EndStatus,
+		})
+	}
+	return rows
+}
+
+// View implements tea.Model
+func (b Browser) View() string {
+	mutedStyle := lipgloss.NewStyle().Foreground(b.theme.Colors.TextMuted)
+
+	var content, help string
+	switch {
+	case b.detail != nil:
+		content = b.detailView()
+		help = "esc back · q quit"
+	case b.tab == tabSessions:
+		content = b.sessionsView()
+		help = "↑/↓ move · enter details · / filter · tab next view · q quit"
+		if b.filtering {
+			help = "enter apply · esc clear"
+		}
+	case b.tab == tabDay:
+		content = b.dayView()
+		help = "←/→ day · t today · ↑/↓ move · enter details · tab next view · q quit"
+	case b.tab == tabLabels:
+		content = b.labelsView()
+		help = "tab next view · q quit"
+	case b.tab == tabCharts:
+		content = b.chartsView()
+		help = "tab next view · q quit"
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		b.tabsView(),
+		"",
+		content,
+		"",
+		mutedStyle.Render(help),
+	)
+}
+
+func (b Browser) tabsView() string {
+	activeStyle := b.theme.TitleStyle().Underline(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(b.theme.Colors.TextMuted)
+
+	tabs := make([]string, 0, len(tabNames))
+	for i, name := range tabNames {
+		label := fmt.Sprintf("%d %s", i+1, name)
+		if i == b.tab {
+			tabs = append(tabs, activeStyle.Render(label))
+		} else {
+			tabs = append(tabs, mutedStyle.Render(label))
+		}
+	}
+	return strings.Join(tabs, "   ")
+}
+
+func (b Browser) sessionsView() string {
+	var lines []string
+	if b.filtering || b.filter.Value() != "" {
+		lines = append(lines, b.filter.View())
+	}
+	if len(b.filtered) == 0 {
+		lines = append(lines, "No matching sessions")
+	} else {
+		lines = append(lines, b.table.View())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b Browser) dayView() string {
+	summary := stats.Summarize(b.daySessions)
+	title := b.theme.TitleStyle().Render(b.day.Format("Monday, 2006-01-02"))
+	line := fmt.Sprintf("%d sessions · %s focused · %d completed", summary.Sessions, FormatFocus(summary.TotalFocus), summary.Completed)
+
+	body := "No sessions on this day"
+	if len(b.daySessions) > 0 {
+		body = b.dayTable.View()
+	}
+	return strings.Join([]string{title, line, "", body}, "\n")
+}
+
+func (b Browser) labelsView() string {
+	totals, _ := history.SumBy(b.sessions, history.GroupByLabel)
+	if len(totals) == 0 {
+		return "No sessions recorded"
+	}
+
+	// Tabs, help and spacing take 4 lines
+	limit := max(b.height-4, 1)
+	if len(totals) > limit {
+		totals = totals[:limit]
+	}
+	bars := make([]Bar, 0, len(totals))
+	for _, t := range totals {
+		label := t.Key
+		if label == "" {
+			label = "(none)"
+		}
+		bars = append(bars, Bar{
+			Label: truncate(label, 30),
+			Value: float64(t.Focus),
+			Text:  fmt.Sprintf("%s · %d sessions", FormatFocus(t.Focus), t.Sessions),
+		})
+	}
+	return BarChart(b.theme, bars, max(b.width-60, 10))
+}
+
+func (b Browser) chartsView() string {
+	today := stats.PeriodStart(b.now.Local(), stats.PeriodDay, b.weekStart)
+	from := today.AddDate(0, 0, -(chartDays - 1))
+
+	// All sessions feed the daily chart; the heatmap, like pomodux-stats
+	// --heatmap, shows completed and manually logged focus only
+	daily, _ := stats.NewBucketer(stats.PeriodDay, b.weekStart, "", time.Local)
+	completed, _ := stats.NewBucketer(stats.PeriodDay, b.weekStart, "", time.Local)
+	for _, s := range b.sessions {
+		daily.Add(s)
+		if s.EndStatus == history.StatusCompleted || s.EndStatus == history.StatusManual {
+			completed.Add(s)
+		}
+	}
+	focus := map[string]time.Duration{}
+	for _, bucket := range daily.Buckets() {
+		for _, t := range bucket.Totals {
+			focus[bucket.Start.Format(time.DateOnly)] += t.Focus
+		}
+	}
+
+	bars := make([]Bar, 0, chartDays)
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		d := focus[day.Format(time.DateOnly)]
+		bars = append(bars, Bar{Label: day.Format("Mon 01-02"), Value: float64(d), Text: FormatFocus(d)})
+	}
+
+	weeks := max(min((b.width-8)/2, 26), 4)
+
+	return strings.Join([]string{
+		b.theme.TitleStyle().Render(fmt.Sprintf("Focus over the last %d days", chartDays)),
+		BarChart(b.theme, bars, max(b.width-30, 10)),
+		"",
+		b.theme.TitleStyle().Render("Completed focus per day"),
+		Heatmap(b.theme, completed.Buckets(), b.now.Local(), weeks, b.weekStart, b.ascii),
+	}, "\n")
+}
+
+func (b Browser) detailView() string {
+	s := *b.detail
+	valueStyle := lipgloss.NewStyle().Foreground(b.theme.Colors.Foreground)
+
+	lines := []blockLine{
+		{"ID", s.ID, valueStyle},
+		{"Label", s.Label, valueStyle},
+	}
+	if s.Project != "" {
+		lines = append(lines, blockLine{"Project", s.Project, valueStyle})
+	}
+	if len(s.Tags) > 0 {
+		lines = append(lines, blockLine{"Tags", strings.Join(s.Tags, ", "), valueStyle})
+	}
+	if s.Preset != "" {
+		lines = append(lines, blockLine{"Preset", s.Preset, valueStyle})
+	}
+	lines = append(lines,
+		blockLine{"Started", s.StartedAt.Local().Format("2006-01-02 15:04:05"), valueStyle},
+		blockLine{"Ended", s.EndedAt.Local().Format("2006-01-02 15:04:05"), valueStyle},
+		blockLine{"Planned", s.Duration, valueStyle},
+		blockLine{"Focus", FormatFocus(s.FocusDuration()), b.theme.TitleStyle()},
+		blockLine{"Status", s.EndStatus, b.theme.StatusStyle(s.EndStatus)},
+	)
+	details := renderBlock(b.theme, "Session", lines)
+
+	pauses := []blockLine{{"Pauses", fmt.Sprint(s.PausedCount), valueStyle}}
+	if paused, err := time.ParseDuration(s.PausedDuration); err == nil && s.PausedCount > 0 {
+		pauses = append(pauses,
+			blockLine{"Time paused", FormatFocus(paused), valueStyle},
+			blockLine{"Average pause", FormatFocus(paused / time.Duration(s.PausedCount)), valueStyle},
+		)
+	}
+
+	notes := lipgloss.NewStyle().Foreground(b.theme.Colors.TextMuted).Render("  No notes")
+	if s.Notes != "" {
+		notes = lipgloss.NewStyle().Width(max(b.width-4, 20)).PaddingLeft(2).Render(s.Notes)
+	}
+
+	return strings.Join([]string{
+		details,
+		"",
+		renderBlock(b.theme, "Pauses", pauses),
+		"",
+		b.theme.TitleStyle().Render("Notes"),
+		notes,
+	}, "\n")
+}
+
+// truncate shortens s to at most width cells, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,135 @@
+package statsui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func browserSessions() []history.Session {
+	day := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+	at := func(id string, start time.Time, label string) history.Session {
+		return history.Session{
+			ID:             id,
+			StartedAt:      start,
+			EndedAt:        start.Add(27 * time.Minute),
+			Duration:       "25m",
+			Preset:         "work",
+			Label:          label,
+			EndStatus:      history.StatusCompleted,
+			PausedCount:    2,
+			PausedDuration: "2m",
+		}
+	}
+
+	fix := at("a", day, "Fix login")
+	fix.Project = "auth"
+	fix.Tags = []string{"bugfix"}
+	fix.Notes = "Root cause was the session cookie path"
+	docs := at("b", day.Add(time.Hour), "Write docs")
+	review := at("c", day.AddDate(0, 0, -1), "Code review")
+	review.Project = "web"
+	return []history.Session{fix, docs, review}
+}
+
+func sendKeys(t *testing.T, m tea.Model, keys ...string) tea.Model {
+	t.Helper()
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "tab":
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "left":
+			msg = tea.KeyMsg{Type: tea.KeyLeft}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		m, _ = m.Update(msg)
+	}
+	return m
+}
+
+func newTestBrowser() tea.Model {
+	now := time.Date(2025, 1, 15, 18, 0, 0, 0, time.Local)
+	var m tea.Model = NewBrowser(theme.GetTheme("default"), browserSessions(), time.Monday, now)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return m
+}
+
+func TestBrowser_SessionsNewestFirst(t *testing.T) {
+	view := newTestBrowser().View()
+
+	docs := strings.Index(view, "Write docs")
+	fix := strings.Index(view, "Fix login +auth @bugfix")
+	review := strings.Index(view, "Code review +web")
+	require.True(t, docs > 0 && fix > 0 && review > 0, view)
+	assert.Less(t, docs, fix)
+	assert.Less(t, fix, review)
+}
+
+func TestBrowser_Filter(t *testing.T) {
+	m := sendKeys(t, newTestBrowser(), "/", "+", "a", "u", "enter")
+	view := m.View()
+	assert.Contains(t, view, "Fix login")
+	assert.NotContains(t, view, "Write docs")
+	assert.NotContains(t, view, "Code review")
+
+	m = sendKeys(t, m, "/", "esc")
+	assert.Contains(t, m.View(), "Write docs")
+
+	m = sendKeys(t, m, "/", "n", "o", "p", "e", "enter")
+	assert.Contains(t, m.View(), "No matching sessions")
+}
+
+func TestBrowser_DrillDown(t *testing.T) {
+	// Second row is "Fix login"
+	m := sendKeys(t, newTestBrowser(), "down", "enter")
+	view := m.View()
+	assert.Contains(t, view, "Fix login")
+	assert.Regexp(t, `Pauses\s+2\s*\n`, view)
+	assert.Regexp(t, `Average pause\s+1m`, view)
+	assert.Contains(t, view, "Root cause was the session cookie path")
+
+	m = sendKeys(t, m, "esc")
+	assert.NotContains(t, m.View(), "Root cause")
+}
+
+func TestBrowser_DayView(t *testing.T) {
+	m := sendKeys(t, newTestBrowser(), "tab")
+	view := m.View()
+	assert.Contains(t, view, "Wednesday, 2025-01-15")
+	assert.Contains(t, view, "2 sessions · 50m focused · 2 completed")
+	assert.NotContains(t, view, "Code review")
+
+	m = sendKeys(t, m, "left")
+	view = m.View()
+	assert.Contains(t, view, "Tuesday, 2025-01-14")
+	assert.Contains(t, view, "Code review")
+
+	m = sendKeys(t, m, "left")
+	assert.Contains(t, m.View(), "No sessions on this day")
+}
+
+func TestBrowser_LabelsAndCharts(t *testing.T) {
+	m := sendKeys(t, newTestBrowser(), "3")
+	assert.Contains(t, m.View(), "Fix login")
+	assert.Contains(t, m.View(), "25m · 1 sessions")
+
+	m = sendKeys(t, m, "4")
+	view := m.View()
+	assert.Contains(t, view, "Focus over the last 14 days")
+	assert.Contains(t, view, "Wed 01-15")
+	assert.Contains(t, view, "Completed focus per day")
+}
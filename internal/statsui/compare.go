@@ -0,0 +1,182 @@
+package statsui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+)
+
+// Columns of the comparison tables holding the change
+const (
+	changeColumn  = 3
+	percentColumn = 4
+)
+
+// compareRow is one metric of a comparison table. trend is the sign of the
+// change with improvements positive, so decreases in pauses count as good.
+type compareRow struct {
+	cells []string
+	trend int
+}
+
+// Comparison renders the totals of two ranges side by side with their
+// change: focus, sessions, completion rate, pauses, sessions ended early
+// and focus per label. Improvements use the success color, regressions the
+// error color.
+func Comparison(th *theme.Theme, c stats.Comparison) string {
+	cur, prev := c.Summary[0], c.Summary[1]
+	endedEarly := func(s stats.Summary) int { return s.Stopped + s.Cancelled + s.Interrupted }
+
+	rows := []compareRow{
+		durationRow("Focus", cur.TotalFocus, prev.TotalFocus, 1),
+		countRow("Sessions", cur.Sessions, prev.Sessions, 1),
+		rateRow(cur, prev),
+		countRow("Pauses", cur.Pauses, prev.Pauses, -1),
+		countRow("Ended early", endedEarly(cur), endedEarly(prev), -1),
+	}
+
+	headers := []string{"", RangeLabel(c.Current), RangeLabel(c.Previous), "Change", "%"}
+	blocks := []string{compareTable(th, headers, rows)}
+
+	if len(c.Labels) > 0 {
+		var labelRows []compareRow
+		for _, l := range c.Labels {
+			label := l.Label
+			if label == "" {
+				label = "(none)"
+			}
+			labelRows = append(labelRows, durationRow(label, l.Current, l.Previous, 1))
+		}
+		headers[0] = "Label"
+		blocks = append(blocks, th.TitleStyle().Render("Focus by label")+"\n"+compareTable(th, headers, labelRows))
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
+
+// RangeLabel formats a range of whole days by its first and last day
+func RangeLabel(r stats.Range) string {
+	last := r.To.AddDate(0, 0, -1)
+	if !last.After(r.From) {
+		return r.From.Format("Mon Jan 02")
+	}
+	if r.From.Year() != last.Year() {
+		return r.From.Format("Jan 02 2006") + " – " + last.Format("Jan 02 2006")
+	}
+	return r.From.Format("Jan 02") + " – " + last.Format("Jan 02")
+}
+
+func compareTable(th *theme.Theme, headers []string, rows []compareRow) string {
+	headerStyle := th.TitleStyle().Padding(0, 1)
+	cellStyle := lipgloss.NewStyle().Foreground(th.Colors.Foreground).Padding(0, 1)
+	trendStyles := map[int]lipgloss.Style{
+		1:  lipgloss.NewStyle().Foreground(th.Colors.Success).Padding(0, 1),
+		0:  lipgloss.NewStyle().Foreground(th.Colors.TextMuted).Padding(0, 1),
+		-1: lipgloss.NewStyle().Foreground(th.Colors.Error).Padding(0, 1),
+	}
+
+	cells := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		cells = append(cells, r.cells)
+	}
+
+	return table.New().
+		Border(th.BorderChars()).
+		BorderStyle(lipgloss.NewStyle().Foreground(th.Colors.Border)).
+		Headers(headers...).
+		Rows(cells...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			if (col == changeColumn || col == percentColumn) && row >= 0 && row < len(rows) {
+				return trendStyles[rows[row].trend]
+			}
+			return cellStyle
+		}).
+		Render()
+}
+
+// durationRow compares two durations; better is 1 when more is better and
+// -1 when less is better
+func durationRow(name string, cur, prev time.Duration, better int) compareRow {
+	delta := cur - prev
+	change := FormatFocus(delta)
+	if delta > 0 {
+		change = "+" + change
+	} else if delta < 0 {
+		change = "-" + FormatFocus(-delta)
+	}
+	return compareRow{
+		cells: []string{name, FormatFocus(cur), FormatFocus(prev), change, percent(float64(cur), float64(prev))},
+		trend: sign(float64(delta)) * better,
+	}
+}
+
+// countRow compares two counts like durationRow
+func countRow(name string, cur, prev int, better int) compareRow {
+	change := "0"
+	if cur != prev {
+		change = fmt.Sprintf("%+d", cur-prev)
+	}
+	return compareRow{
+		cells: []string{name, fmt.Sprintf("%d", cur), fmt.Sprintf("%d", prev), change, percent(float64(cur), float64(prev))},
+		trend: sign(float64(cur-prev)) * better,
+	}
+}
+
+// points formats a change in rate as percentage points
+// rateRow compares completion rates, which only change when both ranges
+// hold timed sessions
+func rateRow(cur, prev stats.Summary) compareRow {
+	if cur.Timed() == 0 || prev.Timed() == 0 {
+		return compareRow{cells: []string{"Completion rate", FormatRate(cur), FormatRate(prev), "n/a", "n/a"}}
+	}
+	return compareRow{
+		cells: []string{
+			"Completion rate",
+			FormatRate(cur),
+			FormatRate(prev),
+			points(cur.CompletionRate - prev.CompletionRate),
+			percent(cur.CompletionRate, prev.CompletionRate),
+		},
+		trend: sign(cur.CompletionRate - prev.CompletionRate),
+	}
+}
+
+func points(delta float64) string {
+	if pts := fmt.Sprintf("%+.0f", delta*100); pts != "+0" && pts != "-0" {
+		return pts + " pts"
+	}
+	return "0 pts"
+}
+
+// percent formats the relative change, "new" when starting from zero
+func percent(cur, prev float64) string {
+	pct, ok := stats.PercentChange(cur, prev)
+	if !ok {
+		if cur == 0 {
+			return "-"
+		}
+		return "new"
+	}
+	if formatted := fmt.Sprintf("%+.0f%%", pct); formatted != "+0%" && formatted != "-0%" {
+		return formatted
+	}
+	return "0%"
+}
+
+func sign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
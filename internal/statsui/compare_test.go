@@ -0,0 +1,58 @@
+package statsui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparison(t *testing.T) {
+	thisWeek := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	c := stats.Comparison{
+		Current:  stats.Range{From: thisWeek, To: thisWeek.AddDate(0, 0, 7)},
+		Previous: stats.Range{From: thisWeek.AddDate(0, 0, -7), To: thisWeek},
+		Summary: [2]stats.Summary{
+			{Sessions: 4, TotalFocus: 100 * time.Minute, Completed: 3, Stopped: 1, CompletionRate: 0.75, Pauses: 2},
+			{Sessions: 2, TotalFocus: 50 * time.Minute, Completed: 1, Stopped: 1, CompletionRate: 0.5, Pauses: 2},
+		},
+		Labels: []stats.LabelChange{
+			{Label: "Fix login", Current: 75 * time.Minute},
+			{Label: "Review", Current: 25 * time.Minute, Previous: 50 * time.Minute},
+		},
+	}
+
+	out := Comparison(theme.GetTheme("default"), c)
+	assert.Contains(t, out, "Mar 10 – Mar 16")
+	assert.Contains(t, out, "Mar 03 – Mar 09")
+	assert.Regexp(t, `Focus +│ 1h40m +│ 50m +│ \+50m +│ \+100%`, out)
+	assert.Regexp(t, `Completion rate +│ 75% +│ 50% +│ \+25 pts +│ \+50%`, out)
+	assert.Regexp(t, `Pauses +│ 2 +│ 2 +│ 0 +│ 0%`, out)
+	assert.Contains(t, out, "Focus by label")
+	assert.Regexp(t, `Fix login +│ 1h15m +│ 0s +│ \+1h15m +│ new`, out)
+	assert.Regexp(t, `Review +│ 25m +│ 50m +│ -25m +│ -50%`, out)
+}
+
+func TestComparison_OnlyManualSessions(t *testing.T) {
+	thisWeek := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	c := stats.Comparison{
+		Current:  stats.Range{From: thisWeek, To: thisWeek.AddDate(0, 0, 7)},
+		Previous: stats.Range{From: thisWeek.AddDate(0, 0, -7), To: thisWeek},
+		Summary: [2]stats.Summary{
+			{Sessions: 2, TotalFocus: 50 * time.Minute, Manual: 2},
+			{Sessions: 2, TotalFocus: 50 * time.Minute, Completed: 1, Stopped: 1, CompletionRate: 0.5},
+		},
+	}
+
+	// Manual logs are not failures, so there is no rate to compare
+	out := Comparison(theme.GetTheme("default"), c)
+	assert.Regexp(t, `Completion rate +│ n/a +│ 50% +│ n/a +│ n/a`, out)
+}
+
+func TestRangeLabel(t *testing.T) {
+	day := time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Mon Dec 29", RangeLabel(stats.Range{From: day, To: day.AddDate(0, 0, 1)}))
+	assert.Equal(t, "Dec 29 2025 – Jan 04 2026", RangeLabel(stats.Range{From: day, To: day.AddDate(0, 0, 7)}))
+}
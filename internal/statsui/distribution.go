@@ -0,0 +1,74 @@
+package statsui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+)
+
+// distributionWidth is the longest bar of the distribution charts
+const distributionWidth = 40
+
+// Distribution renders when focus happens as bar charts: focus by hour of
+// day and by weekday, then completion rate and average pauses by hour.
+// Hour charts cover the hours from the first to the last active one; weeks
+// start on weekStart.
+func Distribution(th *theme.Theme, d *stats.Distribution, weekStart time.Weekday) string {
+	first, last := -1, -1
+	for h, slot := range d.Hours {
+		if slot.Sessions > 0 || slot.Focus > 0 {
+			if first < 0 {
+				first = h
+			}
+			last = h
+		}
+	}
+	if first < 0 {
+		return "No sessions recorded"
+	}
+
+	var focus, rate, pauses []Bar
+	for h := first; h <= last; h++ {
+		slot := d.Hours[h]
+		label := fmt.Sprintf("%02d:00", h)
+		focus = append(focus, Bar{Label: label, Value: slot.Focus.Minutes(), Text: FormatFocus(slot.Focus)})
+
+		rateText := "-"
+		if slot.Timed > 0 {
+			rateText = fmt.Sprintf("%.0f%% of %d", slot.CompletionRate()*100, slot.Timed)
+		}
+		rate = append(rate, Bar{Label: label, Value: slot.CompletionRate(), Text: rateText})
+
+		pauseText := "-"
+		if slot.Sessions > 0 {
+			pauseText = fmt.Sprintf("%.1f", slot.AveragePauses())
+		}
+		pauses = append(pauses, Bar{Label: label, Value: slot.AveragePauses(), Text: pauseText})
+	}
+
+	var weekdays []Bar
+	for i := 0; i < 7; i++ {
+		day := (weekStart + time.Weekday(i)) % 7
+		slot := d.Weekdays[day]
+		text := FormatFocus(slot.Focus)
+		if slot.Sessions > 0 {
+			text += fmt.Sprintf(" · %d sessions", slot.Sessions)
+		}
+		weekdays = append(weekdays, Bar{Label: day.String()[:3], Value: slot.Focus.Minutes(), Text: text})
+	}
+
+	titleStyle := th.TitleStyle()
+	return strings.Join([]string{
+		titleStyle.Render("Focus by hour of day"),
+		BarChart(th, focus, distributionWidth),
+		titleStyle.Render("Focus by weekday"),
+		BarChart(th, weekdays, distributionWidth),
+		titleStyle.Render("Completion rate by hour"),
+		ScaledBarChart(th, rate, distributionWidth, 1),
+		titleStyle.Render("Average pauses by hour"),
+		BarChart(th, pauses, distributionWidth),
+	}, "\n\n")
+}
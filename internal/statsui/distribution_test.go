@@ -0,0 +1,46 @@
+package statsui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+	// Wednesday
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	d := stats.NewDistribution(nil, time.UTC)
+	for i, status := range []string{history.StatusCompleted, history.StatusStopped} {
+		d.Add(history.Session{
+			StartedAt:   start.Add(time.Duration(i) * 2 * time.Hour),
+			EndedAt:     start.Add(time.Duration(i)*2*time.Hour + 25*time.Minute),
+			Duration:    "25m",
+			EndStatus:   status,
+			PausedCount: i * 3,
+		})
+	}
+
+	out := Distribution(theme.GetTheme("default"), d, time.Monday)
+	assert.Contains(t, out, "Focus by hour of day")
+	// Hours run from the first to the last active one
+	assert.Contains(t, out, "09:00")
+	assert.Contains(t, out, "10:00")
+	assert.Contains(t, out, "11:00")
+	assert.NotContains(t, out, "08:00")
+	assert.NotContains(t, out, "12:00")
+	assert.Contains(t, out, "100% of 1")
+	assert.Regexp(t, `11:00 +0% of 1`, out)
+	assert.Contains(t, out, "3.0")
+	assert.Contains(t, out, "50m · 2 sessions")
+	assert.Less(t, strings.Index(out, "Mon"), strings.Index(out, "Sun"))
+}
+
+func TestDistribution_Empty(t *testing.T) {
+	d := stats.NewDistribution(nil, time.UTC)
+	assert.Equal(t, "No sessions recorded", Distribution(theme.GetTheme("default"), d, time.Monday))
+}
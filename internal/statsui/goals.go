@@ -0,0 +1,73 @@
+package statsui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+)
+
+// GoalBlock renders today's progress towards the goal and the streaks
+func GoalBlock(th *theme.Theme, report stats.GoalReport) string {
+	valueStyle := lipgloss.NewStyle().Foreground(th.Colors.Foreground)
+	progressStyle := valueStyle
+	if report.Goal.Met(report.Today) {
+		progressStyle = lipgloss.NewStyle().Foreground(th.Colors.Success)
+	}
+
+	var lines []blockLine
+	if report.Goal.IsZero() {
+		lines = append(lines, blockLine{"Today", "no goal", valueStyle})
+	}
+	if report.Goal.Focus > 0 {
+		value := fmt.Sprintf("%s / %s (%.0f%%)", FormatFocus(report.Today.Focus), FormatFocus(report.Goal.Focus),
+			100*float64(report.Today.Focus)/float64(report.Goal.Focus))
+		lines = append(lines, blockLine{"Focus", value, progressStyle})
+	}
+	if report.Goal.Sessions > 0 {
+		value := fmt.Sprintf("%d / %d", report.Today.Sessions, report.Goal.Sessions)
+		lines = append(lines, blockLine{"Sessions", value, progressStyle})
+	}
+	lines = append(lines,
+		blockLine{"Current streak", days(report.Current), th.TitleStyle()},
+		blockLine{"Longest streak", days(report.Longest), valueStyle},
+	)
+
+	return renderBlock(th, "Daily goal", lines)
+}
+
+// GoalLine renders a one-line goal summary, e.g.
+// "Today 1h10m/2h · 2/4 sessions · 3 day streak"
+func GoalLine(report stats.GoalReport) string {
+	if report.Goal.IsZero() {
+		return ""
+	}
+
+	parts := []string{}
+	if report.Goal.Focus > 0 {
+		parts = append(parts, fmt.Sprintf("Today %s/%s", FormatFocus(report.Today.Focus), FormatFocus(report.Goal.Focus)))
+	}
+	if report.Goal.Sessions > 0 {
+		sessions := fmt.Sprintf("%d/%d sessions", report.Today.Sessions, report.Goal.Sessions)
+		if len(parts) == 0 {
+			sessions = "Today " + sessions
+		}
+		parts = append(parts, sessions)
+	}
+	if report.Goal.Met(report.Today) {
+		parts = append(parts, "goal met")
+	}
+	if report.Current > 0 {
+		parts = append(parts, fmt.Sprintf("%d day streak", report.Current))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
@@ -0,0 +1,53 @@
+package statsui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoalBlock(t *testing.T) {
+	out := GoalBlock(theme.GetTheme("default"), stats.GoalReport{
+		Goal:    stats.Goal{Focus: 2 * time.Hour, Sessions: 4},
+		Today:   stats.Progress{Focus: 90 * time.Minute, Sessions: 3},
+		Current: 1,
+		Longest: 5,
+	})
+
+	assert.Regexp(t, `Focus\s+1h30m / 2h \(75%\)\n`, out)
+	assert.Regexp(t, `Sessions\s+3 / 4\n`, out)
+	assert.Regexp(t, `Current streak\s+1 day\n`, out)
+	assert.Regexp(t, `Longest streak\s+5 days$`, out)
+
+	out = GoalBlock(theme.GetTheme("default"), stats.GoalReport{Longest: 2})
+	assert.Regexp(t, `Today\s+no goal\n`, out)
+}
+
+func TestGoalLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   stats.GoalReport
+		expected string
+	}{
+		{"no goal", stats.GoalReport{Current: 3}, ""},
+		{
+			"focus and sessions",
+			stats.GoalReport{Goal: stats.Goal{Focus: 2 * time.Hour, Sessions: 4}, Today: stats.Progress{Focus: 70 * time.Minute, Sessions: 2}, Current: 3},
+			"Today 1h10m/2h · 2/4 sessions · 3 day streak",
+		},
+		{
+			"sessions met",
+			stats.GoalReport{Goal: stats.Goal{Sessions: 2}, Today: stats.Progress{Focus: time.Hour, Sessions: 2}},
+			"Today 2/2 sessions · goal met",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, GoalLine(tt.report))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package statsui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+)
+
+// heatmapLevels is the number of intensity levels above zero
+const heatmapLevels = 4
+
+// asciiShades renders intensity levels on terminals without color
+var asciiShades = []string{".", "-", "+", "*", "#"}
+
+// Heatmap renders daily focus time as a contribution calendar covering the
+// weeks up to and including the week of end: one column per week, one row
+// per weekday starting with weekStart. Intensity is scaled to the busiest
+// day, colored from the theme's ProgressEmpty to ProgressFilled, or drawn
+// with ASCII shades when ascii is set.
+func Heatmap(th *theme.Theme, days []stats.Bucket, end time.Time, weeks int, weekStart time.Weekday, ascii bool) string {
+	focus := map[string]time.Duration{}
+	for _, day := range days {
+		for _, total := range day.Totals {
+			focus[day.Start.Format(time.DateOnly)] += total.Focus
+		}
+	}
+
+	first := stats.PeriodStart(end, stats.PeriodWeek, weekStart).AddDate(0, 0, -7*(weeks-1))
+
+	var busiest, sum time.Duration
+	for day := first; !day.After(end); day = day.AddDate(0, 0, 1) {
+		d := focus[day.Format(time.DateOnly)]
+		sum += d
+		if d > busiest {
+			busiest = d
+		}
+	}
+
+	cells := heatmapCells(th, ascii)
+	mutedStyle := lipgloss.NewStyle().Foreground(th.Colors.TextMuted)
+
+	var b strings.Builder
+	b.WriteString("    " + mutedStyle.Render(monthLabels(first, weeks)) + "\n")
+	for weekdayRow := 0; weekdayRow < 7; weekdayRow++ {
+		weekday := time.Weekday((int(weekStart) + weekdayRow) % 7)
+		label := "   "
+		if weekdayRow%2 == 1 {
+			label = weekday.String()[:3]
+		}
+		b.WriteString(mutedStyle.Render(label))
+
+		var row []string
+		for week := 0; week < weeks; week++ {
+			day := first.AddDate(0, 0, week*7+weekdayRow)
+			if day.After(end) {
+				break
+			}
+			row = append(row, cells[level(focus[day.Format(time.DateOnly)], busiest)])
+		}
+		if len(row) > 0 {
+			b.WriteString(" " + strings.Join(row, " "))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n    " + mutedStyle.Render("Less ") + strings.Join(cells, " ") + mutedStyle.Render(" More"))
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("    %s focused over %d weeks", FormatFocus(sum), weeks)))
+
+	return b.String()
+}
+
+// level maps focus time to an intensity level relative to the busiest day
+func level(focus, busiest time.Duration) int {
+	if focus <= 0 || busiest <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(heatmapLevels) * float64(focus) / float64(busiest)))
+}
+
+// heatmapCells returns the rendered cell for each intensity level
+func heatmapCells(th *theme.Theme, ascii bool) []string {
+	if ascii {
+		return asciiShades
+	}
+
+	empty := toColorful(th.Colors.ProgressEmpty)
+	filled := toColorful(th.Colors.ProgressFilled)
+	char := th.Progress.FilledChar
+	if char == "" {
+		char = "█"
+	}
+
+	cells := make([]string, heatmapLevels+1)
+	cells[0] = lipgloss.NewStyle().Foreground(th.Colors.ProgressEmpty).Render(char)
+	for i := 1; i <= heatmapLevels; i++ {
+		// Start the gradient a step away from empty so light days stay visible
+		shade := empty.BlendLab(filled, float64(i)/float64(heatmapLevels)).Clamped()
+		cells[i] = lipgloss.NewStyle().Foreground(lipgloss.Color(shade.Hex())).Render(char)
+	}
+	return cells
+}
+
+// toColorful resolves a hex or ANSI color to RGB
+func toColorful(c lipgloss.Color) colorful.Color {
+	return termenv.ConvertToRGB(termenv.TrueColor.Color(string(c)))
+}
+
+// monthLabels returns a row of month abbreviations above the first week
+// column of each month. A partial month in the first column is left
+// unlabelled when the next column already starts a new month.
+func monthLabels(first time.Time, weeks int) string {
+	months := make([]time.Month, weeks)
+	for week := range months {
+		// A column belongs to the month its last day falls in
+		months[week] = first.AddDate(0, 0, week*7+6).Month()
+	}
+
+	row := []byte(strings.Repeat(" ", weeks*2))
+	next := 0
+	for week, month := range months {
+		if week > 0 && months[week-1] == month {
+			continue
+		}
+		if week == 0 && weeks > 1 && months[1] != month {
+			continue
+		}
+		col := week * 2
+		if col < next || col+3 > len(row) {
+			continue
+		}
+		copy(row[col:], month.String()[:3])
+		next = col + 4
+	}
+	return strings.TrimRight(string(row), " ")
+}
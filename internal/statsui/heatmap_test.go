@@ -0,0 +1,80 @@
+package statsui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(date time.Time, focus time.Duration) stats.Bucket {
+	return stats.Bucket{
+		Start:  date,
+		End:    date.AddDate(0, 0, 1),
+		Totals: []history.Total{{Sessions: 1, Focus: focus}},
+	}
+}
+
+func TestHeatmap_ASCII(t *testing.T) {
+	// Wednesday 2025-01-15, two weeks starting Monday 2025-01-06
+	end := time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC)
+	days := []stats.Bucket{
+		day(time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), 2*time.Hour),
+		day(time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC), 25*time.Minute),
+		day(time.Date(2025, 1, 14, 0, 0, 0, 0, time.UTC), time.Hour),
+	}
+
+	out := Heatmap(theme.GetTheme("default"), days, end, 2, time.Monday, true)
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, 10)
+
+	assert.Equal(t, "    Jan", lines[0])
+	assert.Equal(t, "    # .", lines[1]) // Monday: busiest day, then nothing
+	assert.Equal(t, "Tue . +", lines[2]) // Tuesday: half the busiest day
+	assert.Equal(t, "    - .", lines[3]) // Wednesday: 25m is the lowest level
+	assert.Equal(t, "Thu .", lines[4])   // Days after end are left blank
+	assert.Equal(t, "    Less . - + * # More    3h25m focused over 2 weeks", lines[9])
+}
+
+func TestHeatmap_WeekStart(t *testing.T) {
+	end := time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC)
+	out := Heatmap(theme.GetTheme("default"), nil, end, 1, time.Sunday, true)
+	lines := strings.Split(out, "\n")
+
+	assert.Equal(t, "Mon .", lines[2])
+	assert.Equal(t, "Wed .", lines[4])
+	assert.Equal(t, "Fri", lines[6])
+}
+
+func TestHeatmap_Colored(t *testing.T) {
+	end := time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC)
+	out := Heatmap(theme.GetTheme("nord"), nil, end, 1, time.Monday, false)
+
+	assert.Contains(t, out, "█")
+	assert.NotContains(t, out, "#")
+}
+
+func TestToColorful(t *testing.T) {
+	assert.Equal(t, "#88c0d0", toColorful("#88c0d0").Hex())
+	// ANSI palette indices resolve to their standard RGB values
+	assert.Equal(t, "#008080", toColorful("6").Hex())
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, 0, level(0, time.Hour))
+	assert.Equal(t, 1, level(time.Minute, time.Hour))
+	assert.Equal(t, 2, level(30*time.Minute, time.Hour))
+	assert.Equal(t, 4, level(time.Hour, time.Hour))
+}
+
+func TestMonthLabels(t *testing.T) {
+	// Weeks starting 2025-01-20 .. 2025-03-03; the first column ends in
+	// January but the second already reaches February
+	first := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "  Feb     Mar", monthLabels(first, 7))
+}
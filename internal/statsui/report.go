@@ -0,0 +1,124 @@
+// Package statsui renders session statistics for the terminal
+package statsui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/pomodux/pomodux/internal/timer"
+)
+
+// statusColumn is the index of the status column in the session table
+const statusColumn = 4
+
+// SessionTable renders sessions as a themed table
+func SessionTable(th *theme.Theme, sessions []history.Session) string {
+	rows := make([][]string, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, []string{
+			s.StartedAt.Local().Format("2006-01-02 15:04"),
+			FormatFocus(s.FocusDuration()),
+			sessionLabel(s),
+			s.Preset,
+			s.EndStatus,
+			formatPauses(s),
+		})
+	}
+
+	headerStyle := th.TitleStyle().Padding(0, 1)
+	cellStyle := lipgloss.NewStyle().Foreground(th.Colors.Foreground).Padding(0, 1)
+
+	t := table.New().
+		Border(th.BorderChars()).
+		BorderStyle(lipgloss.NewStyle().Foreground(th.Colors.Border)).
+		Headers("Started", "Focus", "Label", "Preset", "Status", "Pauses").
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return headerStyle
+			}
+			if col == statusColumn && row >= 0 && row < len(rows) {
+				return th.StatusStyle(rows[row][col]).Padding(0, 1)
+			}
+			return cellStyle
+		})
+
+	return t.Render()
+}
+
+// SummaryBlock renders the aggregate summary under title
+func SummaryBlock(th *theme.Theme, title string, s stats.Summary) string {
+	valueStyle := lipgloss.NewStyle().Foreground(th.Colors.Foreground)
+
+	return renderBlock(th, title, []blockLine{
+		{"Total focus", FormatFocus(s.TotalFocus), th.TitleStyle()},
+		{"Sessions", fmt.Sprintf("%d", s.Sessions), valueStyle},
+		{"Completed", fmt.Sprintf("%d", s.Completed), th.StatusStyle(history.StatusCompleted)},
+		{"Stopped", fmt.Sprintf("%d", s.Stopped), th.StatusStyle(history.StatusStopped)},
+		{"Cancelled", fmt.Sprintf("%d", s.Cancelled), th.StatusStyle(history.StatusCancelled)},
+		{"Interrupted", fmt.Sprintf("%d", s.Interrupted), th.StatusStyle(history.StatusInterrupted)},
+		{"Manual", fmt.Sprintf("%d", s.Manual), th.StatusStyle(history.StatusManual)},
+		{"Completion rate", FormatRate(s), valueStyle},
+		{"Average pause", fmt.Sprintf("%s (%d pauses)", FormatFocus(s.AveragePause), s.Pauses), valueStyle},
+	})
+}
+
+// blockLine is one "name  value" line of a titled block
+type blockLine struct {
+	name  string
+	value string
+	style lipgloss.Style
+}
+
+// renderBlock renders a title followed by indented, aligned name/value lines
+func renderBlock(th *theme.Theme, title string, lines []blockLine) string {
+	mutedStyle := lipgloss.NewStyle().Foreground(th.Colors.TextMuted)
+
+	var b strings.Builder
+	b.WriteString(th.TitleStyle().Render(title))
+	for _, l := range lines {
+		b.WriteString("\n  ")
+		b.WriteString(mutedStyle.Render(fmt.Sprintf("%-17s", l.name)))
+		b.WriteString(l.style.Render(l.value))
+	}
+	return b.String()
+}
+
+// FormatFocus formats a focus duration rounded down to whole seconds
+func FormatFocus(d time.Duration) string {
+	return timer.FormatDuration(d.Truncate(time.Second))
+}
+
+// FormatRate formats the completion rate of s, "n/a" when every session was
+// logged manually
+func FormatRate(s stats.Summary) string {
+	if s.Timed() == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", s.CompletionRate*100)
+}
+
+// sessionLabel renders a label followed by its project and tags in label shorthand
+func sessionLabel(s history.Session) string {
+	parts := []string{s.Label}
+	if s.Project != "" {
+		parts = append(parts, "+"+s.Project)
+	}
+	for _, tag := range s.Tags {
+		parts = append(parts, "@"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatPauses(s history.Session) string {
+	if s.PausedCount == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d (%s)", s.PausedCount, s.PausedDuration)
+}
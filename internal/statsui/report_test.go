@@ -0,0 +1,74 @@
+package statsui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pomodux/pomodux/internal/history"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/theme"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionTable(t *testing.T) {
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.Local)
+	sessions := []history.Session{
+		{
+			ID:             "a",
+			StartedAt:      start,
+			EndedAt:        start.Add(27 * time.Minute),
+			Duration:       "25m",
+			Preset:         "work",
+			Label:          "Fix login",
+			Project:        "auth",
+			Tags:           []string{"bugfix"},
+			EndStatus:      history.StatusCompleted,
+			PausedCount:    1,
+			PausedDuration: "2m",
+		},
+		{
+			ID:        "b",
+			StartedAt: start.Add(time.Hour),
+			EndedAt:   start.Add(time.Hour + 10*time.Minute),
+			Duration:  "25m",
+			Label:     "Docs",
+			EndStatus: history.StatusStopped,
+		},
+	}
+
+	out := SessionTable(theme.GetTheme("default"), sessions)
+	lines := strings.Split(out, "\n")
+
+	assert.Contains(t, out, "Started")
+	assert.Contains(t, lines[3], "2025-01-15 09:00")
+	assert.Contains(t, lines[3], "25m")
+	assert.Contains(t, lines[3], "Fix login +auth @bugfix")
+	assert.Contains(t, lines[3], "completed")
+	assert.Contains(t, lines[3], "1 (2m)")
+	assert.Contains(t, lines[4], "10m")
+	assert.Contains(t, lines[4], "stopped")
+	assert.Contains(t, lines[4], " - ")
+}
+
+func TestSummaryBlock(t *testing.T) {
+	out := SummaryBlock(theme.GetTheme("default"), "Summary (today)", stats.Summary{
+		Sessions:       4,
+		TotalFocus:     90*time.Minute + 500*time.Millisecond,
+		Completed:      3,
+		Stopped:        1,
+		CompletionRate: 0.75,
+		Pauses:         2,
+		AveragePause:   90 * time.Second,
+	})
+
+	assert.Contains(t, out, "Summary (today)")
+	assert.Regexp(t, `Total focus\s+1h30m\n`, out)
+	assert.Regexp(t, `Completed\s+3\n`, out)
+	assert.Regexp(t, `Stopped\s+1\n`, out)
+	assert.Regexp(t, `Completion rate\s+75%`, out)
+	assert.Regexp(t, `Average pause\s+1m30s \(2 pauses\)`, out)
+
+	out = SummaryBlock(theme.GetTheme("default"), "Summary (today)", stats.Summary{Sessions: 2, Manual: 2, TotalFocus: time.Hour})
+	assert.Regexp(t, `Completion rate\s+n/a`, out, "manual logs are not failures")
+}
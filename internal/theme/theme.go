@@ -68,8 +68,12 @@ func (t *Theme) StatusStyle(status string) lipgloss.Style {
 		color = t.Colors.Warning
 	case "completed":
 		color = t.Colors.Success
-	case "stopped":
+	case "stopped", "cancelled":
 		color = t.Colors.Error
+	case "interrupted":
+		color = t.Colors.Warning
+	case "manual":
+		color = t.Colors.Secondary
 	default:
 		color = t.Colors.Foreground
 	}
@@ -89,23 +93,25 @@ func (t *Theme) ProgressEmptyStyle() lipgloss.Style {
 
 // BorderStyle returns a border style
 func (t *Theme) BorderStyle() lipgloss.Style {
-	style := lipgloss.NewStyle().
-		BorderForeground(t.Colors.Border)
+	return lipgloss.NewStyle().
+		BorderForeground(t.Colors.Border).
+		BorderStyle(t.BorderChars())
+}
 
+// BorderChars returns the border characters for the theme's border style
+func (t *Theme) BorderChars() lipgloss.Border {
 	switch t.Border.Style {
 	case "rounded":
-		style = style.BorderStyle(lipgloss.RoundedBorder())
+		return lipgloss.RoundedBorder()
 	case "square":
-		style = style.BorderStyle(lipgloss.NormalBorder())
+		return lipgloss.NormalBorder()
 	case "double":
-		style = style.BorderStyle(lipgloss.DoubleBorder())
+		return lipgloss.DoubleBorder()
 	case "none":
-		style = style.BorderStyle(lipgloss.HiddenBorder())
+		return lipgloss.HiddenBorder()
 	default:
-		style = style.BorderStyle(lipgloss.RoundedBorder())
+		return lipgloss.RoundedBorder()
 	}
-
-	return style
 }
 
 
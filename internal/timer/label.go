@@ -0,0 +1,64 @@
+package timer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseLabel splits the label shorthand into its parts: words starting with
+// "+" name the project and words starting with "@" are tags, e.g.
+// "Fix login +auth @bugfix @backend". The remaining words form the label.
+func ParseLabel(input string) (label string, project string, tags []string, err error) {
+	var words []string
+	for _, word := range strings.Fields(input) {
+		switch {
+		case len(word) > 1 && word[0] == '+':
+			name := word[1:]
+			if project != "" && project != name {
+				return "", "", nil, fmt.Errorf("label names more than one project: +%s and +%s", project, name)
+			}
+			project = name
+		case len(word) > 1 && word[0] == '@':
+			tags = MergeTags(tags, []string{word[1:]})
+		default:
+			words = append(words, word)
+		}
+	}
+
+	return strings.Join(words, " "), project, tags, nil
+}
+
+// MergeTags appends the tags from extra that are not already in tags,
+// preserving order
+func MergeTags(tags []string, extra []string) []string {
+	for _, tag := range extra {
+		if tag == "" {
+			continue
+		}
+		found := false
+		for _, existing := range tags {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ValidateName checks that a project or tag name is usable
+func ValidateName(kind string, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name cannot be empty", kind)
+	}
+	if len(name) > 50 {
+		return fmt.Errorf("%s name too long (max 50 chars), got %d", kind, len(name))
+	}
+	if strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("%s name %q cannot contain whitespace", kind, name)
+	}
+	return nil
+}
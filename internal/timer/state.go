@@ -8,6 +8,8 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/pomodux/pomodux/internal/encryption"
 )
 
 // TimerState represents the persisted timer state for crash recovery
@@ -19,6 +21,9 @@ type TimerState struct {
 	Duration       string    `json:"duration"`
 	Preset         string    `json:"preset,omitempty"`
 	Label          string    `json:"label"`
+	Project        string    `json:"project,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	Private        bool      `json:"private,omitempty"`
 	Remaining      string    `json:"remaining"`
 	IsPaused       bool      `json:"is_paused"`
 	PausedCount    int       `json:"paused_count"`
@@ -51,6 +56,9 @@ func SaveState(timer *Timer, sessionID string, path string) error {
 		Duration:       durationStr,
 		Preset:         timer.preset,
 		Label:          timer.label,
+		Project:        timer.project,
+		Tags:           timer.tags,
+		Private:        timer.private,
 		Remaining:      remainingStr,
 		IsPaused:       timer.state == StatePaused,
 		PausedCount:    timer.pausedCount,
@@ -64,13 +72,7 @@ func SaveState(timer *Timer, sessionID string, path string) error {
 		return fmt.Errorf("failed to marshal state: %w", err)
 	}
 
-	// Atomic write: write to temp file, then rename
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write state file: %w", err)
-	}
-
-	if err := os.Rename(tmpPath, path); err != nil {
+	if err := encryption.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to save state file: %w", err)
 	}
 
@@ -85,17 +87,21 @@ func LoadState(path string) (*TimerState, error) {
 	}
 
 	// Read file
-	data, err := os.ReadFile(path)
+	raw, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state file: %w", err)
 	}
+	data, err := encryption.Decrypt(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt state file: %w", err)
+	}
 
 	// Parse JSON
 	var state TimerState
 	if err := json.Unmarshal(data, &state); err != nil {
 		// Try to backup corrupted file
 		backupPath := path + ".backup"
-		os.WriteFile(backupPath, data, 0600)
+		os.WriteFile(backupPath, raw, 0600)
 		return nil, fmt.Errorf("failed to parse state file (backed up to %s): %w", backupPath, err)
 	}
 
@@ -145,6 +151,9 @@ func ResumeFromState(state *TimerState) (*Timer, error) {
 	}
 
 	// Restore timer state
+	timer.project = state.Project
+	timer.tags = state.Tags
+	timer.private = state.Private
 	timer.startTime = state.StartedAt
 	timer.pausedCount = state.PausedCount
 
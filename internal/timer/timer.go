@@ -21,10 +21,14 @@ type Timer struct {
 	duration    time.Duration
 	label       string
 	preset      string
+	project     string
+	tags        []string
+	private     bool
 	startTime   time.Time
 	pausedAt    time.Time
 	totalPaused time.Duration
 	pausedCount int
+	stoppedAt   time.Time
 	state       State
 }
 
@@ -92,6 +96,12 @@ func (t *Timer) Stop() error {
 		return fmt.Errorf("timer cannot be stopped from state %s", t.state)
 	}
 
+	// A pause in progress ends with the session
+	if t.state == StatePaused {
+		t.totalPaused += time.Since(t.pausedAt)
+		t.pausedAt = time.Time{}
+	}
+	t.stoppedAt = time.Now()
 	t.state = StateStopped
 	return nil
 }
@@ -115,6 +125,34 @@ func (t *Timer) Remaining() time.Duration {
 	return remaining
 }
 
+// Elapsed returns the focus time so far: the time since start minus pauses,
+// capped at the planned duration. It stops growing once the timer stops.
+func (t *Timer) Elapsed() time.Duration {
+	switch t.state {
+	case StateIdle:
+		return 0
+	case StateCompleted:
+		return t.duration
+	}
+
+	end := time.Now()
+	if t.state == StateStopped {
+		end = t.stoppedAt
+	}
+	elapsed := end.Sub(t.startTime) - t.totalPaused
+	if t.state == StatePaused {
+		elapsed -= end.Sub(t.pausedAt)
+	}
+
+	if elapsed > t.duration {
+		return t.duration
+	}
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
 // IsCompleted checks if the timer has completed
 func (t *Timer) IsCompleted() bool {
 	return t.Remaining() == 0 && t.state == StateRunning
@@ -135,6 +173,36 @@ func (t *Timer) Preset() string {
 	return t.preset
 }
 
+// Project returns the project the session is booked to (empty if none)
+func (t *Timer) Project() string {
+	return t.project
+}
+
+// SetProject sets the project the session is booked to
+func (t *Timer) SetProject(project string) {
+	t.project = project
+}
+
+// Tags returns the session tags
+func (t *Timer) Tags() []string {
+	return t.tags
+}
+
+// SetTags sets the session tags
+func (t *Timer) SetTags(tags []string) {
+	t.tags = tags
+}
+
+// Private reports whether the session label is kept out of logs, stats and exports
+func (t *Timer) Private() bool {
+	return t.private
+}
+
+// SetPrivate marks the session as private
+func (t *Timer) SetPrivate(private bool) {
+	t.private = private
+}
+
 // State returns the current timer state
 func (t *Timer) State() State {
 	return t.state
@@ -179,6 +247,9 @@ func (t *Timer) ToState(sessionID string) *TimerState {
 		Duration:       durationStr,
 		Preset:         t.preset,
 		Label:          t.label,
+		Project:        t.project,
+		Tags:           t.tags,
+		Private:        t.private,
 		Remaining:      remainingStr,
 		IsPaused:       t.state == StatePaused,
 		PausedCount:    t.pausedCount,
@@ -79,6 +79,32 @@ func TestTimer_Stop(t *testing.T) {
 	assert.Equal(t, StateStopped, timer.State())
 }
 
+func TestTimer_Elapsed(t *testing.T) {
+	timer, _ := NewTimer(25*time.Minute, "Test", "")
+	assert.Equal(t, time.Duration(0), timer.Elapsed())
+
+	timer.Start()
+	// Started 20 minutes ago and paused 5 minutes of it, pausing again 2 minutes ago
+	now := time.Now()
+	timer.startTime = now.Add(-20 * time.Minute)
+	timer.totalPaused = 5 * time.Minute
+	timer.Pause()
+	timer.pausedAt = now.Add(-2 * time.Minute)
+	assert.InDelta(t, float64(13*time.Minute), float64(timer.Elapsed()), float64(time.Second))
+
+	// Stopping while paused keeps the last pause out of the focus time and
+	// freezes it
+	timer.Stop()
+	assert.InDelta(t, float64(7*time.Minute), float64(timer.TotalPausedDuration()), float64(time.Second))
+	timer.stoppedAt = timer.stoppedAt.Add(-time.Minute)
+	assert.InDelta(t, float64(12*time.Minute), float64(timer.Elapsed()), float64(time.Second))
+
+	// Capped at the planned duration
+	timer.state = StateRunning
+	timer.startTime = now.Add(-time.Hour)
+	assert.Equal(t, 25*time.Minute, timer.Elapsed())
+}
+
 func TestFormatDuration(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -101,3 +127,62 @@ func TestFormatDuration(t *testing.T) {
 }
 
 
+
+func TestParseLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		label   string
+		project string
+		tags    []string
+	}{
+		{"plain label", "Fix login", "Fix login", "", nil},
+		{"project and tags", "Fix login +auth @bugfix @backend", "Fix login", "auth", []string{"bugfix", "backend"}},
+		{"markers anywhere", "@review Code +web review", "Code review", "web", []string{"review"}},
+		{"duplicate tags", "Deploy @ops @ops", "Deploy", "", []string{"ops"}},
+		{"lone markers kept", "C + D @", "C + D @", "", nil},
+		{"only shorthand", "+auth @bugfix", "", "auth", []string{"bugfix"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, project, tags, err := ParseLabel(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.label, label)
+			assert.Equal(t, tt.project, project)
+			assert.Equal(t, tt.tags, tags)
+		})
+	}
+}
+
+func TestParseLabel_MultipleProjects(t *testing.T) {
+	_, _, _, err := ParseLabel("Fix +auth +web")
+	assert.Error(t, err)
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("tag", "bugfix"))
+	assert.Error(t, ValidateName("tag", ""))
+	assert.Error(t, ValidateName("project", "two words"))
+}
+
+func TestResumeFromState_RestoresProjectAndTags(t *testing.T) {
+	timer, _ := NewTimer(25*time.Minute, "Fix login", "work")
+	timer.SetProject("auth")
+	timer.SetTags([]string{"bugfix", "backend"})
+	timer.Start()
+
+	resumed, err := ResumeFromState(timer.ToState("session-id"))
+	assert.NoError(t, err)
+	assert.Equal(t, "auth", resumed.Project())
+	assert.Equal(t, []string{"bugfix", "backend"}, resumed.Tags())
+}
+
+func TestResumeFromState_RestoresPrivate(t *testing.T) {
+	timer, _ := NewTimer(25*time.Minute, "Doctor appointment", "work")
+	timer.SetPrivate(true)
+	timer.Start()
+
+	resumed, err := ResumeFromState(timer.ToState("session-id"))
+	assert.NoError(t, err)
+	assert.True(t, resumed.Private())
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
@@ -9,6 +10,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/pomodux/pomodux/internal/history"
 	"github.com/pomodux/pomodux/internal/logger"
+	"github.com/pomodux/pomodux/internal/stats"
+	"github.com/pomodux/pomodux/internal/statsui"
 	"github.com/pomodux/pomodux/internal/theme"
 	"github.com/pomodux/pomodux/internal/timer"
 )
@@ -28,7 +31,9 @@ type Model struct {
 	quitting                     bool
 	sessionID                    string
 	statePath                    string
-	historyPath                  string
+	history                      history.Store
+	goals                        stats.Goals
+	goalReport                   *stats.GoalReport
 	showConfirmation             bool
 	wasRunningBeforeConfirmation bool
 	showCompletion               bool
@@ -36,7 +41,7 @@ type Model struct {
 }
 
 // NewModel creates a new TUI model. If theme is nil, the default theme is used.
-func NewModel(t *timer.Timer, sessionID string, statePath string, historyPath string, th *theme.Theme) Model {
+func NewModel(t *timer.Timer, sessionID string, statePath string, store history.Store, th *theme.Theme) Model {
 	if th == nil {
 		th = theme.GetTheme("default")
 	}
@@ -62,10 +67,18 @@ func NewModel(t *timer.Timer, sessionID string, statePath string, historyPath st
 		theme:       th,
 		sessionID:   sessionID,
 		statePath:   statePath,
-		historyPath: historyPath,
+		history:     store,
 	}
 }
 
+// WithGoals returns a copy of the model showing progress towards goals,
+// starting from report as loaded from history
+func (m Model) WithGoals(goals stats.Goals, report stats.GoalReport) Model {
+	m.goals = goals
+	m.goalReport = &report
+	return m
+}
+
 // firstRune returns the first rune of s, or fallback if s is empty.
 func firstRune(s string, fallback rune) rune {
 	if s == "" {
@@ -360,42 +373,37 @@ func (m Model) saveState() {
 	}
 }
 
-// saveSessionToHistory appends the current session to history with the given end_status.
-// Called before tea.Quit on completed, stopped, or cancelled exit paths.
-func (m Model) saveSessionToHistory(endStatus string) {
-	if m.historyPath == "" {
-		logger.WithField("session_id", m.sessionID).Warn("History path not set, skipping session save")
-		return
-	}
-	endedAt := time.Now()
-	session := history.Session{
-		ID:             m.sessionID,
-		StartedAt:      m.timer.StartTime(),
+// HistorySession builds the history record for a timer session that ended at endedAt
+func HistorySession(t *timer.Timer, sessionID string, endStatus string, endedAt time.Time) history.Session {
+	return history.Session{
+		ID:             sessionID,
+		StartedAt:      t.StartTime(),
 		EndedAt:        endedAt,
-		Duration:       timer.FormatDuration(m.timer.Duration()),
-		Preset:         m.timer.Preset(),
-		Label:          m.timer.Label(),
+		Duration:       timer.FormatDuration(t.Duration()),
+		Preset:         t.Preset(),
+		Label:          t.Label(),
+		Project:        t.Project(),
+		Tags:           t.Tags(),
+		Private:        t.Private(),
 		EndStatus:      endStatus,
-		PausedCount:    m.timer.PausedCount(),
-		PausedDuration: timer.FormatDuration(m.timer.TotalPausedDuration()),
+		PausedCount:    t.PausedCount(),
+		PausedDuration: timer.FormatDuration(t.TotalPausedDuration()),
 	}
-	h, err := history.Load(m.historyPath)
-	if err != nil {
-		logger.WithError(err).WithFields(map[string]interface{}{
-			"component":   "tui",
-			"event":       "history_load_error",
-			"session_id":  m.sessionID,
-			"history_path": m.historyPath,
-		}).Error("Failed to load history for session save")
+}
+
+// saveSessionToHistory appends the current session to history with the given end_status.
+// Called before tea.Quit on completed, stopped, or cancelled exit paths.
+func (m Model) saveSessionToHistory(endStatus string) {
+	if m.history == nil {
+		logger.WithField("session_id", m.sessionID).Warn("History store not set, skipping session save")
 		return
 	}
-	h.AddSession(session)
-	if err := history.Save(h, m.historyPath); err != nil {
+	session := HistorySession(m.timer, m.sessionID, endStatus, time.Now())
+	if err := m.history.Append(session); err != nil {
 		logger.WithError(err).WithFields(map[string]interface{}{
 			"component":   "tui",
 			"event":       "history_save_error",
 			"session_id":  m.sessionID,
-			"history_path": m.historyPath,
 		}).Error("Failed to save session to history")
 		return
 	}
@@ -468,17 +476,20 @@ func (m Model) View() string {
 		}
 	}
 
-	inner := lipgloss.JoinVertical(lipgloss.Left,
-		sessionHeader,
-		"",
-		progressBar,
-		"",
-		timeLine,
+	lines := []string{sessionHeader}
+	if meta := m.sessionMeta(); meta != "" {
+		lines = append(lines, mutedStyle.Render(meta))
+	}
+	lines = append(lines, "", progressBar, "", timeLine)
+	if goal := m.goalLine(); goal != "" {
+		lines = append(lines, mutedStyle.Render(goal))
+	}
+	inner := lipgloss.JoinVertical(lipgloss.Left, append(lines,
 		"",
 		statusLine,
 		"",
 		bottomLine,
-	)
+	)...)
 
 	windowStyle := th.BorderStyle().Padding(1, 2)
 	content := windowStyle.Render(inner)
@@ -497,6 +508,45 @@ func (m Model) sessionHeader(th *theme.Theme) string {
 	return th.TitleStyle().Render(text)
 }
 
+// sessionMeta renders the project and tags in label shorthand, e.g. "+auth @bugfix"
+func (m Model) sessionMeta() string {
+	var parts []string
+	if project := m.timer.Project(); project != "" {
+		parts = append(parts, "+"+project)
+	}
+	for _, tag := range m.timer.Tags() {
+		parts = append(parts, "@"+tag)
+	}
+	return strings.Join(parts, " ")
+}
+
+// goalLine renders today's goal progress, counting the running session's
+// focus so far if it will count towards the goal once completed. The goal
+// report leaves out the session's own records, so a resumed session's focus
+// is only counted here.
+func (m Model) goalLine() string {
+	if m.goalReport == nil {
+		return ""
+	}
+
+	report := *m.goalReport
+	current := HistorySession(m.timer, m.sessionID, history.StatusCompleted, time.Now())
+	if m.goals.Counts(current) {
+		wasMet := report.Goal.Met(report.Today)
+		report.Today.Focus += m.timer.Elapsed()
+		if m.showCompletion || m.timer.State() == timer.StateCompleted {
+			report.Today.Sessions++
+		}
+		if !wasMet && report.Goal.Met(report.Today) {
+			report.Current++
+			if report.Current > report.Longest {
+				report.Longest = report.Current
+			}
+		}
+	}
+	return statsui.GoalLine(report)
+}
+
 func prettifyPreset(preset string) string {
 	if preset == "" {
 		return preset